@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"text/tabwriter"
+
+	"github.com/DefiantLabs/cosmos-tax-cli/config"
+	dbTypes "github.com/DefiantLabs/cosmos-tax-cli/db"
+	"github.com/spf13/cobra"
+	"gorm.io/gorm"
+)
+
+var (
+	balancesConfig       config.BalancesConfig
+	balancesDbConnection *gorm.DB
+)
+
+func init() {
+	config.SetupLogFlags(&balancesConfig.Log, balancesCmd)
+	config.SetupDatabaseFlags(&balancesConfig.Database, balancesCmd)
+	config.SetupLensFlags(&balancesConfig.Lens, balancesCmd)
+	config.SetupBalancesSpecificFlags(&balancesConfig, balancesCmd)
+	balancesCmd.AddCommand(balancesShowCmd, balancesRebuildCmd)
+	rootCmd.AddCommand(balancesCmd)
+}
+
+var balancesCmd = &cobra.Command{
+	Use:   "balances",
+	Short: "Show and rebuild the per-address balance history derived from the indexed data.",
+	Long: `The indexer keeps a running balance of every address in every denom after each block that changed it,
+	derived from the taxable txs, fees and taxable events stored for the block. The balances can be shown for any
+	height or date, and rebuilt from scratch for a chain indexed before they were kept.`,
+}
+
+var balancesShowCmd = &cobra.Command{
+	Use:     "show",
+	Short:   "Show the balances of addresses at a height or date.",
+	PreRunE: setupBalances,
+	Run:     showBalances,
+}
+
+var balancesRebuildCmd = &cobra.Command{
+	Use:   "rebuild",
+	Short: "Derive the balances of the chain again from the indexed data.",
+	Long: `Deletes the balances of the chain and derives them again from the stored taxable txs, fees and taxable events,
+	base.chunk-size blocks at a time in height order.`,
+	PreRunE: setupBalances,
+	Run:     rebuildBalances,
+}
+
+func setupBalances(cmd *cobra.Command, args []string) error {
+	bindFlags(cmd, viperConf)
+
+	err := balancesConfig.Validate()
+	if err != nil {
+		return err
+	}
+
+	ignoredKeys := config.CheckSuperfluousBalancesKeys(viperConf.AllKeys())
+
+	if len(ignoredKeys) > 0 {
+		config.Log.Warnf("Warning, the following invalid keys will be ignored: %v", ignoredKeys)
+	}
+
+	setupLogger(balancesConfig.Log.Level, balancesConfig.Log.Path, balancesConfig.Log.Pretty)
+
+	db, err := connectToDBAndMigrate(balancesConfig.Database)
+	if err != nil {
+		config.Log.Fatal("Could not establish connection to the database", err)
+	}
+
+	balancesDbConnection = db
+
+	return nil
+}
+
+// getBalancesChain looks up the configured chain
+func getBalancesChain() uint {
+	var chain dbTypes.Chain
+	err := balancesDbConnection.Where("chain_id = ?", balancesConfig.Lens.ChainID).First(&chain).Error
+	if err != nil {
+		config.Log.Fatalf("Error finding chain %s in the DB. Err: %v", balancesConfig.Lens.ChainID, err)
+	}
+	return chain.ID
+}
+
+func showBalances(cmd *cobra.Command, args []string) {
+	cfg := balancesConfig
+	db := balancesDbConnection
+	chainID := getBalancesChain()
+
+	if len(cfg.Base.Addresses) == 0 {
+		config.Log.Fatal("base.addresses must contain at least one address")
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ADDRESS\tDENOM\tSYMBOL\tBALANCE\tLAST CHANGED")
+	for _, address := range cfg.Base.Addresses {
+		var balances []dbTypes.DenomBalance
+		var err error
+		switch {
+		case cfg.Base.Date != "":
+			date, _ := cfg.GetDate() // validated in the config
+			balances, err = dbTypes.GetBalancesAtTime(db, chainID, address, date)
+		case cfg.Base.Height != -1:
+			balances, err = dbTypes.GetBalancesAtHeight(db, chainID, address, cfg.Base.Height)
+		default:
+			balances, err = dbTypes.GetBalancesAtHeight(db, chainID, address, math.MaxInt64)
+		}
+		if err != nil {
+			config.Log.Fatalf("Error getting the balances of %s. Err: %v", address, err)
+		}
+
+		for _, balance := range balances {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\n", address, balance.Base, balance.Symbol, balance.Balance, balance.Height)
+		}
+	}
+	w.Flush()
+}
+
+func rebuildBalances(cmd *cobra.Command, args []string) {
+	chainID := getBalancesChain()
+
+	config.Log.Infof("Rebuilding the balances of chain %s", balancesConfig.Lens.ChainID)
+	err := dbTypes.RebuildBalances(balancesDbConnection, chainID, int(balancesConfig.Base.ChunkSize), func(height int64) {
+		config.Log.Infof("Rebuilt balances up to height %d", height)
+	})
+	if err != nil {
+		config.Log.Fatal("Error rebuilding the balances", err)
+	}
+	config.Log.Info("Finished rebuilding the balances")
+}
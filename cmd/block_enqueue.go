@@ -1,7 +1,9 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"math"
 	"os"
 	"sort"
@@ -15,7 +17,7 @@ import (
 )
 
 // enqueueBlocksToProcessByMsgType will pass the blocks containing the specified msg type to the indexer
-func (idxr *Indexer) enqueueBlocksToProcessByMsgType(blockChan chan int64, chainID uint, msgType string) {
+func (idxr *Indexer) enqueueBlocksToProcessByMsgType(ctx context.Context, blockChan chan int64, chainID uint, msgType string) {
 	// get the block range
 	startBlock := idxr.cfg.Base.StartBlock
 	endBlock := idxr.cfg.Base.EndBlock
@@ -24,51 +26,52 @@ func (idxr *Indexer) enqueueBlocksToProcessByMsgType(blockChan chan int64, chain
 		endBlock = heighestBlock.Height
 	}
 
-	rows, err := idxr.db.Raw(`SELECT height FROM blocks
+	var blocks []int64
+	err := idxr.supervisor.Retry(ctx, "checking DB for blocks to reindex", func() error {
+		return idxr.db.Raw(`SELECT height FROM blocks
 							JOIN txes ON txes.block_id = blocks.id
 							JOIN messages ON messages.tx_id = txes.id
 							JOIN message_types ON message_types.id = messages.message_type_id
 							AND message_types.message_type = ?
 							WHERE height >= ? AND height <= ? AND blockchain_id = ?::int;
-							`, msgType, startBlock, endBlock, chainID).Rows()
+							`, msgType, startBlock, endBlock, chainID).Scan(&blocks).Error
+	})
 	if err != nil {
-		config.Log.Fatalf("Error checking DB for blocks to reindex. Err: %v", err)
+		return
 	}
-	defer rows.Close()
-	for rows.Next() {
-		var block int64
-		err = idxr.db.ScanRows(rows, &block)
-		if err != nil {
-			config.Log.Fatal("Error getting block height. Err: %v", err)
-		}
+	for _, block := range blocks {
 		config.Log.Debugf("Sending block %v to be re-indexed.", block)
 
-		if idxr.cfg.Base.Throttling != 0 {
-			time.Sleep(time.Second * time.Duration(idxr.cfg.Base.Throttling))
+		if !sleepWithContext(ctx, time.Second*time.Duration(idxr.cfg.Base.Throttling)) {
+			return
 		}
 
 		// Add the new block to the queue
-		blockChan <- block
+		if !sendBlock(ctx, blockChan, block) {
+			return
+		}
 	}
 }
 
-func (idxr *Indexer) enqueueFailedBlocks(blockChan chan int64, chainID uint) {
+func (idxr *Indexer) enqueueFailedBlocks(ctx context.Context, blockChan chan int64, chainID uint) {
 	// Get all failed blocks
 	failedBlocks := dbTypes.GetFailedBlocks(idxr.db, chainID)
 	if len(failedBlocks) == 0 {
 		return
 	}
 	for _, block := range failedBlocks {
-		if idxr.cfg.Base.Throttling != 0 {
-			time.Sleep(time.Second * time.Duration(idxr.cfg.Base.Throttling))
+		if !sleepWithContext(ctx, time.Second*time.Duration(idxr.cfg.Base.Throttling)) {
+			return
 		}
 		config.Log.Infof("Will re-attempt failed block: %v", block.Height)
-		blockChan <- block.Height
+		if !sendBlock(ctx, blockChan, block.Height) {
+			return
+		}
 	}
 	config.Log.Info("All failed blocks have been re-enqueued for processing")
 }
 
-func (idxr *Indexer) enqueueBlocksToProcessFromBlockInputFile(blockChan chan int64, blockInputFile string) {
+func (idxr *Indexer) enqueueBlocksToProcessFromBlockInputFile(ctx context.Context, blockChan chan int64, blockInputFile string) {
 	plan, err := os.ReadFile(blockInputFile)
 	if err != nil {
 		config.Log.Fatalf("Error reading block input file. Err: %v", err)
@@ -95,9 +98,14 @@ func (idxr *Indexer) enqueueBlocksToProcessFromBlockInputFile(blockChan chan int
 	sort.Slice(blocksToIndex, func(i, j int) bool { return blocksToIndex[i] < blocksToIndex[j] })
 
 	// Get latest block height and check to see if we are trying to index blocks outside range
-	earliestBlock, latestBlock, err := rpc.GetEarliestAndLatestBlockHeights(idxr.cl)
+	var earliestBlock, latestBlock int64
+	err = idxr.supervisor.Retry(ctx, "getting blockchain earliest and latest height", func() error {
+		var err error
+		earliestBlock, latestBlock, err = rpc.GetEarliestAndLatestBlockHeights(idxr.cl)
+		return err
+	})
 	if err != nil {
-		config.Log.Fatal("Error getting blockchain latest height. Err: %v", err)
+		return
 	}
 
 	unindexableBlockHeights := []uint64{}
@@ -121,30 +129,55 @@ func (idxr *Indexer) enqueueBlocksToProcessFromBlockInputFile(blockChan chan int
 
 	// Add jobs to the queue to be processed
 	for _, height := range blockInRange {
-		if idxr.cfg.Base.Throttling != 0 {
-			time.Sleep(time.Second * time.Duration(idxr.cfg.Base.Throttling))
+		if !sleepWithContext(ctx, time.Second*time.Duration(idxr.cfg.Base.Throttling)) {
+			return
 		}
 		config.Log.Debugf("Sending block %v to be indexed.", height)
 		// Add the new block to the queue
-		blockChan <- int64(height)
+		if !sendBlock(ctx, blockChan, int64(height)) {
+			return
+		}
 	}
 }
 
 // enqueueBlocksToProcess will pass the blocks that need to be processed to the blockchannel
-func (idxr *Indexer) enqueueBlocksToProcess(blockChan chan int64, chainID uint) {
+func (idxr *Indexer) enqueueBlocksToProcess(ctx context.Context, blockChan chan int64, chainID uint) {
 	// Unless explicitly prevented, lets attempt to enqueue any failed blocks
 	if idxr.cfg.Base.ReattemptFailedBlocks {
-		idxr.enqueueFailedBlocks(blockChan, chainID)
+		idxr.enqueueFailedBlocks(ctx, blockChan, chainID)
 	}
 
 	// Start at the last indexed block height (or the block height in the config, if set)
-	currBlock := idxr.GetIndexerStartingHeight(chainID)
+	currBlock, err := idxr.getIndexerStartingHeight(ctx, chainID)
+	if err != nil {
+		return
+	}
 	// Don't index past this block no matter what
 	lastBlock := idxr.cfg.Base.EndBlock
 	var latestBlock int64 = math.MaxInt64
+	var lastReorgCheck time.Time
 
 	// Add jobs to the queue to be processed
 	for {
+		if ctx.Err() != nil {
+			config.Log.Info("Shutting down, exiting enqueue func.")
+			return
+		}
+
+		// Blocks that turn out to be from a fork are sent to be indexed again
+		if idxr.reorgChecksEnabled() && time.Since(lastReorgCheck) >= reorgCheckInterval {
+			lastReorgCheck = time.Now()
+			reindex, err := idxr.checkRecentBlocks(ctx, chainID)
+			if err != nil {
+				return
+			}
+			for _, height := range reindex {
+				if !sendBlock(ctx, blockChan, height) {
+					return
+				}
+			}
+		}
+
 		// The program is configured to stop running after a set block height.
 		// Generally this will only be done while debugging or if a particular block was incorrectly processed.
 		if lastBlock != -1 && currBlock > lastBlock {
@@ -158,33 +191,250 @@ func (idxr *Indexer) enqueueBlocksToProcess(blockChan chan int64, chainID uint)
 		// The job queue is running out of jobs to process, see if the blockchain has produced any new blocks we haven't indexed yet.
 		if len(blockChan) <= cap(blockChan)/4 {
 			// This is the latest block height available on the Node.
-			var err error
-			latestBlock, err = rpc.GetLatestBlockHeightWithRetry(idxr.cl, idxr.cfg.Base.RequestRetryAttempts, idxr.cfg.Base.RequestRetryMaxWait)
+			err = idxr.supervisor.Retry(ctx, "getting blockchain latest height", func() error {
+				var err error
+				latestBlock, err = rpc.GetLatestBlockHeightWithRetry(idxr.cl, idxr.cfg.Base.RequestRetryAttempts, idxr.cfg.Base.RequestRetryMaxWait)
+				return err
+			})
 			if err != nil {
-				config.Log.Fatal("Error getting blockchain latest height. Err: %v", err)
+				return
 			}
+			// Heights are only indexed once they are deep enough to be considered final
+			latestBlock -= idxr.cfg.Base.ConfirmationDepth
 
 			// Throttling in case of hitting public APIs
-			if idxr.cfg.Base.Throttling != 0 {
-				time.Sleep(time.Second * time.Duration(idxr.cfg.Base.Throttling))
+			if !sleepWithContext(ctx, time.Second*time.Duration(idxr.cfg.Base.Throttling)) {
+				continue
 			}
 
 			// Already at the latest block, wait for the next block to be available.
 			for currBlock < latestBlock && (currBlock <= lastBlock || lastBlock == -1) && len(blockChan) != cap(blockChan) {
 				// if we are not re-indexing, skip curr block if already indexed
-				if !idxr.cfg.Base.ReIndex && blockAlreadyIndexed(currBlock, chainID, idxr.db) {
-					currBlock++
-					continue
+				if !idxr.cfg.Base.ReIndex {
+					var indexed bool
+					err = idxr.supervisor.Retry(ctx, fmt.Sprintf("checking DB for block %d", currBlock), func() error {
+						var err error
+						indexed, err = blockAlreadyIndexed(currBlock, chainID, idxr.db)
+						return err
+					})
+					if err != nil {
+						return
+					}
+					if indexed {
+						currBlock++
+						continue
+					}
 				}
 
-				if idxr.cfg.Base.Throttling != 0 {
-					time.Sleep(time.Second * time.Duration(idxr.cfg.Base.Throttling))
+				if !sleepWithContext(ctx, time.Second*time.Duration(idxr.cfg.Base.Throttling)) {
+					break
 				}
 
 				// Add the new block to the queue
-				blockChan <- currBlock
+				if !sendBlock(ctx, blockChan, currBlock) {
+					break
+				}
 				currBlock++
 			}
 		}
 	}
 }
+
+// How long the work queue enqueue and feed loops wait before polling the chain or the queue again
+const workQueuePollInterval = 5 * time.Second
+
+// How many heights are inserted into the work queue per statement
+const workQueueEnqueueChunk = 10000
+
+// enqueueBlocksToWorkQueue adds the blocks that need to be processed to the persistent work queue.
+// The queue itself is the checkpoint, a restart resumes right after the highest enqueued height.
+func (idxr *Indexer) enqueueBlocksToWorkQueue(ctx context.Context, chainID uint) {
+	// Unless explicitly prevented, lets attempt to requeue any failed blocks
+	if idxr.cfg.Base.ReattemptFailedBlocks {
+		failedBlocks := dbTypes.GetFailedBlocks(idxr.db, chainID)
+		heights := make([]int64, len(failedBlocks))
+		for i, block := range failedBlocks {
+			heights[i] = block.Height
+		}
+		err := idxr.supervisor.Retry(ctx, "requeueing failed blocks in the work queue", func() error {
+			return dbTypes.EnqueueBlockJobs(idxr.db, chainID, heights, true)
+		})
+		if err != nil {
+			return
+		}
+		config.Log.Infof("%d failed blocks have been requeued for processing", len(heights))
+	}
+
+	currBlock, err := idxr.getIndexerStartingHeight(ctx, chainID)
+	if err != nil {
+		return
+	}
+	// Don't index past this block no matter what
+	lastBlock := idxr.cfg.Base.EndBlock
+	var lastReorgCheck time.Time
+
+	for ctx.Err() == nil {
+		// Blocks that turn out to be from a fork are requeued to be indexed again
+		if idxr.reorgChecksEnabled() && time.Since(lastReorgCheck) >= reorgCheckInterval {
+			lastReorgCheck = time.Now()
+			reindex, err := idxr.checkRecentBlocks(ctx, chainID)
+			if err != nil {
+				return
+			}
+			if len(reindex) > 0 {
+				err = idxr.supervisor.Retry(ctx, "requeueing reorged blocks in the work queue", func() error {
+					return dbTypes.EnqueueBlockJobs(idxr.db, chainID, reindex, true)
+				})
+				if err != nil {
+					return
+				}
+			}
+		}
+
+		var latestBlock int64
+		err = idxr.supervisor.Retry(ctx, "getting blockchain latest height", func() error {
+			var err error
+			latestBlock, err = rpc.GetLatestBlockHeightWithRetry(idxr.cl, idxr.cfg.Base.RequestRetryAttempts, idxr.cfg.Base.RequestRetryMaxWait)
+			return err
+		})
+		if err != nil {
+			return
+		}
+
+		// Heights are only indexed once they are deep enough to be considered final
+		latestBlock -= idxr.cfg.Base.ConfirmationDepth
+
+		endOfRange := latestBlock - 1
+		if lastBlock != -1 && endOfRange > lastBlock {
+			endOfRange = lastBlock
+		}
+
+		for currBlock <= endOfRange {
+			chunkEnd := currBlock + workQueueEnqueueChunk - 1
+			if chunkEnd > endOfRange {
+				chunkEnd = endOfRange
+			}
+
+			config.Log.Debugf("Adding blocks %d to %d to the work queue.", currBlock, chunkEnd)
+			err = idxr.supervisor.Retry(ctx, "adding blocks to the work queue", func() error {
+				return dbTypes.EnqueueBlockJobRange(idxr.db, chainID, currBlock, chunkEnd, idxr.cfg.Base.ReIndex)
+			})
+			if err != nil {
+				return
+			}
+			currBlock = chunkEnd + 1
+		}
+
+		if lastBlock != -1 && currBlock > lastBlock {
+			config.Log.Info("Hit the last block we're allowed to index, exiting work queue enqueue func.")
+			return
+		} else if idxr.cfg.Base.ExitWhenCaughtUp && currBlock >= latestBlock {
+			config.Log.Info("Caught up to the latest block, exiting work queue enqueue func.")
+			return
+		}
+
+		sleepWithContext(ctx, workQueuePollInterval+time.Second*time.Duration(idxr.cfg.Base.Throttling))
+	}
+	config.Log.Info("Shutting down, exiting work queue enqueue func.")
+}
+
+// feedBlocksFromWorkQueue claims blocks from the work queue and passes them to the RPC workers.
+// It returns once the enqueue func is done and there is nothing left to claim.
+func (idxr *Indexer) feedBlocksFromWorkQueue(ctx context.Context, blockChan chan int64, chainID uint, batchSize int, enqueueDone chan struct{}) {
+	owner := workQueueOwner()
+	lease := time.Second * time.Duration(idxr.cfg.Base.WorkQueueLease)
+
+	for ctx.Err() == nil {
+		// Only claim what the workers will pick up soon, the lease is running while the blocks wait in the channel
+		if len(blockChan) >= batchSize {
+			sleepWithContext(ctx, 250*time.Millisecond)
+			continue
+		}
+
+		// Check before claiming, otherwise jobs enqueued right before the enqueue func finished could be missed
+		enqueueFinished := false
+		select {
+		case <-enqueueDone:
+			enqueueFinished = true
+		default:
+		}
+
+		var heights []int64
+		err := idxr.supervisor.Retry(ctx, "claiming blocks from the work queue", func() error {
+			var err error
+			heights, err = dbTypes.ClaimBlockJobs(idxr.db, chainID, owner, batchSize, lease)
+			return err
+		})
+		if err != nil {
+			return
+		}
+
+		if len(heights) == 0 {
+			if enqueueFinished {
+				config.Log.Info("No blocks left to claim in the work queue, exiting work queue feed func.")
+				return
+			}
+			sleepWithContext(ctx, workQueuePollInterval)
+			continue
+		}
+
+		// Blocks claimed but not sent are released back to the queue when the indexer exits
+		for _, height := range heights {
+			if !sleepWithContext(ctx, time.Second*time.Duration(idxr.cfg.Base.Throttling)) {
+				break
+			}
+			config.Log.Debugf("Sending block %v from the work queue to be indexed.", height)
+			if !sendBlock(ctx, blockChan, height) {
+				break
+			}
+		}
+	}
+	config.Log.Info("Shutting down, exiting work queue feed func.")
+}
+
+// getIndexerStartingHeight runs GetIndexerStartingHeight through the error policy
+func (idxr *Indexer) getIndexerStartingHeight(ctx context.Context, chainID uint) (int64, error) {
+	var startHeight int64
+	err := idxr.supervisor.Retry(ctx, "getting indexer starting height", func() error {
+		var err error
+		startHeight, err = idxr.GetIndexerStartingHeight(chainID)
+		return err
+	})
+	return startHeight, err
+}
+
+// sleepWithContext sleeps for the duration, it returns false early if the context is canceled
+func sleepWithContext(ctx context.Context, duration time.Duration) bool {
+	if duration <= 0 {
+		return ctx.Err() == nil
+	}
+
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// sendBlock adds the height to the block channel, it returns false if the context is canceled before there is room
+func sendBlock(ctx context.Context, blockChan chan int64, height int64) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case blockChan <- height:
+		return true
+	}
+}
+
+// workQueueOwner identifies this indexer process on the work queue jobs it claims
+func workQueueOwner() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+}
@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/DefiantLabs/cosmos-tax-cli/config"
+	"github.com/DefiantLabs/cosmos-tax-cli/core"
+	"github.com/DefiantLabs/cosmos-tax-cli/rpc"
+)
+
+// errorSupervisor applies the configured error policy to the failures reported by the indexing goroutines.
+// Instead of exiting the process from a goroutine, an abort cancels the indexing context so the data already
+// queried is drained to the DB before the indexer exits with the error.
+type errorSupervisor struct {
+	policy        string
+	retryAttempts int64
+	retryMaxWait  time.Duration
+	cancel        context.CancelFunc
+
+	mu       sync.Mutex
+	abortErr error
+}
+
+func newErrorSupervisor(cfg *config.IndexConfig, cancel context.CancelFunc) *errorSupervisor {
+	retryMaxWait := time.Duration(cfg.Base.RequestRetryMaxWait) * time.Second
+	if retryMaxWait < 2*time.Second {
+		retryMaxWait = 2 * time.Second
+	}
+
+	return &errorSupervisor{
+		policy:        cfg.Base.ErrorPolicy,
+		retryAttempts: cfg.Base.ErrorRetryAttempts,
+		retryMaxWait:  retryMaxWait,
+		cancel:        cancel,
+	}
+}
+
+// HandleBlockError applies the policy to a failed block.
+// retry redoes the work for the block, markFailed records the block as failed so it can be reattempted later.
+// A nil return means the block was recovered or recorded as failed and the caller can move on to the next block,
+// otherwise the indexer is aborting and the caller should stop.
+func (s *errorSupervisor) HandleBlockError(ctx context.Context, blockErr *core.BlockProcessingError, retry func() error, markFailed func(error) error) error {
+	var err error = blockErr
+
+	// Retrying is pointless if no node has the history for the block
+	if s.policy == config.ErrorPolicyRetry && retry != nil && blockErr.Code != core.NodeMissingHistoryForBlock {
+		err = s.retryWithBackoff(ctx, fmt.Sprintf("block %d", blockErr.Height), err, retry)
+		if err == nil {
+			config.Log.Infof("Block %d succeeded after being retried", blockErr.Height)
+			return nil
+		}
+	}
+
+	// Shutting down, the block was not indexed so the next run picks it up again
+	if ctx.Err() != nil {
+		return err
+	}
+
+	if s.policy == config.ErrorPolicyAbort {
+		s.Abort(err)
+		return err
+	}
+
+	config.Log.Warnf("Marking block %d failed and continuing. Err: %v", blockErr.Height, err)
+	if markErr := markFailed(err); markErr != nil {
+		err = fmt.Errorf("block %d failed and could not be recorded as failed, not safe to continue: %w", blockErr.Height, markErr)
+		s.Abort(err)
+		return err
+	}
+
+	return nil
+}
+
+// Retry retries a step that is not tied to a single block, so it can't be skipped, the indexer aborts if it keeps failing.
+// It is used for the DB and RPC lookups the enqueue and bookkeeping steps can't do without.
+func (s *errorSupervisor) Retry(ctx context.Context, description string, step func() error) error {
+	err := step()
+	if err == nil {
+		return nil
+	}
+
+	if s.policy != config.ErrorPolicyAbort {
+		err = s.retryWithBackoff(ctx, description, err, step)
+		if err == nil {
+			return nil
+		}
+	}
+
+	err = fmt.Errorf("%s failed: %w", description, err)
+	if ctx.Err() == nil {
+		s.Abort(err)
+	}
+	return err
+}
+
+// retryWithBackoff reattempts the step up to the retry attempts, it gives up early when the context is canceled
+func (s *errorSupervisor) retryWithBackoff(ctx context.Context, description string, err error, step func() error) error {
+	for attempt := int64(0); attempt < s.retryAttempts; attempt++ {
+		backoff, _ := rpc.GetBackoffDurationForAttempts(attempt, s.retryMaxWait)
+		config.Log.Warnf("Error processing %s, retrying in %v (attempt %d of %d). Err: %v", description, backoff, attempt+1, s.retryAttempts, err)
+		if !sleepWithContext(ctx, backoff) {
+			return err
+		}
+
+		err = step()
+		if err == nil {
+			return nil
+		}
+	}
+
+	return err
+}
+
+// Abort stops the indexer, only the first error is kept
+func (s *errorSupervisor) Abort(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.abortErr != nil {
+		return
+	}
+
+	config.Log.Error("Aborting indexer, finishing in flight blocks before exiting.", err)
+	s.abortErr = err
+	s.cancel()
+}
+
+// Err returns the error the indexer was aborted with, if any
+func (s *errorSupervisor) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.abortErr
+}
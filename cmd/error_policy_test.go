@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DefiantLabs/cosmos-tax-cli/config"
+	"github.com/DefiantLabs/cosmos-tax-cli/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestSupervisor(policy string, retryAttempts int64) (*errorSupervisor, context.Context) {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &errorSupervisor{
+		policy:        policy,
+		retryAttempts: retryAttempts,
+		retryMaxWait:  time.Millisecond,
+		cancel:        cancel,
+	}, ctx
+}
+
+func newTestBlockError(code core.BlockProcessingFailure) *core.BlockProcessingError {
+	return &core.BlockProcessingError{Height: 10, Code: code, Err: errors.New("query failed")}
+}
+
+func TestErrorPolicyRetryRecovers(t *testing.T) {
+	supervisor, ctx := newTestSupervisor(config.ErrorPolicyRetry, 3)
+
+	retries := 0
+	markedFailed := false
+	err := supervisor.HandleBlockError(ctx, newTestBlockError(core.BlockQueryError), func() error {
+		retries++
+		if retries < 2 {
+			return errors.New("still failing")
+		}
+		return nil
+	}, func(error) error {
+		markedFailed = true
+		return nil
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, retries)
+	assert.False(t, markedFailed)
+	assert.Nil(t, supervisor.Err())
+}
+
+func TestErrorPolicyRetryMarksFailedWhenRetriesRunOut(t *testing.T) {
+	supervisor, ctx := newTestSupervisor(config.ErrorPolicyRetry, 2)
+
+	retries := 0
+	var markedErr error
+	err := supervisor.HandleBlockError(ctx, newTestBlockError(core.BlockQueryError), func() error {
+		retries++
+		return errors.New("still failing")
+	}, func(err error) error {
+		markedErr = err
+		return nil
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, retries)
+	assert.EqualError(t, markedErr, "still failing")
+	assert.Nil(t, ctx.Err())
+}
+
+func TestErrorPolicySkipMarksFailedWithoutRetrying(t *testing.T) {
+	supervisor, ctx := newTestSupervisor(config.ErrorPolicySkip, 3)
+
+	markedFailed := false
+	err := supervisor.HandleBlockError(ctx, newTestBlockError(core.BlockQueryError), func() error {
+		t.Fatal("the skip policy must not retry")
+		return nil
+	}, func(error) error {
+		markedFailed = true
+		return nil
+	})
+
+	assert.Nil(t, err)
+	assert.True(t, markedFailed)
+	assert.Nil(t, supervisor.Err())
+}
+
+func TestErrorPolicyAbortCancelsAndKeepsFirstError(t *testing.T) {
+	supervisor, ctx := newTestSupervisor(config.ErrorPolicyAbort, 3)
+
+	blockErr := newTestBlockError(core.BlockQueryError)
+	err := supervisor.HandleBlockError(ctx, blockErr, nil, func(error) error {
+		t.Fatal("the abort policy must not mark blocks failed")
+		return nil
+	})
+
+	assert.Equal(t, blockErr, err)
+	assert.NotNil(t, ctx.Err())
+	assert.Equal(t, blockErr, supervisor.Err())
+
+	supervisor.Abort(errors.New("a later error"))
+	assert.Equal(t, blockErr, supervisor.Err())
+}
+
+func TestErrorPolicyAbortsWhenBlockCantBeMarkedFailed(t *testing.T) {
+	supervisor, ctx := newTestSupervisor(config.ErrorPolicySkip, 0)
+
+	err := supervisor.HandleBlockError(ctx, newTestBlockError(core.BlockQueryError), nil, func(error) error {
+		return errors.New("db unavailable")
+	})
+
+	assert.NotNil(t, err)
+	assert.NotNil(t, ctx.Err())
+	assert.Equal(t, err, supervisor.Err())
+}
@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/DefiantLabs/cosmos-tax-cli/config"
+	"github.com/DefiantLabs/cosmos-tax-cli/core"
+	dbTypes "github.com/DefiantLabs/cosmos-tax-cli/db"
+	"github.com/DefiantLabs/cosmos-tax-cli/metrics"
+	"github.com/DefiantLabs/cosmos-tax-cli/rpc"
+)
+
+// failedBlockRetrier retries failed blocks and failed block events in the background while the indexer runs.
+// Every row waits longer after each failed attempt, and is given up on once it reaches the max attempts.
+type failedBlockRetrier struct {
+	idxr        *Indexer
+	dbChainID   uint
+	blockChan   chan int64
+	maxWait     time.Duration
+	maxAttempts int
+
+	// The attempts of a failed block when it was last sent, it is not sent again until it fails again
+	sentAttempts map[int64]int
+	// Exhausted heights already reported, so each is only logged once
+	reportedBlocks      map[int64]struct{}
+	reportedEventBlocks map[int64]struct{}
+}
+
+func newFailedBlockRetrier(idxr *Indexer, dbChainID uint, blockChan chan int64) *failedBlockRetrier {
+	return &failedBlockRetrier{
+		idxr:                idxr,
+		dbChainID:           dbChainID,
+		blockChan:           blockChan,
+		maxWait:             time.Duration(idxr.cfg.Base.FailedBlockRetryMaxWait) * time.Second,
+		maxAttempts:         int(idxr.cfg.Base.FailedBlockMaxAttempts),
+		sentAttempts:        make(map[int64]int),
+		reportedBlocks:      make(map[int64]struct{}),
+		reportedEventBlocks: make(map[int64]struct{}),
+	}
+}
+
+// run retries the failed blocks that are due every retry interval, until stop is closed or the context is canceled
+func (r *failedBlockRetrier) run(ctx context.Context, stop <-chan struct{}) {
+	ticker := time.NewTicker(time.Duration(r.idxr.cfg.Base.FailedBlockRetryInterval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		if r.idxr.cfg.Base.ChainIndexingEnabled {
+			r.retryFailedBlocks(ctx)
+		}
+		if r.idxr.cfg.Base.BlockEventIndexingEnabled {
+			r.retryFailedEventBlocks(ctx)
+		}
+	}
+}
+
+// dueForRetry is true once the backoff for the number of attempts has passed since the last failure
+func (r *failedBlockRetrier) dueForRetry(attempts int, lastSeen time.Time) bool {
+	backoff, _ := rpc.GetBackoffDurationForAttempts(int64(attempts), r.maxWait)
+	return time.Since(lastSeen) >= backoff
+}
+
+// retryFailedBlocks sends the failed blocks that are due to the workers (or requeues them in the work queue).
+// The workers handle them like any other block, a block that fails again is recorded with one more attempt.
+func (r *failedBlockRetrier) retryFailedBlocks(ctx context.Context) {
+	retryable, exhausted, err := dbTypes.GetFailedBlocksForRetry(r.idxr.db, r.dbChainID, r.maxAttempts)
+	if err != nil {
+		config.Log.Error("Error getting failed blocks to retry.", err)
+		return
+	}
+	r.reportExhausted("block", exhausted, r.reportedBlocks)
+
+	var due []int64
+	for _, block := range retryable {
+		if attempts, ok := r.sentAttempts[block.Height]; ok && attempts == block.Attempts {
+			continue
+		}
+		if r.dueForRetry(block.Attempts, block.LastSeen) {
+			due = append(due, block.Height)
+			r.sentAttempts[block.Height] = block.Attempts
+		}
+	}
+	if len(due) == 0 {
+		return
+	}
+
+	config.Log.Infof("Retrying %d failed blocks", len(due))
+	if r.idxr.cfg.Base.WorkQueue {
+		err := dbTypes.EnqueueBlockJobs(r.idxr.db, r.dbChainID, due, true)
+		if err != nil {
+			config.Log.Error("Error requeueing failed blocks in the work queue.", err)
+			for _, height := range due {
+				delete(r.sentAttempts, height)
+			}
+		}
+		return
+	}
+
+	for _, height := range due {
+		if !sendBlock(ctx, r.blockChan, height) {
+			return
+		}
+	}
+}
+
+// retryFailedEventBlocks queries and writes the events of the failed event blocks that are due
+func (r *failedBlockRetrier) retryFailedEventBlocks(ctx context.Context) {
+	retryable, exhausted, err := dbTypes.GetFailedEventBlocksForRetry(r.idxr.db, r.dbChainID, r.maxAttempts)
+	if err != nil {
+		config.Log.Error("Error getting failed event blocks to retry.", err)
+		return
+	}
+	r.reportExhausted("block_events", exhausted, r.reportedEventBlocks)
+
+	for _, block := range retryable {
+		if ctx.Err() != nil {
+			return
+		}
+		if !r.dueForRetry(block.Attempts, block.LastSeen) {
+			continue
+		}
+
+		config.Log.Infof("Retrying failed block events for block %d (attempt %d)", block.Height, block.Attempts+1)
+		err := r.indexEventBlock(block.Height)
+		if err != nil {
+			config.Log.Warnf("Retrying block events for block %d failed. Err: %v", block.Height, err)
+			if markErr := r.idxr.markBlockEventsFailed(core.AsBlockProcessingError(block.Height, core.FailedBlockEventHandling, err)); markErr != nil {
+				config.Log.Error(fmt.Sprintf("Error recording the failed retry of block events for block %d.", block.Height), markErr)
+			}
+			continue
+		}
+
+		if err := dbTypes.DeleteFailedEventBlock(r.idxr.db, block.Height, r.dbChainID); err != nil {
+			config.Log.Error(fmt.Sprintf("Error removing block %d from the failed event blocks.", block.Height), err)
+		}
+	}
+}
+
+func (r *failedBlockRetrier) indexEventBlock(height int64) error {
+	data, err := r.idxr.processBlockEvents(height, r.idxr.processor.HandleFailedBlock)
+	if err != nil || data == nil {
+		return err
+	}
+
+	return dbTypes.IndexBlockEvents(r.idxr.db, r.idxr.denoms, r.idxr.dryRun, data.blockHeight, data.blockTime, r.idxr.watchList.FilterEvents(data.blockRelevantEvents),
+		r.idxr.cfg.Lens.ChainID, r.idxr.cfg.Lens.ChainName, fmt.Sprintf("block %d", data.blockHeight))
+}
+
+// reportExhausted logs the heights that newly reached the max attempts and updates the exhausted metric
+func (r *failedBlockRetrier) reportExhausted(kind string, exhausted []int64, reported map[int64]struct{}) {
+	metrics.ExhaustedFailedBlocks.WithLabelValues(r.idxr.cfg.Lens.ChainID, kind).Set(float64(len(exhausted)))
+
+	var newlyExhausted []int64
+	for _, height := range exhausted {
+		if _, ok := reported[height]; !ok {
+			reported[height] = struct{}{}
+			newlyExhausted = append(newlyExhausted, height)
+		}
+	}
+	if len(newlyExhausted) > 0 {
+		config.Log.Warnf("Failed %s heights reached %d attempts and will no longer be retried in the background: %v", kind, r.maxAttempts, newlyExhausted)
+	}
+}
@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/DefiantLabs/cosmos-tax-cli/config"
+	dbTypes "github.com/DefiantLabs/cosmos-tax-cli/db"
+	"github.com/spf13/cobra"
+	"gorm.io/gorm"
+)
+
+var (
+	failedBlocksConfig       config.FailedBlocksConfig
+	failedBlocksDbConnection *gorm.DB
+)
+
+func init() {
+	config.SetupLogFlags(&failedBlocksConfig.Log, failedBlocksCmd)
+	config.SetupDatabaseFlags(&failedBlocksConfig.Database, failedBlocksCmd)
+	config.SetupLensFlags(&failedBlocksConfig.Lens, failedBlocksCmd)
+	config.SetupFailedBlocksSpecificFlags(&failedBlocksConfig, failedBlocksCmd)
+	failedBlocksCmd.AddCommand(failedBlocksListCmd, failedBlocksReasonsCmd, failedBlocksRetryCmd)
+	rootCmd.AddCommand(failedBlocksCmd)
+}
+
+var failedBlocksCmd = &cobra.Command{
+	Use:   "failed-blocks",
+	Short: "Inspect and retry the blocks the indexer could not index.",
+	Long: `Lists the failed blocks with the details of their latest failure, groups them by failure reason and
+	requeues them to be indexed again. The base.code, base.message-type, base.start-block and base.end-block
+	flags select the failed blocks for every subcommand.`,
+}
+
+var failedBlocksListCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "List the failed blocks with the details of their latest failure.",
+	PreRunE: setupFailedBlocks,
+	Run:     listFailedBlocks,
+}
+
+var failedBlocksReasonsCmd = &cobra.Command{
+	Use:     "reasons",
+	Short:   "Group the failed blocks by failure code and message type.",
+	PreRunE: setupFailedBlocks,
+	Run:     listFailedBlockReasons,
+}
+
+var failedBlocksRetryCmd = &cobra.Command{
+	Use:   "retry",
+	Short: "Requeue the failed blocks in the work queue to be indexed again.",
+	Long: `Requeues the selected failed blocks in the work queue, an indexer running with base.work-queue picks them up.
+	With base.output-file the heights are also written to a file that can be indexed with base.block-input-file.
+	Blocks are removed from the failed blocks once they are indexed.`,
+	PreRunE: setupFailedBlocks,
+	Run:     retryFailedBlocks,
+}
+
+func setupFailedBlocks(cmd *cobra.Command, args []string) error {
+	bindFlags(cmd, viperConf)
+
+	err := failedBlocksConfig.Validate()
+	if err != nil {
+		return err
+	}
+
+	ignoredKeys := config.CheckSuperfluousFailedBlocksKeys(viperConf.AllKeys())
+
+	if len(ignoredKeys) > 0 {
+		config.Log.Warnf("Warning, the following invalid keys will be ignored: %v", ignoredKeys)
+	}
+
+	setupLogger(failedBlocksConfig.Log.Level, failedBlocksConfig.Log.Path, failedBlocksConfig.Log.Pretty)
+
+	db, err := connectToDBAndMigrate(failedBlocksConfig.Database)
+	if err != nil {
+		config.Log.Fatal("Could not establish connection to the database", err)
+	}
+
+	failedBlocksDbConnection = db
+
+	return nil
+}
+
+// getFailedBlocksChainAndFilter looks up the configured chain and builds the filter from the flags
+func getFailedBlocksChainAndFilter() (uint, dbTypes.FailedBlockFilter) {
+	var chain dbTypes.Chain
+	err := failedBlocksDbConnection.Where("chain_id = ?", failedBlocksConfig.Lens.ChainID).First(&chain).Error
+	if err != nil {
+		config.Log.Fatalf("Error finding chain %s in the DB. Err: %v", failedBlocksConfig.Lens.ChainID, err)
+	}
+
+	return chain.ID, dbTypes.FailedBlockFilter{
+		Code:        failedBlocksConfig.Base.Code,
+		MessageType: failedBlocksConfig.Base.MessageType,
+		StartHeight: failedBlocksConfig.Base.StartBlock,
+		EndHeight:   failedBlocksConfig.Base.EndBlock,
+	}
+}
+
+func listFailedBlocks(cmd *cobra.Command, args []string) {
+	chainID, filter := getFailedBlocksChainAndFilter()
+
+	failedBlocks, err := dbTypes.GetFilteredFailedBlocks(failedBlocksDbConnection, chainID, filter)
+	if err != nil {
+		config.Log.Fatal("Error getting failed blocks", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "HEIGHT\tCODE\tMESSAGE TYPE\tTX HASH\tATTEMPTS\tFIRST SEEN\tLAST SEEN\tERROR")
+	for _, block := range failedBlocks {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%d\t%s\t%s\t%s\n", block.Height, block.Code, block.MessageType, block.TxHash,
+			block.Attempts, formatSeen(block.FirstSeen), formatSeen(block.LastSeen), block.Error)
+	}
+	w.Flush()
+
+	fmt.Printf("%d failed blocks\n", len(failedBlocks))
+}
+
+func listFailedBlockReasons(cmd *cobra.Command, args []string) {
+	chainID, filter := getFailedBlocksChainAndFilter()
+
+	reasons, err := dbTypes.GetFailedBlockReasons(failedBlocksDbConnection, chainID, filter)
+	if err != nil {
+		config.Log.Fatal("Error getting failed block reasons", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "BLOCKS\tCODE\tMESSAGE TYPE\tLOWEST HEIGHT\tHIGHEST HEIGHT\tLAST SEEN")
+	for _, reason := range reasons {
+		var lastSeen time.Time
+		if reason.LastSeen != nil {
+			lastSeen = *reason.LastSeen
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%d\t%s\n", reason.Blocks, reason.Code, reason.MessageType, reason.MinHeight, reason.MaxHeight, formatSeen(lastSeen))
+	}
+	w.Flush()
+}
+
+func retryFailedBlocks(cmd *cobra.Command, args []string) {
+	chainID, filter := getFailedBlocksChainAndFilter()
+
+	failedBlocks, err := dbTypes.GetFilteredFailedBlocks(failedBlocksDbConnection, chainID, filter)
+	if err != nil {
+		config.Log.Fatal("Error getting failed blocks", err)
+	}
+	if len(failedBlocks) == 0 {
+		config.Log.Info("No failed blocks to retry")
+		return
+	}
+
+	heights := make([]int64, len(failedBlocks))
+	for i, block := range failedBlocks {
+		heights[i] = block.Height
+	}
+
+	err = dbTypes.EnqueueBlockJobs(failedBlocksDbConnection, chainID, heights, true)
+	if err != nil {
+		config.Log.Fatal("Error adding the failed blocks to the work queue", err)
+	}
+	config.Log.Infof("Requeued %d failed blocks in the work queue", len(heights))
+
+	if failedBlocksConfig.Base.OutputFile != "" {
+		heightsJSON, err := json.Marshal(heights)
+		if err != nil {
+			config.Log.Fatal("Error encoding the block heights", err)
+		}
+		err = os.WriteFile(failedBlocksConfig.Base.OutputFile, heightsJSON, 0o600)
+		if err != nil {
+			config.Log.Fatal("Error writing the output file", err)
+		}
+		config.Log.Infof("Wrote %d block heights to %s", len(heights), failedBlocksConfig.Base.OutputFile)
+	}
+}
+
+// formatSeen formats a first/last seen time, blocks recorded before failure details were stored have none
+func formatSeen(seen time.Time) string {
+	if seen.IsZero() {
+		return "-"
+	}
+	return seen.Format(time.RFC3339)
+}
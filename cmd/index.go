@@ -1,20 +1,25 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"log"
+	"os"
+	"os/signal"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/DefiantLabs/lens/client"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
 	"github.com/go-co-op/gocron"
 
 	"github.com/DefiantLabs/cosmos-tax-cli/config"
 	"github.com/DefiantLabs/cosmos-tax-cli/core"
 	eventTypes "github.com/DefiantLabs/cosmos-tax-cli/cosmos/events"
 	dbTypes "github.com/DefiantLabs/cosmos-tax-cli/db"
-	"github.com/DefiantLabs/cosmos-tax-cli/osmosis"
+	"github.com/DefiantLabs/cosmos-tax-cli/metrics"
 	"github.com/DefiantLabs/cosmos-tax-cli/rpc"
 	"github.com/DefiantLabs/cosmos-tax-cli/tasks"
 	"github.com/spf13/cobra"
@@ -23,15 +28,26 @@ import (
 )
 
 type Indexer struct {
-	cfg       *config.IndexConfig
-	dryRun    bool
-	db        *gorm.DB
-	cl        *client.ChainClient
-	scheduler *gocron.Scheduler
+	cfg                 *config.IndexConfig
+	dryRun              bool
+	db                  *gorm.DB
+	cl                  *client.ChainClient
+	pool                *rpc.ClientPool
+	processor           *core.ChainProcessor
+	denoms              *dbTypes.DenomCache
+	scheduler           *gocron.Scheduler
+	supervisor          *errorSupervisor
+	lastCommittedHeight int64              // highest block written by doDBUpdates, reported on shutdown
+	watchList           *dbTypes.WatchList // nil unless in watch mode, then only data touching watched addresses is written
+	addresses           []string           // set by index-address, then only the blocks touching these addresses are indexed
 }
 
 var indexer Indexer
 
+// The indexers of the chains to index, every chain of the chains list or just indexer without one. They share the DB
+// and the scheduler, everything else is set up per chain.
+var chainIndexers []*Indexer
+
 func init() {
 	indexer.cfg = &config.IndexConfig{}
 	config.SetupLogFlags(&indexer.cfg.Log, indexCmd)
@@ -48,7 +64,9 @@ var indexCmd = &cobra.Command{
 	Short: "Indexes the blockchain according to the configuration defined.",
 	Long: `Indexes the Cosmos-based blockchain according to the configurations found on the command line
 	or in the specified config file. Indexes taxable events into a database for easy querying. It is
-	highly recommended to keep this command running as a background service to keep your index up to date.`,
+	highly recommended to keep this command running as a background service to keep your index up to date.
+	Several chains can be indexed into the same database by a single process, by listing them as [[chains]]
+	tables with their own lens and base settings in the config file.`,
 	PreRunE: setupIndex,
 	Run:     index,
 }
@@ -56,11 +74,26 @@ var indexCmd = &cobra.Command{
 func setupIndex(cmd *cobra.Command, args []string) error {
 	bindFlags(cmd, viperConf)
 
-	err := indexer.cfg.Validate()
+	chainConfs, err := indexer.cfg.ChainConfigs(viperConf.Get("chains"))
 	if err != nil {
 		return err
 	}
 
+	for _, chainConf := range chainConfs {
+		err = chainConf.Validate()
+		if err != nil {
+			if chainConf != indexer.cfg {
+				return fmt.Errorf("chain %s: %w", chainConf.Lens.ChainID, err)
+			}
+			return err
+		}
+
+		// 0 is an invalid starting block, set it to 1
+		if chainConf.Base.StartBlock == 0 {
+			chainConf.Base.StartBlock = 1
+		}
+	}
+
 	ignoredKeys := config.CheckSuperfluousIndexKeys(viperConf.AllKeys())
 
 	if len(ignoredKeys) > 0 {
@@ -69,11 +102,6 @@ func setupIndex(cmd *cobra.Command, args []string) error {
 
 	setupLogger(indexer.cfg.Log.Level, indexer.cfg.Log.Path, indexer.cfg.Log.Pretty)
 
-	// 0 is an invalid starting block, set it to 1
-	if indexer.cfg.Base.StartBlock == 0 {
-		indexer.cfg.Base.StartBlock = 1
-	}
-
 	db, err := connectToDBAndMigrate(indexer.cfg.Database)
 	if err != nil {
 		config.Log.Fatal("Could not establish connection to the database", err)
@@ -83,84 +111,187 @@ func setupIndex(cmd *cobra.Command, args []string) error {
 
 	indexer.scheduler = gocron.NewScheduler(time.UTC)
 
-	// We should stop relying on the denom cache now that we are running this as a CLI tool only
-	dbTypes.CacheDenoms(db)
-	dbTypes.CacheIBCDenoms(db)
-
 	indexer.dryRun = indexer.cfg.Base.Dry
 
+	chainIndexers = nil
+	for _, chainConf := range chainConfs {
+		if chainConf == indexer.cfg {
+			chainIndexers = append(chainIndexers, &indexer)
+			continue
+		}
+		chainIndexer := indexer
+		chainIndexer.cfg = chainConf
+		chainIndexer.dryRun = chainConf.Base.Dry
+		chainIndexers = append(chainIndexers, &chainIndexer)
+	}
+
 	return nil
 }
 
 // The Indexer struct is used to perform index operations
 
-func setupIndexer() *Indexer {
+// setup connects to the chain and sets up everything chain specific, the client, the processor parsing the chain's
+// txs and events and the chain's scheduled tasks
+func (idxr *Indexer) setup() error {
 	var err error
 
-	// Setup chain specific stuff
-	core.SetupAddressRegex(indexer.cfg.Lens.AccountPrefix + "(valoper)?1[a-z0-9]{38}")
-	core.SetupAddressPrefix(indexer.cfg.Lens.AccountPrefix)
-	core.ChainSpecificBeginBlockerEventTypeHandlerBootstrap(indexer.cfg.Lens.ChainID)
-	core.ChainSpecificEndBlockerEventTypeHandlerBootstrap(indexer.cfg.Lens.ChainID)
-	core.ChainSpecificEpochIdentifierEventTypeHandlersBootstrap(indexer.cfg.Lens.ChainID)
-
-	config.SetChainConfig(indexer.cfg.Lens.AccountPrefix)
-
 	// Setup scheduler to periodically update denoms
-	if indexer.cfg.Base.API != "" {
-		_, err = indexer.scheduler.Every(6).Hours().Do(tasks.IBCDenomUpsertTask, indexer.cfg.Base.API, indexer.db)
+	if idxr.cfg.Base.API != "" {
+		_, err = idxr.scheduler.Every(6).Hours().Do(tasks.IBCDenomUpsertTask, idxr.cfg.Base.API, idxr.db)
 		if err != nil {
 			config.Log.Error("Error scheduling ibc denom upsert task. Err: ", err)
 		}
-
-		indexer.scheduler.StartAsync()
 	}
 
+	registry := core.NewChainRegistry(idxr.cfg.Lens.ChainID)
+
 	// Some chains do not have the denom metadata URL available on chain, so we do chain specific downloads instead.
-	tasks.DoChainSpecificUpsertDenoms(indexer.db, indexer.cfg.Lens.ChainID, indexer.cfg.Base.RequestRetryAttempts, indexer.cfg.Base.RequestRetryMaxWait, indexer.cfg.AssetList)
-	indexer.cl = config.GetLensClient(indexer.cfg.Lens)
+	if upsertDenoms := registry.DenomUpsert(); upsertDenoms != nil {
+		upsertDenoms(idxr.db, idxr.cfg.Base.RequestRetryAttempts, idxr.cfg.Base.RequestRetryMaxWait, idxr.cfg.AssetList)
+	}
+	// may want to move this elsewhere, or eliminate entirely
+	// I would prefer we just grab the denoms when needed always
+	// Current problem: we use the denom cache in various blocks later on
+	// Each chain has its own cache, so setting up a chain does not reload the cache of the chains already indexing
+	idxr.denoms = dbTypes.NewDenomCache(idxr.db)
+
+	// Block queries are spread over every configured RPC endpoint, the first one is used for everything else
+	lensClients := config.GetLensClients(idxr.cfg.Lens)
+	idxr.cl = lensClients[0]
+	idxr.pool = rpc.NewClientPool(lensClients)
 
-	core.ChainSpecificMessageTypeHandlerBootstrap(indexer.cfg.Lens.ChainID, indexer.cl)
+	// Setup chain specific stuff
+	idxr.processor, err = core.NewChainProcessor(idxr.cl, registry, core.ChainProcessorOptions{
+		AccountPrefix:          idxr.cfg.Lens.AccountPrefix,
+		StoreRawTxs:            idxr.cfg.Base.StoreRawTxs,
+		LenientUnknownMessages: idxr.cfg.Base.UnknownMessages == config.UnknownMessagesLenient,
+		BankEventFallback:      idxr.cfg.Base.BankEventFallback,
+		Denoms:                 idxr.denoms,
+	})
+	if err != nil {
+		return fmt.Errorf("error setting up the chain processor: %w", err)
+	}
 
 	// Depending on the app configuration, wait for the chain to catch up
-	chainCatchingUp, err := rpc.IsCatchingUp(indexer.cl)
-	for indexer.cfg.Base.WaitForChain && chainCatchingUp && err == nil {
+	chainCatchingUp, err := rpc.IsCatchingUp(idxr.cl)
+	for idxr.cfg.Base.WaitForChain && chainCatchingUp && err == nil {
 		// Wait between status checks, don't spam the node with requests
-		config.Log.Debug("Chain is still catching up, please wait or disable check in config.")
-		time.Sleep(time.Second * time.Duration(indexer.cfg.Base.WaitForChainDelay))
-		chainCatchingUp, err = rpc.IsCatchingUp(indexer.cl)
+		config.Log.Debugf("Chain %s is still catching up, please wait or disable check in config.", idxr.cfg.Lens.ChainID)
+		time.Sleep(time.Second * time.Duration(idxr.cfg.Base.WaitForChainDelay))
+		chainCatchingUp, err = rpc.IsCatchingUp(idxr.cl)
 
 		// This EOF error pops up from time to time and is unpredictable
 		// It is most likely an error on the node, we would need to see any error logs on the node side
 		// Try one more time
 		if err != nil && strings.HasSuffix(err.Error(), "EOF") {
-			time.Sleep(time.Second * time.Duration(indexer.cfg.Base.WaitForChainDelay))
-			chainCatchingUp, err = rpc.IsCatchingUp(indexer.cl)
+			time.Sleep(time.Second * time.Duration(idxr.cfg.Base.WaitForChainDelay))
+			chainCatchingUp, err = rpc.IsCatchingUp(idxr.cl)
 		}
 	}
 	if err != nil {
-		config.Log.Fatal("Error querying chain status.", err)
+		return fmt.Errorf("error querying chain status: %w", err)
 	}
 
-	if indexer.cfg.Lens.ChainID == osmosis.ChainID && indexer.cfg.Base.EpochEventIndexingEnabled {
-		err := osmosis.SetupOsmosisEpochIndexer(indexer.cl, indexer.cfg.Base.EpochIndexingIdentifier)
+	// The processor shares the registry, handlers the setup adds are used for the chain's epoch events
+	if idxr.cfg.Base.EpochEventIndexingEnabled {
+		err := registry.SetupEpochIndexer(idxr.cl, idxr.cfg.Base.EpochIndexingIdentifier)
 		if err != nil {
-			config.Log.Fatal("Error setting up Osmosis Epoch Indexer.", err)
+			return fmt.Errorf("error setting up the epoch indexer: %w", err)
 		}
 	}
 
-	return &indexer
+	return nil
 }
 
 func index(cmd *cobra.Command, args []string) {
-	// Setup the indexer with config, db, and cl
-	idxr := setupIndexer()
-	dbConn, err := idxr.db.DB()
+	dbConn, err := indexer.db.DB()
 	if err != nil {
 		config.Log.Fatal("Failed to connect to DB", err)
 	}
 	defer dbConn.Close()
 
+	// SIGINT and SIGTERM stop the enqueueing and the event indexers, the data already queried is still written to the DB
+	signalCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	indexingDone := make(chan struct{})
+	defer close(indexingDone)
+	go func() {
+		select {
+		case <-signalCtx.Done():
+			config.Log.Info("Shutdown signal received, finishing in flight blocks before exiting. Signal again to force quit.")
+			// Let a second signal kill the process the default way
+			stop()
+		case <-indexingDone:
+		}
+	}()
+
+	// Setup scheduler to periodically link IBC transfers to the other chains indexed in the DB
+	if indexer.cfg.Base.IBCLinkInterval > 0 && !indexer.dryRun {
+		_, err = indexer.scheduler.Every(int(indexer.cfg.Base.IBCLinkInterval)).Seconds().Do(tasks.IBCLinkTask, indexer.db)
+		if err != nil {
+			config.Log.Error("Error scheduling ibc link task. Err: ", err)
+		}
+	}
+	// The chains schedule their own tasks once they are set up
+	indexer.scheduler.StartAsync()
+
+	if indexer.cfg.Base.MetricsAddress != "" {
+		metrics.StartServer(indexer.cfg.Base.MetricsAddress)
+	}
+
+	// Every chain runs its own pipeline, a chain that fails or aborts does not stop the others
+	chainErrs := make([]error, len(chainIndexers))
+	var wg sync.WaitGroup
+	for i, idxr := range chainIndexers {
+		wg.Add(1)
+		go func(i int, idxr *Indexer) {
+			defer wg.Done()
+			chainErrs[i] = idxr.run(signalCtx)
+		}(i, idxr)
+	}
+	wg.Wait()
+
+	// If we error out in the main loop, this will block. Meaning we may not know of an error for 6 hours until last scheduled task stops
+	indexer.scheduler.Stop()
+
+	if err := errors.Join(chainErrs...); err != nil {
+		config.Log.Fatalf("Indexer aborted. Err: %v", err)
+	}
+}
+
+// run sets up the chain and indexes it until it is done, the context is canceled or the indexer aborts. The error is
+// the reason the indexer aborted.
+func (idxr *Indexer) run(signalCtx context.Context) error {
+	chainID := idxr.cfg.Lens.ChainID
+	err := idxr.setup()
+	if err != nil {
+		config.Log.Error(fmt.Sprintf("Error setting up the indexer for chain %s.", chainID), err)
+		return fmt.Errorf("chain %s: %w", chainID, err)
+	}
+
+	// Failures are handled according to the error policy, aborting cancels the context the same way a signal does
+	ctx, cancel := context.WithCancel(signalCtx)
+	defer cancel()
+	idxr.supervisor = newErrorSupervisor(idxr.cfg, cancel)
+
+	chain := dbTypes.Chain{
+		ChainID: chainID,
+		Name:    idxr.cfg.Lens.ChainName,
+	}
+	dbChainID, err := dbTypes.GetDBChainID(idxr.db, chain)
+	if err != nil {
+		config.Log.Error(fmt.Sprintf("Failed to add/create chain %s in DB", chainID), err)
+		return fmt.Errorf("chain %s: %w", chainID, err)
+	}
+
+	if idxr.cfg.Base.WatchMode {
+		err := idxr.setupWatchList(ctx, dbChainID)
+		if err != nil {
+			config.Log.Error(fmt.Sprintf("Error setting up the watch list for chain %s.", chainID), err)
+			return fmt.Errorf("chain %s: %w", chainID, err)
+		}
+	}
+
 	// blockChan are just the block heights; limit max jobs in the queue, otherwise this queue would contain one
 	// item (block height) for every block on the entire blockchain we're indexing. Furthermore, once the queue
 	// is close to empty, we will spin up a new thread to fill it up with new jobs.
@@ -185,7 +316,7 @@ func index(cmd *cobra.Command, args []string) {
 		for i := 0; i < rpcQueryThreads; i++ {
 			txChanWaitGroup.Add(1)
 			go func() {
-				idxr.queryRPC(blockChan, txDataChan, core.HandleFailedBlock)
+				idxr.queryRPC(ctx, blockChan, txDataChan, idxr.processor.HandleFailedBlock, dbChainID)
 				txChanWaitGroup.Done()
 			}()
 		}
@@ -203,95 +334,168 @@ func index(cmd *cobra.Command, args []string) {
 	blockEventsDataChan := make(chan *blockEventsDBData, 4*rpcQueryThreads)
 	if idxr.cfg.Base.BlockEventIndexingEnabled {
 		wg.Add(1)
-		go idxr.indexBlockEvents(&wg, core.HandleFailedBlock, blockEventsDataChan)
+		go idxr.indexBlockEvents(ctx, &wg, idxr.processor.HandleFailedBlock, blockEventsDataChan)
 	} else {
 		close(blockEventsDataChan)
 	}
 
-	chain := dbTypes.Chain{
-		ChainID: idxr.cfg.Lens.ChainID,
-		Name:    idxr.cfg.Lens.ChainName,
-	}
-	dbChainID, err := dbTypes.GetDBChainID(idxr.db, chain)
-	if err != nil {
-		config.Log.Fatal("Failed to add/create chain in DB", err)
-	}
-
 	// Epoch BeginBlocker and EndBlocker indexing requirements. Indexes block events that took place in the BeginBlock and EndBlock state transitions of Epochs
 	epochEventsDataChan := make(chan *epochEventsDBData, 4*rpcQueryThreads)
 	if idxr.cfg.Base.EpochEventIndexingEnabled {
 		wg.Add(1)
-		go idxr.indexEpochEvents(&wg, core.HandleFailedBlock, epochEventsDataChan, dbChainID)
+		go idxr.indexEpochEvents(ctx, &wg, idxr.processor.HandleFailedBlock, epochEventsDataChan, dbChainID)
 	} else {
 		close(epochEventsDataChan)
 	}
 
+	if idxr.cfg.Base.MetricsAddress != "" {
+		metrics.RegisterChannelDepth(chainID, "block", func() int { return len(blockChan) })
+		metrics.RegisterChannelDepth(chainID, "tx_data", func() int { return len(txDataChan) })
+		metrics.RegisterChannelDepth(chainID, "block_events_data", func() int { return len(blockEventsDataChan) })
+		metrics.RegisterChannelDepth(chainID, "epoch_events_data", func() int { return len(epochEventsDataChan) })
+		go idxr.trackChainHeight(ctx)
+	}
+
 	// Start a thread to index the data queried from the chain.
 	if idxr.cfg.Base.ChainIndexingEnabled || idxr.cfg.Base.BlockEventIndexingEnabled || idxr.cfg.Base.EpochEventIndexingEnabled {
 		wg.Add(1)
 		go idxr.doDBUpdates(&wg, txDataChan, blockEventsDataChan, epochEventsDataChan, dbChainID)
 	}
 
+	// Failed blocks are retried in the background while blocks are being enqueued, the retrier is stopped before the
+	// block channel is closed
+	retryStop := make(chan struct{})
+	retryDone := make(chan struct{})
+	if idxr.cfg.Base.FailedBlockRetryInterval > 0 && !idxr.dryRun {
+		go func() {
+			newFailedBlockRetrier(idxr, dbChainID, blockChan).run(ctx, retryStop)
+			close(retryDone)
+		}()
+	} else {
+		close(retryDone)
+	}
+	var stopRetriesOnce sync.Once
+	stopRetries := func() {
+		stopRetriesOnce.Do(func() { close(retryStop) })
+		<-retryDone
+	}
+
 	// Add jobs to the queue to be processed
 	if idxr.cfg.Base.ChainIndexingEnabled {
 		switch {
+		case idxr.isAddressBackfill():
+			idxr.enqueueBlocksForAddresses(ctx, blockChan, dbChainID)
 		case idxr.cfg.Base.ReindexMessageType != "":
-			idxr.enqueueBlocksToProcessByMsgType(blockChan, dbChainID, idxr.cfg.Base.ReindexMessageType)
+			idxr.enqueueBlocksToProcessByMsgType(ctx, blockChan, dbChainID, idxr.cfg.Base.ReindexMessageType)
 		case idxr.cfg.Base.BlockInputFile != "":
-			idxr.enqueueBlocksToProcessFromBlockInputFile(blockChan, idxr.cfg.Base.BlockInputFile)
+			idxr.enqueueBlocksToProcessFromBlockInputFile(ctx, blockChan, idxr.cfg.Base.BlockInputFile)
+		case idxr.cfg.Base.WorkQueue:
+			enqueueDone := make(chan struct{})
+			go func() {
+				idxr.enqueueBlocksToWorkQueue(ctx, dbChainID)
+				close(enqueueDone)
+			}()
+			idxr.feedBlocksFromWorkQueue(ctx, blockChan, dbChainID, rpcQueryThreads, enqueueDone)
 		default:
-			idxr.enqueueBlocksToProcess(blockChan, dbChainID)
+			idxr.enqueueBlocksToProcess(ctx, blockChan, dbChainID)
 		}
 
 		// close the block chan once all blocks have been written to it
+		stopRetries()
 		close(blockChan)
 	}
 
-	// If we error out in the main loop, this will block. Meaning we may not know of an error for 6 hours until last scheduled task stops
-	idxr.scheduler.Stop()
 	wg.Wait()
+	stopRetries()
+
+	// Blocks claimed from the work queue that never made it to the workers can be claimed again right away
+	if idxr.cfg.Base.WorkQueue {
+		released, err := dbTypes.ReleaseBlockJobs(idxr.db, dbChainID, workQueueOwner())
+		if err != nil {
+			config.Log.Error("Error releasing claimed work queue blocks, they will be claimable once their lease expires.", err)
+		} else if released > 0 {
+			config.Log.Infof("Released %d unprocessed blocks back to the work queue", released)
+		}
+	}
+
+	if err := idxr.supervisor.Err(); err != nil {
+		config.Log.Errorf("Indexer for chain %s aborted. Last committed block height: %d. Err: %v", chainID, idxr.lastCommittedHeight, err)
+		return fmt.Errorf("chain %s: %w", chainID, err)
+	} else if ctx.Err() != nil {
+		config.Log.Infof("Indexer for chain %s shut down. Last committed block height: %d", chainID, idxr.lastCommittedHeight)
+	} else {
+		config.Log.Infof("Indexer for chain %s finished. Last committed block height: %d", chainID, idxr.lastCommittedHeight)
+	}
+
+	return nil
+}
+
+// trackChainHeight periodically records the latest chain height so the head lag can be alerted on
+func (idxr *Indexer) trackChainHeight(ctx context.Context) {
+	for {
+		latestHeight, err := rpc.GetLatestBlockHeight(idxr.cl)
+		if err != nil {
+			config.Log.Warnf("Error getting blockchain latest height for metrics. Err: %v", err)
+		} else {
+			metrics.SetChainHeight(idxr.cfg.Lens.ChainID, latestHeight)
+		}
+		if !sleepWithContext(ctx, 30*time.Second) {
+			return
+		}
+	}
 }
 
-func GetBlockEventsStartIndexHeight(db *gorm.DB, chainID string) int64 {
+func GetBlockEventsStartIndexHeight(db *gorm.DB, chainID string) (int64, error) {
 	block, err := dbTypes.GetHighestTaxableEventBlock(db, chainID)
 	if err != nil && err.Error() != "record not found" {
-		log.Fatalf("Cannot retrieve highest indexed block event. Err: %v", err)
+		return 0, fmt.Errorf("cannot retrieve highest indexed block event: %w", err)
 	}
 
-	return block.Height
+	return block.Height, nil
 }
 
 // blockAlreadyIndexed will return true if the block is already in the DB
-func blockAlreadyIndexed(blockHeight int64, chainID uint, db *gorm.DB) bool {
+func blockAlreadyIndexed(blockHeight int64, chainID uint, db *gorm.DB) (bool, error) {
 	var exists bool
 	err := db.Raw(`SELECT count(*) > 0 FROM blocks WHERE height = ?::int AND blockchain_id = ?::int AND indexed = true AND time_stamp != '0001-01-01T00:00:00.000Z';`, blockHeight, chainID).Row().Scan(&exists)
 	if err != nil {
-		config.Log.Fatalf("Error checking DB for block. Err: %v", err)
+		return false, fmt.Errorf("error checking DB for block: %w", err)
 	}
-	return exists
+	return exists, nil
 }
 
 // GetIndexerStartingHeight will determine which block to start at
 // if start block is set to -1, it will start at the highest block indexed
 // otherwise, it will start at the first missing block between the start and end height
-func (idxr *Indexer) GetIndexerStartingHeight(chainID uint) int64 {
+func (idxr *Indexer) GetIndexerStartingHeight(chainID uint) (int64, error) {
+	// The work queue remembers what was already enqueued, resume right after it instead of scanning the blocks table
+	if idxr.cfg.Base.WorkQueue && !idxr.cfg.Base.ReIndex {
+		highestJobHeight, found, err := dbTypes.GetHighestBlockJobHeight(idxr.db, chainID)
+		if err != nil {
+			return 0, fmt.Errorf("error getting highest work queue block: %w", err)
+		}
+		if found && highestJobHeight >= idxr.cfg.Base.StartBlock {
+			return highestJobHeight + 1, nil
+		}
+	}
+
 	// If the start height is set to -1, resume from the highest block already indexed
 	if idxr.cfg.Base.StartBlock == -1 {
 		latestBlock, err := rpc.GetLatestBlockHeight(idxr.cl)
 		if err != nil {
-			log.Fatalf("Error getting blockchain latest height. Err: %v", err)
+			return 0, fmt.Errorf("error getting blockchain latest height: %w", err)
 		}
 
 		fmt.Println("Found latest block", latestBlock)
 		highestIndexedBlock := dbTypes.GetHighestIndexedBlock(idxr.db, chainID)
 		if highestIndexedBlock.Height < latestBlock {
-			return highestIndexedBlock.Height + 1
+			return highestIndexedBlock.Height + 1, nil
 		}
 	}
 
 	// if we are re-indexing, just start at the configured start block
 	if idxr.cfg.Base.ReIndex {
-		return idxr.cfg.Base.StartBlock
+		return idxr.cfg.Base.StartBlock, nil
 	}
 
 	maxStart := idxr.cfg.Base.EndBlock
@@ -307,29 +511,75 @@ func (idxr *Indexer) GetIndexerStartingHeight(chainID uint) int64 {
 // queryRPC will query the RPC endpoint
 // this information will be parsed and converted into the domain objects we use for indexing this data.
 // data is then passed to a channel to be consumed and inserted into the DB
-func (idxr *Indexer) queryRPC(blockChan chan int64, dbDataChan chan *dbData, failedBlockHandler core.FailedBlockHandler) {
+func (idxr *Indexer) queryRPC(ctx context.Context, blockChan chan int64, dbDataChan chan *dbData, failedBlockHandler core.FailedBlockHandler, dbChainID uint) {
 	for blockToProcess := range blockChan {
-		// attempt to process the block 5 times and then give up
-		err := processBlock(idxr.cl, idxr.db, failedBlockHandler, dbDataChan, blockToProcess)
+		// Heights still waiting in the channel are not started once shutting down, they have not been indexed so the
+		// next run picks them up again
+		if ctx.Err() != nil {
+			return
+		}
+
+		height := blockToProcess
+		err := processBlock(idxr.processor, idxr.pool, idxr.db, failedBlockHandler, dbDataChan, height)
 		if err != nil {
-			config.Log.Error(fmt.Sprintf("Failed to process block %v. Will add to failed blocks table", blockToProcess))
-			err := dbTypes.UpsertFailedBlock(idxr.db, blockToProcess, idxr.cfg.Lens.ChainID, idxr.cfg.Lens.ChainName)
+			config.Log.Error(fmt.Sprintf("Failed to process block %v.", height), err)
+			blockErr := core.AsBlockProcessingError(height, core.BlockQueryError, err)
+			err = idxr.supervisor.HandleBlockError(ctx, blockErr,
+				func() error {
+					return processBlock(idxr.processor, idxr.pool, idxr.db, failedBlockHandler, dbDataChan, height)
+				},
+				func(err error) error {
+					return idxr.markBlockFailed(dbChainID, core.AsBlockProcessingError(height, blockErr.Code, err))
+				})
 			if err != nil {
-				config.Log.Fatal(fmt.Sprintf("Failed to store that block %v failed. Not safe to continue.", blockToProcess), err)
+				return
 			}
 		}
 	}
 }
 
-func processBlock(cl *client.ChainClient, dbConn *gorm.DB, failedBlockHandler func(height int64, code core.BlockProcessingFailure, err error), dbDataChan chan *dbData, blockToProcess int64) error {
+// markBlockFailed records the block and the details of the failure in the failed blocks table (and the work queue) so
+// it can be reattempted later
+func (idxr *Indexer) markBlockFailed(dbChainID uint, blockErr *core.BlockProcessingError) error {
+	metrics.FailedBlocks.WithLabelValues(idxr.cfg.Lens.ChainID, blockErr.Code.String()).Inc()
+
+	txHash, messageType := core.TxErrorDetails(blockErr)
+	failure := dbTypes.FailedBlock{
+		Height:      blockErr.Height,
+		Code:        blockErr.Code.String(),
+		Error:       blockErr.Err.Error(),
+		MessageType: messageType,
+		TxHash:      txHash,
+	}
+	err := dbTypes.UpsertFailedBlock(idxr.db, failure, idxr.cfg.Lens.ChainID, idxr.cfg.Lens.ChainName)
+	if err != nil {
+		return err
+	}
+
+	if idxr.cfg.Base.WorkQueue {
+		return dbTypes.FailBlockJob(idxr.db, dbChainID, blockErr.Height, blockErr.Error())
+	}
+
+	return nil
+}
+
+// markBlockEventsFailed records the block in the failed event blocks table so it can be reattempted later
+func (idxr *Indexer) markBlockEventsFailed(blockErr *core.BlockProcessingError) error {
+	metrics.FailedBlocks.WithLabelValues(idxr.cfg.Lens.ChainID, blockErr.Code.String()).Inc()
+
+	return dbTypes.UpsertFailedEventBlock(idxr.db, blockErr.Height, idxr.cfg.Lens.ChainID, idxr.cfg.Lens.ChainName)
+}
+
+func processBlock(processor *core.ChainProcessor, pool *rpc.ClientPool, dbConn *gorm.DB, failedBlockHandler func(height int64, code core.BlockProcessingFailure, err error), dbDataChan chan *dbData, blockToProcess int64) error {
 	// fmt.Printf("Querying RPC transactions for block %d\n", blockToProcess)
 	newBlock := dbTypes.Block{Height: blockToProcess}
 	var txDBWrappers []dbTypes.TxDBWrapper
 	var blockTime *time.Time
+	var block *coretypes.ResultBlock
 	var err error
 	errTypeURL := false
 
-	txsEventResp, unpackError, err := rpc.GetTxsByBlockHeight(cl, newBlock.Height)
+	txsEventResp, unpackError, err := rpc.GetTxsByBlockHeightFromPool(pool, newBlock.Height)
 	if err != nil {
 		if strings.Contains(err.Error(), "unable to resolve type URL") {
 			errTypeURL = true
@@ -348,54 +598,60 @@ func processBlock(cl *client.ChainClient, dbConn *gorm.DB, failedBlockHandler fu
 	// 2) The RPC endpoint (node we queried) doesn't recognize the type URL anymore, for an older type (e.g. on an archive node).
 	if errTypeURL || len(txsEventResp.Txs) == 0 {
 		// The node might have pruned history resulting in a failed lookup. Recheck to see if the block was supposed to have TX results.
-		resBlockResults, err := rpc.GetBlockByHeight(cl, newBlock.Height)
+		resBlockResults, err := rpc.GetBlockByHeightFromPool(pool, newBlock.Height)
 		if err != nil || resBlockResults == nil {
-			if err != nil && strings.Contains(err.Error(), "is not available, lowest height is") {
-				failedBlockHandler(newBlock.Height, core.NodeMissingHistoryForBlock, err)
-			} else {
-				failedBlockHandler(newBlock.Height, core.BlockQueryError, err)
+			code := core.BlockQueryError
+			if err != nil && (strings.Contains(err.Error(), "is not available, lowest height is") || errors.Is(err, rpc.ErrNoPoolNodeForHeight)) {
+				code = core.NodeMissingHistoryForBlock
+			} else if err == nil {
+				err = errors.New("no block results returned")
 			}
-			return err
+			failedBlockHandler(newBlock.Height, code, err)
+			return &core.BlockProcessingError{Height: newBlock.Height, Code: code, Err: err}
 		} else if len(resBlockResults.TxsResults) > 0 {
 			// The tx.height=X query said there were 0 TXs, but GetBlockByHeight() found some. When this happens
 			// it is the same on every RPC node. Thus, we defer to the results from GetBlockByHeight.
 			config.Log.Debugf("Falling back to secondary queries for block height %d", newBlock.Height)
 
-			blockResults, err := rpc.GetBlock(cl, newBlock.Height)
+			block, err = rpc.GetBlockFromPool(pool, newBlock.Height)
 			if err != nil {
 				config.Log.Errorf("Secondary RPC query failed, %d, %s", newBlock.Height, err)
 				return err
 			}
 
-			txDBWrappers, blockTime, err = core.ProcessRPCBlockByHeightTXs(dbConn, cl, blockResults, resBlockResults)
+			txDBWrappers, blockTime, err = processor.ProcessRPCBlockByHeightTXs(dbConn, block, resBlockResults)
 			if err != nil {
 				config.Log.Errorf("Second query parser failed (ProcessRPCBlockByHeightTXs), %d, %s", newBlock.Height, err.Error())
 				return err
 			}
 		}
 	} else {
-		txDBWrappers, blockTime, err = core.ProcessRPCTXs(dbConn, cl, txsEventResp)
+		txDBWrappers, blockTime, err = processor.ProcessRPCTXs(dbConn, txsEventResp)
 		if err != nil {
 			config.Log.Error("ProcessRpcTxs: unhandled error", err)
 			failedBlockHandler(blockToProcess, core.UnprocessableTxError, err)
-			return err
+			return &core.BlockProcessingError{Height: blockToProcess, Code: core.UnprocessableTxError, Err: err}
 		}
 	}
 
-	// Get the block time if we don't have TXs
-	if blockTime == nil {
-		result, err := rpc.GetBlock(cl, newBlock.Height)
+	// Get the block info for the block hashes, and the block time if we don't have TXs
+	if block == nil {
+		block, err = rpc.GetBlockFromPool(pool, newBlock.Height)
 		if err != nil {
 			config.Log.Errorf("Error getting block info for block %v. Err: %v", newBlock.Height, err)
 			return err
 		}
-		blockTime = &result.Block.Time
+	}
+	if blockTime == nil {
+		blockTime = &block.Block.Time
 	}
 
 	res := &dbData{
 		txDBWrappers: txDBWrappers,
 		blockTime:    *blockTime,
 		blockHeight:  blockToProcess,
+		blockHash:    block.BlockID.Hash.String(),
+		appHash:      block.Block.AppHash.String(),
 	}
 	dbDataChan <- res
 
@@ -406,6 +662,8 @@ type dbData struct {
 	txDBWrappers []dbTypes.TxDBWrapper
 	blockTime    time.Time
 	blockHeight  int64
+	blockHash    string
+	appHash      string
 }
 
 type blockEventsDBData struct {
@@ -422,15 +680,30 @@ type epochEventsDBData struct {
 	epochNumber         uint
 }
 
-func (idxr *Indexer) indexBlockEvents(wg *sync.WaitGroup, failedBlockHandler core.FailedBlockHandler, blockEventsDataChan chan *blockEventsDBData) {
+// indexBlockEvents walks the block heights and queries their events on a pool of workers,
+// the events are passed on to the DB in height order so the highest indexed block event is a safe place to resume from
+func (idxr *Indexer) indexBlockEvents(ctx context.Context, wg *sync.WaitGroup, failedBlockHandler core.FailedBlockHandler, blockEventsDataChan chan *blockEventsDBData) {
 	defer close(blockEventsDataChan)
 	defer wg.Done()
 
+	if idxr.isAddressBackfill() {
+		idxr.indexAddressBlockEvents(ctx, failedBlockHandler, blockEventsDataChan)
+		return
+	}
+
 	startHeight := idxr.cfg.Base.BlockEventsStartBlock
 	endHeight := idxr.cfg.Base.BlockEventsEndBlock
 
 	if startHeight <= 0 {
-		dbLastIndexedBlockEvent := GetBlockEventsStartIndexHeight(idxr.db, idxr.cfg.Lens.ChainID)
+		var dbLastIndexedBlockEvent int64
+		err := idxr.supervisor.Retry(ctx, "getting the highest indexed block event", func() error {
+			var err error
+			dbLastIndexedBlockEvent, err = GetBlockEventsStartIndexHeight(idxr.db, idxr.cfg.Lens.ChainID)
+			return err
+		})
+		if err != nil {
+			return
+		}
 		if dbLastIndexedBlockEvent > 0 {
 			startHeight = dbLastIndexedBlockEvent + 1
 		}
@@ -441,60 +714,28 @@ func (idxr *Indexer) indexBlockEvents(wg *sync.WaitGroup, failedBlockHandler cor
 		startHeight = 1
 	}
 
-	lastKnownBlockHeight, errBh := rpc.GetLatestBlockHeight(idxr.cl)
-	if errBh != nil {
-		config.Log.Fatal("Error getting blockchain latest height in block event indexer.", errBh)
+	var lastKnownBlockHeight int64
+	getLatestBlockHeight := func() error {
+		var err error
+		lastKnownBlockHeight, err = rpc.GetLatestBlockHeight(idxr.cl)
+		return err
+	}
+	if err := idxr.supervisor.Retry(ctx, "getting blockchain latest height in block event indexer", getLatestBlockHeight); err != nil {
+		return
 	}
 
-	config.Log.Infof("Indexing block events from block: %v to %v", startHeight, endHeight)
-
-	currentHeight := startHeight
-
-	for endHeight == -1 || currentHeight <= endHeight {
-		bresults, err := rpc.GetBlockResultWithRetry(idxr.cl, currentHeight, idxr.cfg.Base.RequestRetryAttempts, idxr.cfg.Base.RequestRetryMaxWait)
-		if err != nil {
-			config.Log.Error(fmt.Sprintf("Error receiving block result for block %d", currentHeight), err)
-			failedBlockHandler(currentHeight, core.FailedBlockEventHandling, err)
-
-			err := dbTypes.UpsertFailedEventBlock(idxr.db, currentHeight, idxr.cfg.Lens.ChainID, idxr.cfg.Lens.ChainName)
-			if err != nil {
-				config.Log.Fatal("Failed to insert failed block event", err)
-			}
-
-			currentHeight++
-			if idxr.cfg.Base.Throttling != 0 {
-				time.Sleep(time.Second * time.Duration(idxr.cfg.Base.Throttling))
-			}
-			continue
-		}
+	config.Log.Infof("Indexing block events from block: %v to %v with %d workers", startHeight, endHeight, idxr.cfg.Base.EventWorkers)
 
-		blockRelevantEvents, err := core.ProcessRPCBlockEvents(bresults)
+	pool := newOrderedPool(int(idxr.cfg.Base.EventWorkers), func(data *blockEventsDBData) {
+		blockEventsDataChan <- data
+	})
+	defer pool.Close()
 
-		switch {
-		case err != nil:
-			failedBlockHandler(currentHeight, core.FailedBlockEventHandling, err)
-			err := dbTypes.UpsertFailedEventBlock(idxr.db, currentHeight, idxr.cfg.Lens.ChainID, idxr.cfg.Lens.ChainName)
-			if err != nil {
-				config.Log.Fatal("Failed to insert failed block event", err)
-			}
-		case len(blockRelevantEvents) != 0:
-			result, err := rpc.GetBlock(idxr.cl, bresults.Height)
-			if err != nil {
-				failedBlockHandler(currentHeight, core.FailedBlockEventHandling, err)
+	currentHeight := startHeight
 
-				err := dbTypes.UpsertFailedEventBlock(idxr.db, currentHeight, idxr.cfg.Lens.ChainID, idxr.cfg.Lens.ChainName)
-				if err != nil {
-					config.Log.Fatal("Failed to insert failed block event", err)
-				}
-			} else {
-				blockEventsDataChan <- &blockEventsDBData{
-					blockHeight:         bresults.Height,
-					blockTime:           result.Block.Time,
-					blockRelevantEvents: blockRelevantEvents,
-				}
-			}
-		default:
-			config.Log.Infof("Block %d has no relevant block events", bresults.Height)
+	for (endHeight == -1 || currentHeight <= endHeight) && ctx.Err() == nil {
+		if !pool.Submit(ctx, idxr.blockEventsWork(ctx, currentHeight, failedBlockHandler)) {
+			break
 		}
 
 		currentHeight++
@@ -506,27 +747,90 @@ func (idxr *Indexer) indexBlockEvents(wg *sync.WaitGroup, failedBlockHandler cor
 			// whether we are going too fast and need to do multiple sleeps
 			// whether the lastKnownHeight was set a long time ago (as in at app start) and we just need to reset the value
 			for {
-				lastKnownBlockHeight, err = rpc.GetLatestBlockHeight(idxr.cl)
-				if err != nil {
-					config.Log.Fatal("Error getting blockchain latest height in block event indexer.", errBh)
+				if err := idxr.supervisor.Retry(ctx, "getting blockchain latest height in block event indexer", getLatestBlockHeight); err != nil {
+					return
 				}
 
 				if currentHeight > lastKnownBlockHeight {
 					config.Log.Infof("Sleeping...")
-					time.Sleep(time.Second * 20)
+					if !sleepWithContext(ctx, time.Second*20) {
+						break
+					}
 				} else {
 					config.Log.Infof("Continuing until block %d", lastKnownBlockHeight)
-					time.Sleep(time.Second * time.Duration(idxr.cfg.Base.Throttling))
+					sleepWithContext(ctx, time.Second*time.Duration(idxr.cfg.Base.Throttling))
 					break
 				}
 			}
-		} else if idxr.cfg.Base.Throttling != 0 {
-			time.Sleep(time.Second * time.Duration(idxr.cfg.Base.Throttling))
+		} else {
+			sleepWithContext(ctx, time.Second*time.Duration(idxr.cfg.Base.Throttling))
+		}
+	}
+
+	if ctx.Err() != nil {
+		config.Log.Infof("Shutting down, stopped indexing block events before block %d", currentHeight)
+	}
+}
+
+// blockEventsWork processes the block events of the height on a worker, applying the error policy if it fails
+func (idxr *Indexer) blockEventsWork(ctx context.Context, height int64, failedBlockHandler core.FailedBlockHandler) orderedWork[*blockEventsDBData] {
+	return func() (*blockEventsDBData, bool, error) {
+		data, err := idxr.processBlockEvents(height, failedBlockHandler)
+		if err != nil {
+			blockErr := core.AsBlockProcessingError(height, core.FailedBlockEventHandling, err)
+			err = idxr.supervisor.HandleBlockError(ctx, blockErr,
+				func() error {
+					var retryErr error
+					data, retryErr = idxr.processBlockEvents(height, failedBlockHandler)
+					return retryErr
+				},
+				func(err error) error {
+					return idxr.markBlockEventsFailed(core.AsBlockProcessingError(height, blockErr.Code, err))
+				})
+			if err != nil {
+				return nil, false, err
+			}
 		}
+
+		return data, data != nil, nil
 	}
 }
 
-func (idxr *Indexer) indexEpochEvents(wg *sync.WaitGroup, failedBlockHandler core.FailedBlockHandler, epochEventsDataChan chan *epochEventsDBData, chainID uint) {
+// processBlockEvents queries the BeginBlocker and EndBlocker events of the block, the data is nil if none of them are relevant
+func (idxr *Indexer) processBlockEvents(height int64, failedBlockHandler core.FailedBlockHandler) (*blockEventsDBData, error) {
+	bresults, err := rpc.GetBlockResultWithRetry(idxr.cl, height, idxr.cfg.Base.RequestRetryAttempts, idxr.cfg.Base.RequestRetryMaxWait)
+	if err != nil {
+		config.Log.Error(fmt.Sprintf("Error receiving block result for block %d", height), err)
+		failedBlockHandler(height, core.FailedBlockEventHandling, err)
+		return nil, err
+	}
+
+	blockRelevantEvents, err := idxr.processor.ProcessRPCBlockEvents(bresults)
+	if err != nil {
+		failedBlockHandler(height, core.FailedBlockEventHandling, err)
+		return nil, err
+	}
+
+	if len(blockRelevantEvents) == 0 {
+		config.Log.Infof("Block %d has no relevant block events", bresults.Height)
+		return nil, nil
+	}
+
+	result, err := rpc.GetBlock(idxr.cl, bresults.Height)
+	if err != nil {
+		failedBlockHandler(height, core.FailedBlockEventHandling, err)
+		return nil, err
+	}
+
+	return &blockEventsDBData{
+		blockHeight:         bresults.Height,
+		blockTime:           result.Block.Time,
+		blockRelevantEvents: blockRelevantEvents,
+	}, nil
+}
+
+// indexEpochEvents queries the events of the unindexed epochs on a pool of workers, the events are passed on to the DB in epoch order
+func (idxr *Indexer) indexEpochEvents(ctx context.Context, wg *sync.WaitGroup, failedBlockHandler core.FailedBlockHandler, epochEventsDataChan chan *epochEventsDBData, chainID uint) {
 	defer close(epochEventsDataChan)
 	defer wg.Done()
 
@@ -538,23 +842,34 @@ func (idxr *Indexer) indexEpochEvents(wg *sync.WaitGroup, failedBlockHandler cor
 	var chain dbTypes.Chain
 	chain.ChainID = idxr.cl.Config.ChainID
 	chain.Name = idxr.cfg.Lens.ChainName
-	res := idxr.db.FirstOrCreate(&chain)
-
-	if res.Error != nil {
-		config.Log.Fatalf("Error setting up Chain model. Err: %v", res.Error)
+	err := idxr.supervisor.Retry(ctx, "setting up Chain model", func() error {
+		return idxr.db.FirstOrCreate(&chain).Error
+	})
+	if err != nil {
+		return
 	}
 
-	latestHeight, err := rpc.GetLatestBlockHeight(idxr.cl)
+	var latestHeight int64
+	err = idxr.supervisor.Retry(ctx, "getting latest block height", func() error {
+		var err error
+		latestHeight, err = rpc.GetLatestBlockHeight(idxr.cl)
+		return err
+	})
 	if err != nil {
-		config.Log.Fatalf("Error getting latest block height. Err: %v", err)
+		return
 	}
 
 	indexEpochsAtStartingHeight(idxr.db, idxr.cl, latestHeight, chain, epochIdentifier, idxr.cfg.Base.Throttling)
 
 	// Get epochs for identifier between start and end epoch that have not been indexed
-	epochsBetween, err := GetUnindexedEpochsAtIdentifierBetweenStartAndEnd(idxr.db, chainID, epochIdentifier, startEpochNumber, endEpochNumber)
+	var epochsBetween []dbTypes.Epoch
+	err = idxr.supervisor.Retry(ctx, fmt.Sprintf("getting epochs between %d and %d for identifier %s", startEpochNumber, endEpochNumber, epochIdentifier), func() error {
+		var err error
+		epochsBetween, err = GetUnindexedEpochsAtIdentifierBetweenStartAndEnd(idxr.db, chainID, epochIdentifier, startEpochNumber, endEpochNumber)
+		return err
+	})
 	if err != nil {
-		config.Log.Fatalf("Error getting epochs between %d and %d for identifier %s. %s", startEpochNumber, endEpochNumber, epochIdentifier, err)
+		return
 	}
 
 	if len(epochsBetween) == 0 {
@@ -562,66 +877,90 @@ func (idxr *Indexer) indexEpochEvents(wg *sync.WaitGroup, failedBlockHandler cor
 		return
 	}
 
-	config.Log.Infof("Indexing epoch events from epoch: %v to %v", epochsBetween[0].EpochNumber, epochsBetween[len(epochsBetween)-1].EpochNumber)
+	config.Log.Infof("Indexing epoch events from epoch: %v to %v with %d workers", epochsBetween[0].EpochNumber, epochsBetween[len(epochsBetween)-1].EpochNumber, idxr.cfg.Base.EventWorkers)
 
-	for _, epoch := range epochsBetween {
-		config.Log.Infof("Indexing epoch events for epoch %v at height %d", epoch.EpochNumber, epoch.StartHeight)
+	pool := newOrderedPool(int(idxr.cfg.Base.EventWorkers), func(data *epochEventsDBData) {
+		epochEventsDataChan <- data
+	})
+	defer pool.Close()
 
-		bresults, err := rpc.GetBlockResultWithRetry(idxr.cl, int64(epoch.StartHeight), idxr.cfg.Base.RequestRetryAttempts, idxr.cfg.Base.RequestRetryMaxWait)
-		if err != nil {
-			config.Log.Error(fmt.Sprintf("Error receiving block result for block %d", epoch.StartHeight), err)
-			failedBlockHandler(int64(epoch.StartHeight), core.FailedBlockEventHandling, err)
+	for _, epoch := range epochsBetween {
+		if ctx.Err() != nil {
+			config.Log.Infof("Shutting down, stopped indexing epoch events before epoch %d", epoch.EpochNumber)
+			return
+		}
 
-			err := dbTypes.UpsertFailedEventBlock(idxr.db, int64(epoch.StartHeight), idxr.cfg.Lens.ChainID, idxr.cfg.Lens.ChainName)
-			if err != nil {
-				config.Log.Fatal("Failed to insert failed block event", err)
-			}
+		config.Log.Infof("Indexing epoch events for epoch %v at height %d", epoch.EpochNumber, epoch.StartHeight)
 
-			if idxr.cfg.Base.Throttling != 0 {
-				time.Sleep(time.Second * time.Duration(idxr.cfg.Base.Throttling))
-			}
+		if !pool.Submit(ctx, idxr.epochEventsWork(ctx, epoch, epochIdentifier, failedBlockHandler)) {
 			continue
 		}
 
-		blockRelevantEvents, err := core.ProcessRPCEpochEvents(bresults, epochIdentifier)
+		sleepWithContext(ctx, time.Second*time.Duration(idxr.cfg.Base.Throttling))
+	}
+
+	config.Log.Infof("Finished gathering epoch events for epochs %d to %d in identifier %s", startEpochNumber, endEpochNumber, epochIdentifier)
+}
 
+// epochEventsWork processes the events of the epoch on a worker, applying the error policy if it fails
+func (idxr *Indexer) epochEventsWork(ctx context.Context, epoch dbTypes.Epoch, epochIdentifier string, failedBlockHandler core.FailedBlockHandler) orderedWork[*epochEventsDBData] {
+	return func() (*epochEventsDBData, bool, error) {
+		data, err := idxr.processEpochEvents(epoch, epochIdentifier, failedBlockHandler)
 		if err != nil {
-			failedBlockHandler(int64(epoch.StartHeight), core.FailedBlockEventHandling, err)
-			err := dbTypes.UpsertFailedEventBlock(idxr.db, int64(epoch.StartHeight), idxr.cfg.Lens.ChainID, idxr.cfg.Lens.ChainName)
+			height := int64(epoch.StartHeight)
+			blockErr := core.AsBlockProcessingError(height, core.FailedBlockEventHandling, err)
+			err = idxr.supervisor.HandleBlockError(ctx, blockErr,
+				func() error {
+					var retryErr error
+					data, retryErr = idxr.processEpochEvents(epoch, epochIdentifier, failedBlockHandler)
+					return retryErr
+				},
+				func(err error) error {
+					return idxr.markBlockEventsFailed(core.AsBlockProcessingError(height, blockErr.Code, err))
+				})
 			if err != nil {
-				config.Log.Fatal("Failed to insert failed block event", err)
-			}
-		} else {
-			if len(blockRelevantEvents) == 0 {
-				config.Log.Infof("Block %d has no relevant block events", bresults.Height)
+				return nil, false, err
 			}
+		}
 
-			result, err := rpc.GetBlock(idxr.cl, bresults.Height)
-			if err != nil {
-				failedBlockHandler(int64(epoch.StartHeight), core.FailedBlockEventHandling, err)
+		return data, data != nil, nil
+	}
+}
 
-				err := dbTypes.UpsertFailedEventBlock(idxr.db, int64(epoch.StartHeight), idxr.cfg.Lens.ChainID, idxr.cfg.Lens.ChainName)
-				if err != nil {
-					config.Log.Fatal("Failed to insert failed block event", err)
-				}
-			} else {
-				epochEventsDataChan <- &epochEventsDBData{
-					blockHeight:         bresults.Height,
-					blockTime:           result.Block.Time,
-					blockRelevantEvents: blockRelevantEvents,
-					epochIdentifier:     epochIdentifier,
-					epochNumber:         epoch.EpochNumber,
-				}
-			}
-		}
+// processEpochEvents queries the events of the block the epoch started at
+func (idxr *Indexer) processEpochEvents(epoch dbTypes.Epoch, epochIdentifier string, failedBlockHandler core.FailedBlockHandler) (*epochEventsDBData, error) {
+	height := int64(epoch.StartHeight)
 
-		if idxr.cfg.Base.Throttling != 0 {
-			time.Sleep(time.Second * time.Duration(idxr.cfg.Base.Throttling))
-		}
+	bresults, err := rpc.GetBlockResultWithRetry(idxr.cl, height, idxr.cfg.Base.RequestRetryAttempts, idxr.cfg.Base.RequestRetryMaxWait)
+	if err != nil {
+		config.Log.Error(fmt.Sprintf("Error receiving block result for block %d", height), err)
+		failedBlockHandler(height, core.FailedBlockEventHandling, err)
+		return nil, err
+	}
 
+	blockRelevantEvents, err := idxr.processor.ProcessRPCEpochEvents(bresults, epochIdentifier)
+	if err != nil {
+		failedBlockHandler(height, core.FailedBlockEventHandling, err)
+		return nil, err
 	}
 
-	config.Log.Infof("Finished gathering epoch events for epochs %d to %d in identifier %s", startEpochNumber, endEpochNumber, epochIdentifier)
+	if len(blockRelevantEvents) == 0 {
+		config.Log.Infof("Block %d has no relevant block events", bresults.Height)
+	}
+
+	result, err := rpc.GetBlock(idxr.cl, bresults.Height)
+	if err != nil {
+		failedBlockHandler(height, core.FailedBlockEventHandling, err)
+		return nil, err
+	}
+
+	return &epochEventsDBData{
+		blockHeight:         bresults.Height,
+		blockTime:           result.Block.Time,
+		blockRelevantEvents: blockRelevantEvents,
+		epochIdentifier:     epochIdentifier,
+		epochNumber:         epoch.EpochNumber,
+	}, nil
 }
 
 func GetUnindexedEpochsAtIdentifierBetweenStartAndEnd(db *gorm.DB, chainID uint, identifier string, startEpochNumber int64, endEpochNumber int64) ([]dbTypes.Epoch, error) {
@@ -639,10 +978,82 @@ func GetUnindexedEpochsAtIdentifierBetweenStartAndEnd(db *gorm.DB, chainID uint,
 	return epochsBetween, err
 }
 
+// writeBlocks writes the blocks in a single batch. If the batch fails the blocks are written one at a time, so a bad
+// block goes through the error policy without holding back the rest of the batch.
+// It returns the heights that were written and the number of failed writes.
+func (idxr *Indexer) writeBlocks(ctx context.Context, blocks []*dbData, dbChainID uint) ([]int64, int) {
+	batch := make([]dbTypes.BlockDBWrapper, len(blocks))
+	for i, data := range blocks {
+		batch[i] = dbTypes.BlockDBWrapper{Height: data.blockHeight, Time: data.blockTime, BlockHash: data.blockHash, AppHash: data.appHash, Txs: idxr.watchList.FilterTxs(data.txDBWrappers)}
+	}
+
+	var written []int64
+	failedWrites := 0
+
+	writeStart := time.Now()
+	batchErr := dbTypes.IndexNewBlocks(idxr.db, batch, dbChainID)
+	if batchErr == nil {
+		metrics.ObserveDBWrite(idxr.cfg.Lens.ChainID, "block", writeStart)
+		for _, block := range batch {
+			written = append(written, block.Height)
+		}
+	} else {
+		failedWrites++
+		if len(batch) > 1 {
+			config.Log.Error(fmt.Sprintf("Error indexing blocks %d to %d, writing them one at a time.", batch[0].Height, batch[len(batch)-1].Height), batchErr)
+		}
+
+		for _, block := range batch {
+			block := block
+			indexBlock := func() error {
+				return dbTypes.IndexNewBlocks(idxr.db, []dbTypes.BlockDBWrapper{block}, dbChainID)
+			}
+
+			// A batch of one has already been attempted
+			err := batchErr
+			if len(batch) > 1 {
+				writeStart = time.Now()
+				err = indexBlock()
+			}
+			if err != nil {
+				config.Log.Error(fmt.Sprintf("Error indexing block %v.", block.Height), err)
+				if len(batch) > 1 {
+					failedWrites++
+				}
+				markedFailed := false
+				blockErr := &core.BlockProcessingError{Height: block.Height, Code: core.BlockDBWriteError, Err: err}
+				err = idxr.supervisor.HandleBlockError(ctx, blockErr, indexBlock, func(err error) error {
+					markedFailed = true
+					idxr.processor.HandleFailedBlock(block.Height, core.BlockDBWriteError, err)
+					return idxr.markBlockFailed(dbChainID, core.AsBlockProcessingError(block.Height, core.BlockDBWriteError, err))
+				})
+				// Either the indexer is aborting or the block was recorded as failed, it was not written
+				if err != nil || markedFailed {
+					continue
+				}
+			}
+			metrics.ObserveDBWrite(idxr.cfg.Lens.ChainID, "block", writeStart)
+			written = append(written, block.Height)
+		}
+	}
+
+	if idxr.cfg.Base.WorkQueue && len(written) > 0 {
+		err := idxr.supervisor.Retry(ctx, fmt.Sprintf("marking blocks %d to %d done in the work queue", written[0], written[len(written)-1]), func() error {
+			return dbTypes.CompleteBlockJobs(idxr.db, dbChainID, written)
+		})
+		if err != nil {
+			return nil, failedWrites
+		}
+	}
+
+	return written, failedWrites
+}
+
 // doDBUpdates will read the data out of the db data chan that had been processed by the workers
 // if this is a dry run, we will simply empty the channel and track progress
-// otherwise we will index the data in the DB.
+// otherwise we will index the data in the DB, up to db-batch-size blocks per DB transaction.
 // it will also read rewars data and index that.
+// The channels are always drained, even when the indexer is aborting, so the data already queried is not lost.
 func (idxr *Indexer) doDBUpdates(wg *sync.WaitGroup, txDataChan chan *dbData, blockEventsDataChan chan *blockEventsDBData, epochEventsDataChan chan *epochEventsDBData, dbChainID uint) {
 	blocksProcessed := 0
 	dbWrites := 0
@@ -650,6 +1061,43 @@ func (idxr *Indexer) doDBUpdates(wg *sync.WaitGroup, txDataChan chan *dbData, bl
 	timeStart := time.Now()
 	defer wg.Done()
 
+	// DB writes are retried even while shutting down, the data has already been queried
+	ctx := context.Background()
+
+	blockDone := func(height int64) {
+		metrics.BlocksProcessed.WithLabelValues(idxr.cfg.Lens.ChainID).Inc()
+		metrics.SetIndexedHeight(idxr.cfg.Lens.ChainID, height)
+		if !idxr.dryRun && height > idxr.lastCommittedHeight {
+			idxr.lastCommittedHeight = height
+		}
+
+		// Just measuring how many blocks/second we can process
+		if idxr.cfg.Base.BlockTimer > 0 {
+			blocksProcessed++
+			if blocksProcessed%int(idxr.cfg.Base.BlockTimer) == 0 {
+				totalTime := time.Since(timeStart)
+				config.Log.Info(fmt.Sprintf("Processing %d blocks took %f seconds. %d total blocks have been processed.\n", idxr.cfg.Base.BlockTimer, totalTime.Seconds(), blocksProcessed))
+				timeStart = time.Now()
+			}
+			if float64(dbReattempts)/float64(dbWrites) > .1 {
+				idxr.supervisor.Abort(fmt.Errorf("more than 10%% of the last %v DB writes have failed", dbWrites))
+			}
+		}
+	}
+
+	var pendingBlocks []*dbData
+	writePendingBlocks := func() {
+		if len(pendingBlocks) == 0 {
+			return
+		}
+		written, failedWrites := idxr.writeBlocks(ctx, pendingBlocks, dbChainID)
+		dbReattempts += failedWrites
+		for _, height := range written {
+			blockDone(height)
+		}
+		pendingBlocks = nil
+	}
+
 	for {
 		// break out of loop once all channels are fully consumed
 		if txDataChan == nil && blockEventsDataChan == nil && epochEventsDataChan == nil {
@@ -662,37 +1110,24 @@ func (idxr *Indexer) doDBUpdates(wg *sync.WaitGroup, txDataChan chan *dbData, bl
 		case data, ok := <-txDataChan:
 			if !ok {
 				txDataChan = nil
+				// Nothing should be left since a batch is written as soon as the channel is empty
+				writePendingBlocks()
 				continue
 			}
 			dbWrites++
 			// While debugging we'll sometimes want to turn off INSERTS to the DB
 			// Note that this does not turn off certain reads or DB connections.
-			if !idxr.dryRun {
-				config.Log.Info(fmt.Sprintf("Indexing %v TXs from block %d", len(data.txDBWrappers), data.blockHeight))
-				err := dbTypes.IndexNewBlock(idxr.db, data.blockHeight, data.blockTime, data.txDBWrappers, dbChainID)
-				if err != nil {
-					// Do a single reattempt on failure
-					dbReattempts++
-					err = dbTypes.IndexNewBlock(idxr.db, data.blockHeight, data.blockTime, data.txDBWrappers, dbChainID)
-					if err != nil {
-						config.Log.Fatal(fmt.Sprintf("Error indexing block %v.", data.blockHeight), err)
-					}
-				}
-			} else {
+			if idxr.dryRun {
 				config.Log.Info(fmt.Sprintf("Processing block %d (dry run, block data will not be stored in DB).", data.blockHeight))
+				blockDone(data.blockHeight)
+				continue
 			}
 
-			// Just measuring how many blocks/second we can process
-			if idxr.cfg.Base.BlockTimer > 0 {
-				blocksProcessed++
-				if blocksProcessed%int(idxr.cfg.Base.BlockTimer) == 0 {
-					totalTime := time.Since(timeStart)
-					config.Log.Info(fmt.Sprintf("Processing %d blocks took %f seconds. %d total blocks have been processed.\n", idxr.cfg.Base.BlockTimer, totalTime.Seconds(), blocksProcessed))
-					timeStart = time.Now()
-				}
-				if float64(dbReattempts)/float64(dbWrites) > .1 {
-					config.Log.Fatalf("More than 10%% of the last %v DB writes have failed.", dbWrites)
-				}
+			config.Log.Info(fmt.Sprintf("Indexing %v TXs from block %d", len(data.txDBWrappers), data.blockHeight))
+			pendingBlocks = append(pendingBlocks, data)
+			// Write once the batch is full, or right away if no other block is ready so a caught up indexer doesn't lag
+			if len(pendingBlocks) >= int(idxr.cfg.Base.DBBatchSize) || len(txDataChan) == 0 {
+				writePendingBlocks()
 			}
 		case eventData, ok := <-blockEventsDataChan:
 			if !ok {
@@ -703,15 +1138,23 @@ func (idxr *Indexer) doDBUpdates(wg *sync.WaitGroup, txDataChan chan *dbData, bl
 			config.Log.Info(fmt.Sprintf("Indexing %v Block Events from block %d", len(eventData.blockRelevantEvents), eventData.blockHeight))
 			identifierLoggingString := fmt.Sprintf("block %d", eventData.blockHeight)
 
-			err := dbTypes.IndexBlockEvents(idxr.db, idxr.dryRun, eventData.blockHeight, eventData.blockTime, eventData.blockRelevantEvents, idxr.cfg.Lens.ChainID, idxr.cfg.Lens.ChainName, identifierLoggingString)
+			writeStart := time.Now()
+			indexEvents := func() error {
+				return dbTypes.IndexBlockEvents(idxr.db, idxr.denoms, idxr.dryRun, eventData.blockHeight, eventData.blockTime, idxr.watchList.FilterEvents(eventData.blockRelevantEvents), idxr.cfg.Lens.ChainID, idxr.cfg.Lens.ChainName, identifierLoggingString)
+			}
+			err := indexEvents()
 			if err != nil {
-				// Do a single reattempt on failure
+				config.Log.Error(fmt.Sprintf("Error indexing block events for %s.", identifierLoggingString), err)
 				dbReattempts++
-				err = dbTypes.IndexBlockEvents(idxr.db, idxr.dryRun, eventData.blockHeight, eventData.blockTime, eventData.blockRelevantEvents, idxr.cfg.Lens.ChainID, idxr.cfg.Lens.ChainName, identifierLoggingString)
+				blockErr := &core.BlockProcessingError{Height: eventData.blockHeight, Code: core.BlockDBWriteError, Err: err}
+				err = idxr.supervisor.HandleBlockError(ctx, blockErr, indexEvents, func(err error) error {
+					return idxr.markBlockEventsFailed(core.AsBlockProcessingError(eventData.blockHeight, blockErr.Code, err))
+				})
 				if err != nil {
-					config.Log.Fatal(fmt.Sprintf("Error indexing block events for %s.", identifierLoggingString), err)
+					continue
 				}
 			}
+			metrics.ObserveDBWrite(idxr.cfg.Lens.ChainID, "block_events", writeStart)
 		case epochEventData, ok := <-epochEventsDataChan:
 
 			if !ok {
@@ -722,20 +1165,31 @@ func (idxr *Indexer) doDBUpdates(wg *sync.WaitGroup, txDataChan chan *dbData, bl
 			identifierLoggingString := fmt.Sprintf("epoch %d in epoch identifier %s", epochEventData.epochNumber, epochEventData.epochIdentifier)
 			config.Log.Info(fmt.Sprintf("Indexing %v Block Events from block %d for %s", len(epochEventData.blockRelevantEvents), epochEventData.blockHeight, identifierLoggingString))
 
-			err := dbTypes.IndexBlockEvents(idxr.db, idxr.dryRun, epochEventData.blockHeight, epochEventData.blockTime, epochEventData.blockRelevantEvents, idxr.cfg.Lens.ChainID, idxr.cfg.Lens.ChainName, identifierLoggingString)
+			writeStart := time.Now()
+			indexEvents := func() error {
+				return dbTypes.IndexBlockEvents(idxr.db, idxr.denoms, idxr.dryRun, epochEventData.blockHeight, epochEventData.blockTime, idxr.watchList.FilterEvents(epochEventData.blockRelevantEvents), idxr.cfg.Lens.ChainID, idxr.cfg.Lens.ChainName, identifierLoggingString)
+			}
+			err := indexEvents()
 			if err != nil {
-				// Do a single reattempt on failure
+				config.Log.Error(fmt.Sprintf("Error indexing block events for %s.", identifierLoggingString), err)
 				dbReattempts++
-				err = dbTypes.IndexBlockEvents(idxr.db, idxr.dryRun, epochEventData.blockHeight, epochEventData.blockTime, epochEventData.blockRelevantEvents, idxr.cfg.Lens.ChainID, idxr.cfg.Lens.ChainName, identifierLoggingString)
+				blockErr := &core.BlockProcessingError{Height: epochEventData.blockHeight, Code: core.BlockDBWriteError, Err: err}
+				err = idxr.supervisor.HandleBlockError(ctx, blockErr, indexEvents, func(err error) error {
+					return idxr.markBlockEventsFailed(core.AsBlockProcessingError(epochEventData.blockHeight, blockErr.Code, err))
+				})
+				// The epoch is left unindexed so the next run picks it up again
 				if err != nil {
-					config.Log.Fatal(fmt.Sprintf("Error indexing block events for %s.", identifierLoggingString), err)
+					continue
 				}
 			}
 
-			err = dbTypes.UpdateEpochIndexingStatus(idxr.db, idxr.dryRun, epochEventData.epochNumber, epochEventData.epochIdentifier, idxr.cfg.Lens.ChainID, idxr.cfg.Lens.ChainName)
+			err = idxr.supervisor.Retry(ctx, fmt.Sprintf("marking %s indexed", identifierLoggingString), func() error {
+				return dbTypes.UpdateEpochIndexingStatus(idxr.db, idxr.dryRun, epochEventData.epochNumber, epochEventData.epochIdentifier, idxr.cfg.Lens.ChainID, idxr.cfg.Lens.ChainName)
+			})
 			if err != nil {
-				config.Log.Fatal(fmt.Sprintf("Error indexing block events for %s. Could not mark Epoch indexed.", identifierLoggingString), err)
+				continue
 			}
+			metrics.ObserveDBWrite(idxr.cfg.Lens.ChainID, "epoch_events", writeStart)
 		}
 	}
 }
@@ -0,0 +1,225 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/DefiantLabs/cosmos-tax-cli/config"
+	"github.com/DefiantLabs/cosmos-tax-cli/core"
+	"github.com/DefiantLabs/cosmos-tax-cli/rpc"
+	"github.com/DefiantLabs/cosmos-tax-cli/tendermint/events"
+	lensClient "github.com/DefiantLabs/lens/client"
+	"github.com/spf13/cobra"
+)
+
+// Tx events whose attribute is the address for every tx that moves funds to or from it
+var addressTxSearchKeys = []string{
+	"message.sender",
+	"transfer.sender",
+	"transfer.recipient",
+	"coin_spent.spender",
+	"coin_received.receiver",
+}
+
+// BeginBlocker and EndBlocker events whose attribute is the address for every block event the indexer handles for it
+var addressBlockSearchKeys = []string{
+	"transfer.recipient",
+	"coin_received.receiver",
+	events.BlockEventDepositToPool + ".depositor",
+	events.BlockEventSwapTransacted + ".swap_requester",
+	events.BlockEventWithdrawFromPool + ".withdrawer",
+}
+
+func init() {
+	config.SetupLogFlags(&indexer.cfg.Log, indexAddressCmd)
+	config.SetupDatabaseFlags(&indexer.cfg.Database, indexAddressCmd)
+	config.SetupLensFlags(&indexer.cfg.Lens, indexAddressCmd)
+	config.SetupThrottlingFlag(&indexer.cfg.Base.Throttling, indexAddressCmd)
+	config.SetupIndexSpecificFlags(indexer.cfg, indexAddressCmd)
+	config.SetupIndexAddressFlags(indexer.cfg, indexAddressCmd)
+
+	rootCmd.AddCommand(indexAddressCmd)
+}
+
+var indexAddressCmd = &cobra.Command{
+	Use:   "index-address",
+	Short: "Indexes only the blocks that touch the given addresses.",
+	Long: `Uses the tx and block search of the RPC node to find every block between base.start-block and base.end-block
+	with a tx or a block event touching one of base.addresses, and indexes just those blocks and their block events.
+	This serves a new address without indexing the whole chain, the node must index the searched events (e.g.
+	message.sender, transfer.recipient, coin_received.receiver). Blocks that are already indexed are skipped unless
+	base.reindex is set or the indexer runs in watch mode, then the addresses are added to the watch list and their
+	blocks are always indexed again.
+	Block events are only indexed for the found blocks, so a later index run with base.index-block-events should set
+	base.block-events-start-block rather than resume from the highest indexed block event.`,
+	PreRunE: setupIndexAddress,
+	Run:     index,
+}
+
+func setupIndexAddress(cmd *cobra.Command, args []string) error {
+	bindFlags(cmd, viperConf)
+
+	cfg := indexer.cfg
+	if viperConf.IsSet("chains") {
+		return errors.New("index-address indexes a single chain, remove the chains tables from the config")
+	}
+	if len(cfg.Base.Addresses) == 0 {
+		return errors.New("base.addresses must contain at least one address")
+	}
+	for _, address := range cfg.Base.Addresses {
+		if strings.Contains(address, " ") || strings.Contains(address, "'") {
+			return fmt.Errorf("invalid address '%v'", address)
+		}
+	}
+
+	// The blocks to index come from the address search, the other enqueue methods and the epoch indexer don't apply
+	if cfg.Base.StartBlock < 1 {
+		cfg.Base.StartBlock = 1
+	}
+	cfg.Base.ChainIndexingEnabled = true
+	cfg.Base.BlockInputFile = ""
+	cfg.Base.ReindexMessageType = ""
+	cfg.Base.WorkQueue = false
+	cfg.Base.BlockEventIndexingEnabled = true
+	cfg.Base.BlockEventsStartBlock = cfg.Base.StartBlock
+	cfg.Base.BlockEventsEndBlock = cfg.Base.EndBlock
+	cfg.Base.EpochEventIndexingEnabled = false
+
+	// Added here so the indexer loads them into the watch list at startup
+	if cfg.Base.WatchMode {
+		cfg.Base.WatchedAddresses = append(cfg.Base.WatchedAddresses, cfg.Base.Addresses...)
+	}
+
+	indexer.addresses = cfg.Base.Addresses
+
+	return setupIndex(cmd, args)
+}
+
+// searchAddressHeights runs the search for every key and address between the configured start and end block and
+// returns the distinct heights found. The height key is the search's height attribute, tx.height or block.height.
+func (idxr *Indexer) searchAddressHeights(ctx context.Context, keys []string, heightKey string, search func(cl *lensClient.ChainClient, query string) ([]int64, error)) ([]int64, error) {
+	endHeight := idxr.cfg.Base.EndBlock
+	if endHeight == -1 {
+		err := idxr.supervisor.Retry(ctx, "getting blockchain latest height", func() error {
+			var err error
+			endHeight, err = rpc.GetLatestBlockHeight(idxr.cl)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return searchHeights(addressSearchQueries(idxr.addresses, keys, heightKey, idxr.cfg.Base.StartBlock, endHeight), func(query string) ([]int64, error) {
+		var heights []int64
+		err := idxr.supervisor.Retry(ctx, fmt.Sprintf("searching %s", query), func() error {
+			var err error
+			heights, err = search(idxr.cl, query)
+			return err
+		})
+		return heights, err
+	})
+}
+
+// addressSearchQueries returns the search query of every key and address between the start and end height
+func addressSearchQueries(addresses []string, keys []string, heightKey string, startHeight int64, endHeight int64) []string {
+	var queries []string
+	for _, address := range addresses {
+		for _, key := range keys {
+			queries = append(queries, fmt.Sprintf("%s='%s' AND %s>=%d AND %s<=%d", key, address, heightKey, startHeight, heightKey, endHeight))
+		}
+	}
+	return queries
+}
+
+// searchHeights runs every query and returns the distinct heights found, in ascending order
+func searchHeights(queries []string, search func(query string) ([]int64, error)) ([]int64, error) {
+	found := make(map[int64]struct{})
+	for _, query := range queries {
+		heights, err := search(query)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, height := range heights {
+			found[height] = struct{}{}
+		}
+	}
+
+	heights := make([]int64, 0, len(found))
+	for height := range found {
+		heights = append(heights, height)
+	}
+	sort.Slice(heights, func(i, j int) bool { return heights[i] < heights[j] })
+	return heights, nil
+}
+
+// enqueueBlocksForAddresses sends the blocks with a tx touching one of the addresses to the RPC workers
+func (idxr *Indexer) enqueueBlocksForAddresses(ctx context.Context, blockChan chan int64, chainID uint) {
+	heights, err := idxr.searchAddressHeights(ctx, addressTxSearchKeys, "tx.height", rpc.SearchTxHeights)
+	if err != nil {
+		return
+	}
+	config.Log.Infof("Found %d blocks with txs touching %v", len(heights), idxr.addresses)
+
+	// In watch mode an indexed block may not have the txs of the addresses, they were not watched when it was indexed
+	skipIndexed := !idxr.cfg.Base.ReIndex && !idxr.cfg.Base.WatchMode
+
+	for _, height := range heights {
+		if skipIndexed {
+			var indexed bool
+			err := idxr.supervisor.Retry(ctx, fmt.Sprintf("checking if block %d is indexed", height), func() error {
+				var err error
+				indexed, err = blockAlreadyIndexed(height, chainID, idxr.db)
+				return err
+			})
+			if err != nil {
+				return
+			}
+			if indexed {
+				config.Log.Debugf("Block %d is already indexed, skipping", height)
+				continue
+			}
+		}
+
+		if !sleepWithContext(ctx, time.Second*time.Duration(idxr.cfg.Base.Throttling)) {
+			return
+		}
+		config.Log.Debugf("Sending block %v to be indexed.", height)
+		if !sendBlock(ctx, blockChan, height) {
+			return
+		}
+	}
+}
+
+// indexAddressBlockEvents indexes the block events of the blocks with a BeginBlocker or EndBlocker event touching one of the addresses
+func (idxr *Indexer) indexAddressBlockEvents(ctx context.Context, failedBlockHandler core.FailedBlockHandler, blockEventsDataChan chan *blockEventsDBData) {
+	heights, err := idxr.searchAddressHeights(ctx, addressBlockSearchKeys, "block.height", rpc.SearchBlockHeights)
+	if err != nil {
+		return
+	}
+	config.Log.Infof("Found %d blocks with block events touching %v, indexing them with %d workers", len(heights), idxr.addresses, idxr.cfg.Base.EventWorkers)
+
+	pool := newOrderedPool(int(idxr.cfg.Base.EventWorkers), func(data *blockEventsDBData) {
+		blockEventsDataChan <- data
+	})
+	defer pool.Close()
+
+	for _, height := range heights {
+		if !pool.Submit(ctx, idxr.blockEventsWork(ctx, height, failedBlockHandler)) {
+			return
+		}
+		if !sleepWithContext(ctx, time.Second*time.Duration(idxr.cfg.Base.Throttling)) {
+			return
+		}
+	}
+}
+
+// isAddressBackfill is true when running index-address, only the blocks of the addresses are indexed
+func (idxr *Indexer) isAddressBackfill() bool {
+	return len(idxr.addresses) > 0
+}
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"context"
+	"sync"
+)
+
+// orderedWork is a unit of work submitted to an orderedPool.
+// emit is false when there is nothing to pass on for the work (e.g. a block without relevant events),
+// a non nil error means the work was not done (e.g. the indexer is shutting down) and nothing after it is emitted.
+type orderedWork[T any] func() (value T, emit bool, err error)
+
+type orderedResult[T any] struct {
+	seq   int64
+	value T
+	emit  bool
+	err   error
+}
+
+// orderedPool runs work concurrently on a number of workers and emits the results in the order the work was submitted.
+// At most window pieces of work are in flight or waiting to be emitted, so a slow piece of work can't make the
+// results buffer grow without bound.
+type orderedPool[T any] struct {
+	jobs    chan orderedJob[T]
+	results chan orderedResult[T]
+	window  chan struct{}
+	nextSeq int64
+
+	workersDone sync.WaitGroup
+	emitDone    chan struct{}
+}
+
+type orderedJob[T any] struct {
+	seq  int64
+	work orderedWork[T]
+}
+
+// newOrderedPool starts the workers, emit is called from a single goroutine in submission order
+func newOrderedPool[T any](workers int, emit func(T)) *orderedPool[T] {
+	window := 4 * workers
+	pool := &orderedPool[T]{
+		jobs:     make(chan orderedJob[T], window),
+		results:  make(chan orderedResult[T], window),
+		window:   make(chan struct{}, window),
+		emitDone: make(chan struct{}),
+	}
+
+	for i := 0; i < workers; i++ {
+		pool.workersDone.Add(1)
+		go func() {
+			defer pool.workersDone.Done()
+			for job := range pool.jobs {
+				value, emit, err := job.work()
+				pool.results <- orderedResult[T]{seq: job.seq, value: value, emit: emit, err: err}
+			}
+		}()
+	}
+
+	go pool.emitInOrder(emit)
+
+	return pool
+}
+
+func (p *orderedPool[T]) emitInOrder(emit func(T)) {
+	defer close(p.emitDone)
+
+	pending := make(map[int64]orderedResult[T])
+	var next int64
+	stopped := false
+	for result := range p.results {
+		pending[result.seq] = result
+		for {
+			result, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			next++
+			<-p.window
+
+			// Emitting past unfinished work would leave a gap behind the data already written
+			if result.err != nil {
+				stopped = true
+			}
+			if !stopped && result.emit {
+				emit(result.value)
+			}
+		}
+	}
+}
+
+// Submit queues the work, blocking while the window is full. It returns false if the context is canceled first.
+// Submit must not be called concurrently.
+func (p *orderedPool[T]) Submit(ctx context.Context, work orderedWork[T]) bool {
+	select {
+	case p.window <- struct{}{}:
+	case <-ctx.Done():
+		return false
+	}
+
+	p.jobs <- orderedJob[T]{seq: p.nextSeq, work: work}
+	p.nextSeq++
+	return true
+}
+
+// Close waits for the submitted work to finish and be emitted
+func (p *orderedPool[T]) Close() {
+	close(p.jobs)
+	p.workersDone.Wait()
+	close(p.results)
+	<-p.emitDone
+}
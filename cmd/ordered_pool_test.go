@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderedPoolEmitsInSubmitOrder(t *testing.T) {
+	var emitted []int
+	pool := newOrderedPool(4, func(value int) { emitted = append(emitted, value) })
+
+	var expected []int
+	for i := 0; i < 100; i++ {
+		value := i
+		assert.True(t, pool.Submit(context.Background(), func() (int, bool, error) {
+			time.Sleep(time.Duration(rand.Intn(500)) * time.Microsecond)
+			// Odd values have nothing to emit
+			return value, value%2 == 0, nil
+		}))
+		if value%2 == 0 {
+			expected = append(expected, value)
+		}
+	}
+	pool.Close()
+
+	assert.Equal(t, expected, emitted)
+}
+
+func TestOrderedPoolStopsEmittingAfterError(t *testing.T) {
+	var emitted []int
+	pool := newOrderedPool(2, func(value int) { emitted = append(emitted, value) })
+
+	for i := 0; i < 6; i++ {
+		value := i
+		pool.Submit(context.Background(), func() (int, bool, error) {
+			if value == 3 {
+				return 0, false, errors.New("shutting down")
+			}
+			return value, true, nil
+		})
+	}
+	pool.Close()
+
+	assert.Equal(t, []int{0, 1, 2}, emitted)
+}
+
+func TestOrderedPoolSubmitAfterCancel(t *testing.T) {
+	pool := newOrderedPool(1, func(int) {})
+
+	// Fill the window with work that waits until it is released
+	release := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		assert.True(t, pool.Submit(context.Background(), func() (int, bool, error) {
+			<-release
+			return 0, true, nil
+		}))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.False(t, pool.Submit(ctx, func() (int, bool, error) { return 0, true, nil }))
+
+	close(release)
+	pool.Close()
+}
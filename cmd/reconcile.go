@@ -0,0 +1,240 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/DefiantLabs/cosmos-tax-cli/config"
+	dbTypes "github.com/DefiantLabs/cosmos-tax-cli/db"
+	"github.com/DefiantLabs/cosmos-tax-cli/rpc"
+	sdkTypes "github.com/cosmos/cosmos-sdk/types"
+	"github.com/shopspring/decimal"
+	"github.com/spf13/cobra"
+	"gorm.io/gorm"
+)
+
+var (
+	reconcileConfig       config.ReconcileConfig
+	reconcileDbConnection *gorm.DB
+)
+
+func init() {
+	config.SetupLogFlags(&reconcileConfig.Log, reconcileCmd)
+	config.SetupDatabaseFlags(&reconcileConfig.Database, reconcileCmd)
+	config.SetupLensFlags(&reconcileConfig.Lens, reconcileCmd)
+	config.SetupReconcileSpecificFlags(&reconcileConfig, reconcileCmd)
+	rootCmd.AddCommand(reconcileCmd)
+}
+
+var reconcileCmd = &cobra.Command{
+	Use:   "reconcile",
+	Short: "Compare the net position of addresses in the DB to their on-chain balances.",
+	Long: `Sums the taxable txs, taxable events and fees stored for each address up to the height into a net position per denom,
+	and compares it to the bank balance plus delegations and unbonding delegations queried from the chain at that height.
+	IBC denoms are compared by the base denom of their trace, the same denom the indexer stores their amounts under.
+	Any drift points to a message or event the indexer did not parse correctly. Unclaimed staking rewards and coins held
+	by other modules for the address (e.g. locked LP shares) are not queried and show up as drift.
+	Exits with a non zero exit code if any denom has drift.`,
+	PreRunE: setupReconcile,
+	Run:     reconcile,
+}
+
+// denomReconciliation is the position of an address in a denom according to the DB and according to the chain
+type denomReconciliation struct {
+	Denom     string          `json:"denom"`
+	Indexed   decimal.Decimal `json:"indexed"`
+	Bank      decimal.Decimal `json:"bank"`
+	Delegated decimal.Decimal `json:"delegated"`
+	Unbonding decimal.Decimal `json:"unbonding"`
+	Drift     decimal.Decimal `json:"drift"` // indexed minus the on-chain total
+}
+
+type addressReconciliation struct {
+	Address string                `json:"address"`
+	Height  int64                 `json:"height"`
+	Denoms  []denomReconciliation `json:"denoms"`
+}
+
+func setupReconcile(cmd *cobra.Command, args []string) error {
+	bindFlags(cmd, viperConf)
+
+	err := reconcileConfig.Validate()
+	if err != nil {
+		return err
+	}
+
+	ignoredKeys := config.CheckSuperfluousReconcileKeys(viperConf.AllKeys())
+
+	if len(ignoredKeys) > 0 {
+		config.Log.Warnf("Warning, the following invalid keys will be ignored: %v", ignoredKeys)
+	}
+
+	setupLogger(reconcileConfig.Log.Level, reconcileConfig.Log.Path, reconcileConfig.Log.Pretty)
+
+	db, err := connectToDBAndMigrate(reconcileConfig.Database)
+	if err != nil {
+		config.Log.Fatal("Could not establish connection to the database", err)
+	}
+
+	reconcileDbConnection = db
+
+	return nil
+}
+
+func reconcile(cmd *cobra.Command, args []string) {
+	cfg := reconcileConfig
+	db := reconcileDbConnection
+
+	dbConn, err := db.DB()
+	if err != nil {
+		config.Log.Fatal("Failed to connect to DB", err)
+	}
+	defer dbConn.Close()
+
+	pool := rpc.NewClientPool(config.GetLensClients(cfg.Lens))
+
+	dbChainID, err := dbTypes.GetDBChainID(db, dbTypes.Chain{ChainID: cfg.Lens.ChainID, Name: cfg.Lens.ChainName})
+	if err != nil {
+		config.Log.Fatal("Failed to add/create chain in DB", err)
+	}
+
+	highestIndexed := dbTypes.GetHighestIndexedBlock(db, dbChainID).Height
+	height := cfg.Base.Height
+	if height == -1 {
+		height = highestIndexed
+	}
+	if height < 1 {
+		config.Log.Fatal("Nothing to reconcile, no blocks are indexed for the chain")
+	}
+	if height > highestIndexed {
+		config.Log.Warnf("Height %d is above the highest indexed block %d, txs after that block are missing from the DB", height, highestIndexed)
+	}
+
+	// Positions are only complete if every block up to the height was indexed
+	failedHeights, err := dbTypes.GetFailedBlockHeightsInRange(db, dbChainID, 1, height)
+	if err != nil {
+		config.Log.Fatal("Error getting failed blocks", err)
+	}
+	if len(failedHeights) > 0 {
+		config.Log.Warnf("%d blocks up to height %d failed to index, the DB positions may be incomplete", len(failedHeights), height)
+	}
+
+	denoms := dbTypes.NewDenomCache(db)
+
+	var results []addressReconciliation
+	hasDrift := false
+	for _, address := range cfg.Base.Addresses {
+		result, err := reconcileAddress(db, pool, denoms, dbChainID, address, height)
+		if err != nil {
+			config.Log.Fatalf("Error reconciling address %s. Err: %v", address, err)
+		}
+		results = append(results, result)
+
+		drifted := 0
+		for _, denom := range result.Denoms {
+			if denom.Drift.IsZero() {
+				config.Log.Infof("%s %s: indexed %s, on chain %s", address, denom.Denom, denom.Indexed, denom.Bank.Add(denom.Delegated).Add(denom.Unbonding))
+				continue
+			}
+			drifted++
+			config.Log.Warnf("%s %s: indexed %s, bank %s, delegated %s, unbonding %s, drift %s",
+				address, denom.Denom, denom.Indexed, denom.Bank, denom.Delegated, denom.Unbonding, denom.Drift)
+		}
+
+		if drifted > 0 {
+			hasDrift = true
+			config.Log.Warnf("%s: %d of %d denoms have drift at height %d", address, drifted, len(result.Denoms), height)
+		} else {
+			config.Log.Infof("%s: all %d denoms match the chain at height %d", address, len(result.Denoms), height)
+		}
+	}
+
+	if cfg.Base.OutputFile != "" {
+		resultsJSON, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			config.Log.Fatal("Error encoding the reconciliation", err)
+		}
+		err = os.WriteFile(cfg.Base.OutputFile, resultsJSON, 0o600)
+		if err != nil {
+			config.Log.Fatal("Error writing the output file", err)
+		}
+		config.Log.Infof("Wrote the reconciliation to %s", cfg.Base.OutputFile)
+	}
+
+	if hasDrift {
+		config.Log.Error("Reconciliation failed, the DB positions do not match the chain")
+		os.Exit(1)
+	}
+}
+
+// reconcileAddress compares the net position of the address in every denom it holds or ever held to the chain
+func reconcileAddress(db *gorm.DB, pool *rpc.ClientPool, denoms *dbTypes.DenomCache, chainID uint, address string, height int64) (addressReconciliation, error) {
+	result := addressReconciliation{Address: address, Height: height}
+
+	positions, err := dbTypes.GetNetPositions(db, chainID, address, height)
+	if err != nil {
+		return result, err
+	}
+
+	holdings, err := rpc.GetAccountHoldingsAtHeightFromPool(pool, address, height)
+	if err != nil {
+		return result, err
+	}
+
+	result.Denoms = reconcileHoldings(positions, holdings, denoms.GetIBCDenom)
+
+	return result, nil
+}
+
+// reconcileHoldings compares the DB positions to the on-chain holdings, keyed by the denom the indexer stores the
+// coins under so IBC denoms are compared to the position in the base denom of their trace
+func reconcileHoldings(positions map[string]decimal.Decimal, holdings *rpc.AccountHoldings, getIBCDenom func(denomTrace string) (dbTypes.IBCDenom, error)) []denomReconciliation {
+	denoms := make(map[string]*denomReconciliation)
+	getDenom := func(denom string) *denomReconciliation {
+		if _, ok := denoms[denom]; !ok {
+			denoms[denom] = &denomReconciliation{Denom: denom}
+		}
+		return denoms[denom]
+	}
+
+	for denom, amount := range positions {
+		getDenom(denom).Indexed = amount
+	}
+	addCoins := func(coins sdkTypes.Coins, field func(*denomReconciliation) *decimal.Decimal) {
+		for _, coin := range coins {
+			amount := field(getDenom(indexedDenom(coin.Denom, getIBCDenom)))
+			*amount = amount.Add(decimal.NewFromBigInt(coin.Amount.BigInt(), 0))
+		}
+	}
+	addCoins(holdings.Bank, func(d *denomReconciliation) *decimal.Decimal { return &d.Bank })
+	addCoins(holdings.Delegated, func(d *denomReconciliation) *decimal.Decimal { return &d.Delegated })
+	addCoins(holdings.Unbonding, func(d *denomReconciliation) *decimal.Decimal { return &d.Unbonding })
+
+	reconciliations := make([]denomReconciliation, 0, len(denoms))
+	for _, denom := range denoms {
+		denom.Drift = denom.Indexed.Sub(denom.Bank).Sub(denom.Delegated).Sub(denom.Unbonding)
+		reconciliations = append(reconciliations, *denom)
+	}
+	sort.Slice(reconciliations, func(i, j int) bool {
+		return reconciliations[i].Denom < reconciliations[j].Denom
+	})
+
+	return reconciliations
+}
+
+// indexedDenom is the denom the indexer stores amounts of the coin denom under. Like the indexer, an IBC denom goes
+// by the base denom of its trace and falls back to the IBC denom itself when the trace is unknown.
+func indexedDenom(denom string, getIBCDenom func(denomTrace string) (dbTypes.IBCDenom, error)) string {
+	if !strings.HasPrefix(denom, "ibc/") {
+		return denom
+	}
+
+	ibcDenom, err := getIBCDenom(denom)
+	if err != nil {
+		config.Log.Warnf("IBC Denom lookup failed for %s, comparing it as is, err: %v", denom, err)
+		return denom
+	}
+	return ibcDenom.BaseDenom
+}
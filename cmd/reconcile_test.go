@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"fmt"
+	"testing"
+
+	dbTypes "github.com/DefiantLabs/cosmos-tax-cli/db"
+	"github.com/DefiantLabs/cosmos-tax-cli/rpc"
+	sdkTypes "github.com/cosmos/cosmos-sdk/types"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+const testAtomIBCDenom = "ibc/27394FB092D2ECCD56123C74F36E4C1F926001CEADA9CA97EA622B25F41E5EB2"
+
+func testGetIBCDenom(denomTrace string) (dbTypes.IBCDenom, error) {
+	if denomTrace == testAtomIBCDenom {
+		return dbTypes.IBCDenom{Hash: testAtomIBCDenom, Path: "transfer/channel-0", BaseDenom: "uatom"}, nil
+	}
+	return dbTypes.IBCDenom{}, fmt.Errorf("no IBC denom found for the specified denom trace %s", denomTrace)
+}
+
+func TestReconcileHoldingsComparesIBCDenomsByBaseDenom(t *testing.T) {
+	const unknownIBCDenom = "ibc/0000000000000000000000000000000000000000000000000000000000000000"
+	positions := map[string]decimal.Decimal{
+		"uosmo":         decimal.NewFromInt(100),
+		"uatom":         decimal.NewFromInt(40),
+		unknownIBCDenom: decimal.NewFromInt(5),
+	}
+	holdings := &rpc.AccountHoldings{
+		Bank: sdkTypes.NewCoins(
+			sdkTypes.NewInt64Coin("uosmo", 60),
+			sdkTypes.NewInt64Coin(testAtomIBCDenom, 40),
+			sdkTypes.NewInt64Coin(unknownIBCDenom, 5),
+		),
+		Delegated: sdkTypes.NewCoins(sdkTypes.NewInt64Coin("uosmo", 40)),
+	}
+
+	reconciliations := reconcileHoldings(positions, holdings, testGetIBCDenom)
+
+	assert.Len(t, reconciliations, 3)
+	for _, reconciliation := range reconciliations {
+		assert.True(t, reconciliation.Drift.IsZero(), "%s has drift %s", reconciliation.Denom, reconciliation.Drift)
+	}
+	assert.Equal(t, "uatom", reconciliations[1].Denom)
+	assert.True(t, reconciliations[1].Bank.Equal(decimal.NewFromInt(40)))
+	assert.Equal(t, unknownIBCDenom, reconciliations[0].Denom)
+}
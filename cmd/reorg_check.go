@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/DefiantLabs/cosmos-tax-cli/config"
+	dbTypes "github.com/DefiantLabs/cosmos-tax-cli/db"
+	"github.com/DefiantLabs/cosmos-tax-cli/rpc"
+)
+
+// How often the most recently indexed blocks are checked against the node while following the chain tip
+const reorgCheckInterval = 30 * time.Second
+
+// reorgChecksEnabled is true when the indexer follows the chain tip and recent blocks should be verified
+func (idxr *Indexer) reorgChecksEnabled() bool {
+	return idxr.cfg.Base.EndBlock == -1 && idxr.cfg.Base.ReorgCheckDepth > 0 && !idxr.dryRun
+}
+
+// checkRecentBlocks compares the hashes of the most recently indexed blocks to the ones the node has now.
+// A block with a different hash was indexed from a fork (or from a node that returned bad data), its txs are deleted
+// and its height is returned so it can be indexed again.
+func (idxr *Indexer) checkRecentBlocks(ctx context.Context, chainID uint) ([]int64, error) {
+	var blocks []dbTypes.Block
+	err := idxr.supervisor.Retry(ctx, "getting recently indexed blocks", func() error {
+		var err error
+		blocks, err = dbTypes.GetRecentIndexedBlocks(idxr.db, chainID, idxr.cfg.Base.ReorgCheckDepth)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var reindex []int64
+	for _, block := range blocks {
+		result, err := rpc.GetBlockFromPool(idxr.pool, block.Height)
+		if err != nil {
+			// The next check will cover this block again
+			config.Log.Warnf("Error getting block %d to check its hash, skipping the rest of this check. Err: %v", block.Height, err)
+			break
+		}
+
+		nodeHash := result.BlockID.Hash.String()
+		if nodeHash == block.BlockHash {
+			continue
+		}
+
+		config.Log.Warnf("Block %d was indexed with hash %s but the node now has hash %s, it will be reindexed", block.Height, block.BlockHash, nodeHash)
+		blockID := block.ID
+		err = idxr.supervisor.Retry(ctx, fmt.Sprintf("resetting block %d for reindexing", block.Height), func() error {
+			return dbTypes.ResetBlock(idxr.db, blockID)
+		})
+		if err != nil {
+			return nil, err
+		}
+		reindex = append(reindex, block.Height)
+	}
+
+	return reindex, nil
+}
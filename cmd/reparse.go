@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/DefiantLabs/cosmos-tax-cli/config"
+	"github.com/DefiantLabs/cosmos-tax-cli/core"
+	dbTypes "github.com/DefiantLabs/cosmos-tax-cli/db"
+	"github.com/spf13/cobra"
+	"gorm.io/gorm"
+)
+
+var (
+	reparseConfig       config.ReparseConfig
+	reparseDbConnection *gorm.DB
+)
+
+func init() {
+	config.SetupLogFlags(&reparseConfig.Log, reparseCmd)
+	config.SetupDatabaseFlags(&reparseConfig.Database, reparseCmd)
+	config.SetupLensFlags(&reparseConfig.Lens, reparseCmd)
+	config.SetupReparseSpecificFlags(&reparseConfig, reparseCmd)
+	rootCmd.AddCommand(reparseCmd)
+}
+
+var reparseCmd = &cobra.Command{
+	Use:   "reparse",
+	Short: "Parse stored raw txs again with the current message parsers and report or apply the changed taxable txs.",
+	Long: `Replays the raw txs stored by the indexer (see base.store-raw-txs) through the current message parsers and
+	compares the resulting taxable txs to the ones in the database. The differences are reported, and written to the
+	database when base.apply is set. No node is queried, only txs with stored raw data can be reparsed.`,
+	PreRunE: setupReparse,
+	Run:     reparse,
+}
+
+func setupReparse(cmd *cobra.Command, args []string) error {
+	bindFlags(cmd, viperConf)
+
+	err := reparseConfig.Validate()
+	if err != nil {
+		return err
+	}
+
+	ignoredKeys := config.CheckSuperfluousReparseKeys(viperConf.AllKeys())
+
+	if len(ignoredKeys) > 0 {
+		config.Log.Warnf("Warning, the following invalid keys will be ignored: %v", ignoredKeys)
+	}
+
+	setupLogger(reparseConfig.Log.Level, reparseConfig.Log.Path, reparseConfig.Log.Pretty)
+
+	db, err := connectToDBAndMigrate(reparseConfig.Database)
+	if err != nil {
+		config.Log.Fatal("Could not establish connection to the database", err)
+	}
+
+	reparseDbConnection = db
+
+	return nil
+}
+
+func reparse(cmd *cobra.Command, args []string) {
+	cfg := reparseConfig
+	db := reparseDbConnection
+
+	dbConn, err := db.DB()
+	if err != nil {
+		config.Log.Fatal("Failed to connect to DB", err)
+	}
+	defer dbConn.Close()
+
+	// Setup chain specific stuff, the same way the indexer does
+	cl := config.GetLensClient(cfg.Lens)
+	processor, err := core.NewChainProcessor(cl, core.NewChainRegistry(cfg.Lens.ChainID), core.ChainProcessorOptions{
+		AccountPrefix: cfg.Lens.AccountPrefix,
+		// Txs stored with unparsed messages must not fail just because some of their messages still have no parser
+		LenientUnknownMessages: true,
+		BankEventFallback:      cfg.Base.BankEventFallback,
+		// Have to cache denoms to get translations from e.g. ujuno to Juno
+		Denoms: dbTypes.NewDenomCache(db),
+	})
+	if err != nil {
+		config.Log.Fatal("Error setting up the chain processor", err)
+	}
+
+	dbChainID, err := dbTypes.GetDBChainID(db, dbTypes.Chain{ChainID: cfg.Lens.ChainID, Name: cfg.Lens.ChainName})
+	if err != nil {
+		config.Log.Fatal("Failed to add/create chain in DB", err)
+	}
+
+	if cfg.Base.Apply {
+		config.Log.Infof("Reparsing stored txs and applying changes, starting at block %d", cfg.Base.StartBlock)
+	} else {
+		config.Log.Infof("Reparsing stored txs and reporting changes only, starting at block %d", cfg.Base.StartBlock)
+	}
+
+	var reparsed, changed, failed int
+	var lastID uint
+	for {
+		rawTxs, err := dbTypes.GetStoredRawTxs(db, dbChainID, cfg.Base.StartBlock, cfg.Base.EndBlock, cfg.Base.MessageType, lastID, cfg.Base.BatchSize)
+		if err != nil {
+			config.Log.Fatal("Error getting stored raw txs", err)
+		}
+		if len(rawTxs) == 0 {
+			break
+		}
+		lastID = rawTxs[len(rawTxs)-1].ID
+
+		txIDs := make([]uint, len(rawTxs))
+		for i, rawTx := range rawTxs {
+			txIDs[i] = rawTx.TxID
+		}
+
+		storedFingerprints, err := dbTypes.GetTaxableTxFingerprints(db, txIDs)
+		if err != nil {
+			config.Log.Fatal("Error getting stored taxable txs", err)
+		}
+		storedUnparsedCounts, err := dbTypes.GetUnparsedMessageCounts(db, txIDs)
+		if err != nil {
+			config.Log.Fatal("Error getting stored unparsed messages", err)
+		}
+
+		// Changed txs are written grouped by block so their block rows keep the stored time
+		var changedTxIDs []uint
+		var blocks []dbTypes.BlockDBWrapper
+		blockIndexes := make(map[int64]int)
+
+		for _, rawTx := range rawTxs {
+			processedTx, err := processor.ReparseRawTx(db, rawTx)
+			if err != nil {
+				config.Log.Errorf("Error reparsing tx %s at height %d. Err: %v", rawTx.Hash, rawTx.Height, err)
+				failed++
+				continue
+			}
+			reparsed++
+
+			added, removed := dbTypes.DiffTaxableTxFingerprints(storedFingerprints[rawTx.TxID], dbTypes.GetTxTaxableTxFingerprints(processedTx))
+			storedUnparsed, unparsed := storedUnparsedCounts[rawTx.TxID], dbTypes.CountUnparsedMessages(processedTx)
+			if len(added) == 0 && len(removed) == 0 && storedUnparsed == unparsed {
+				continue
+			}
+			changed++
+
+			config.Log.Infof("Tx %s at height %d: %d taxable txs added, %d removed", rawTx.Hash, rawTx.Height, len(added), len(removed))
+			if storedUnparsed != unparsed {
+				config.Log.Infof("Tx %s at height %d: unparsed messages went from %d to %d", rawTx.Hash, rawTx.Height, storedUnparsed, unparsed)
+			}
+			for _, fingerprint := range removed {
+				config.Log.Debugf("Tx %s removed taxable tx: %+v", rawTx.Hash, fingerprint)
+			}
+			for _, fingerprint := range added {
+				config.Log.Debugf("Tx %s added taxable tx: %+v", rawTx.Hash, fingerprint)
+			}
+
+			changedTxIDs = append(changedTxIDs, rawTx.TxID)
+			blockIndex, ok := blockIndexes[rawTx.Height]
+			if !ok {
+				blockIndex = len(blocks)
+				blockIndexes[rawTx.Height] = blockIndex
+				blocks = append(blocks, dbTypes.BlockDBWrapper{Height: rawTx.Height, Time: rawTx.TimeStamp})
+			}
+			blocks[blockIndex].Txs = append(blocks[blockIndex].Txs, processedTx)
+		}
+
+		if cfg.Base.Apply && len(changedTxIDs) > 0 {
+			start := time.Now()
+			err := dbTypes.ReplaceTaxableTxs(db, blocks, changedTxIDs, dbChainID)
+			if err != nil {
+				config.Log.Fatal("Error writing reparsed txs", err)
+			}
+			config.Log.Infof("Wrote %d reparsed txs in %s", len(changedTxIDs), time.Since(start))
+		}
+	}
+
+	if cfg.Base.Apply {
+		config.Log.Infof("Reparse done. %d txs reparsed, %d changed and written, %d failed", reparsed, changed, failed)
+	} else {
+		config.Log.Infof("Reparse done. %d txs reparsed, %d would change, %d failed. Run with base.apply to write the changes", reparsed, changed, failed)
+	}
+}
@@ -13,6 +13,11 @@ import (
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 	"gorm.io/gorm"
+
+	// Chains with chain specific parsing register it with core in their init funcs
+	_ "github.com/DefiantLabs/cosmos-tax-cli/cosmoshub"
+	_ "github.com/DefiantLabs/cosmos-tax-cli/juno"
+	_ "github.com/DefiantLabs/cosmos-tax-cli/osmosis"
 )
 
 var (
@@ -93,7 +98,16 @@ func bindFlags(cmd *cobra.Command, v *viper.Viper) {
 		// Apply the viper config value to the flag when the flag is not set and viper has a value
 		if !f.Changed && v.IsSet(configName) {
 			val := v.Get(configName)
-			err := cmd.Flags().Set(f.Name, fmt.Sprintf("%v", val))
+			flagValue := fmt.Sprintf("%v", val)
+			// Lists in the config file need to be passed to slice flags as comma separated values
+			if list, ok := val.([]interface{}); ok {
+				values := make([]string, len(list))
+				for i, item := range list {
+					values[i] = fmt.Sprintf("%v", item)
+				}
+				flagValue = strings.Join(values, ",")
+			}
+			err := cmd.Flags().Set(f.Name, flagValue)
 			if err != nil {
 				log.Fatalf("Failed to bind config file value %v. Err: %v", configName, err)
 			}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/DefiantLabs/cosmos-tax-cli/config"
+	dbTypes "github.com/DefiantLabs/cosmos-tax-cli/db"
+	"github.com/spf13/cobra"
+	"gorm.io/gorm"
+)
+
+var (
+	unknownMessagesConfig       config.UnknownMessagesConfig
+	unknownMessagesDbConnection *gorm.DB
+)
+
+func init() {
+	config.SetupLogFlags(&unknownMessagesConfig.Log, unknownMessagesCmd)
+	config.SetupDatabaseFlags(&unknownMessagesConfig.Database, unknownMessagesCmd)
+	config.SetupLensFlags(&unknownMessagesConfig.Lens, unknownMessagesCmd)
+	config.SetupUnknownMessagesSpecificFlags(&unknownMessagesConfig, unknownMessagesCmd)
+	rootCmd.AddCommand(unknownMessagesCmd)
+}
+
+var unknownMessagesCmd = &cobra.Command{
+	Use:   "unknown-messages",
+	Short: "List the message types stored without a parser, most common first.",
+	Long: `Groups the messages the indexer stored as unparsed messages (with base.unknown-messages set to lenient) by
+	message type, with the number of messages and txs and the heights they were seen at, to prioritize writing parsers.
+	Once a parser exists, the reparse command parses the stored raw txs again.`,
+	PreRunE: setupUnknownMessages,
+	Run:     listUnknownMessages,
+}
+
+func setupUnknownMessages(cmd *cobra.Command, args []string) error {
+	bindFlags(cmd, viperConf)
+
+	err := unknownMessagesConfig.Validate()
+	if err != nil {
+		return err
+	}
+
+	ignoredKeys := config.CheckSuperfluousUnknownMessagesKeys(viperConf.AllKeys())
+
+	if len(ignoredKeys) > 0 {
+		config.Log.Warnf("Warning, the following invalid keys will be ignored: %v", ignoredKeys)
+	}
+
+	setupLogger(unknownMessagesConfig.Log.Level, unknownMessagesConfig.Log.Path, unknownMessagesConfig.Log.Pretty)
+
+	db, err := connectToDBAndMigrate(unknownMessagesConfig.Database)
+	if err != nil {
+		config.Log.Fatal("Could not establish connection to the database", err)
+	}
+
+	unknownMessagesDbConnection = db
+
+	return nil
+}
+
+func listUnknownMessages(cmd *cobra.Command, args []string) {
+	var chain dbTypes.Chain
+	err := unknownMessagesDbConnection.Where("chain_id = ?", unknownMessagesConfig.Lens.ChainID).First(&chain).Error
+	if err != nil {
+		config.Log.Fatalf("Error finding chain %s in the DB. Err: %v", unknownMessagesConfig.Lens.ChainID, err)
+	}
+
+	messageTypes, err := dbTypes.GetUnknownMessageTypes(unknownMessagesDbConnection, chain.ID, unknownMessagesConfig.Base.StartBlock, unknownMessagesConfig.Base.EndBlock)
+	if err != nil {
+		config.Log.Fatal("Error getting unparsed messages", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "MESSAGES\tTXS\tMESSAGE TYPE\tLOWEST HEIGHT\tHIGHEST HEIGHT")
+	for _, messageType := range messageTypes {
+		fmt.Fprintf(w, "%d\t%d\t%s\t%d\t%d\n", messageType.Messages, messageType.Txs, messageType.MessageType, messageType.MinHeight, messageType.MaxHeight)
+	}
+	w.Flush()
+
+	fmt.Printf("%d unknown message types\n", len(messageTypes))
+}
@@ -4,6 +4,7 @@ import (
 	"os"
 
 	"github.com/DefiantLabs/cosmos-tax-cli/config"
+	"github.com/DefiantLabs/cosmos-tax-cli/core"
 	"github.com/DefiantLabs/cosmos-tax-cli/tasks"
 	"github.com/spf13/cobra"
 	"gorm.io/gorm"
@@ -67,13 +68,17 @@ func updateDenoms(cmd *cobra.Command, args []string) {
 	switch {
 	case cfg.Base.UpdateAll:
 		config.Log.Infof("Running denom update task for all supported chains")
-		for chainID, function := range tasks.ChainSpecificDenomUpsertFunctions {
-			config.Log.Infof("Running denom update task for chain %s", chainID)
+		for _, plugin := range core.ChainPlugins() {
+			function := core.NewChainRegistry(plugin.ChainID()).DenomUpsert()
+			if function == nil {
+				continue
+			}
+			config.Log.Infof("Running denom update task for chain %s", plugin.ChainID())
 			function(db, cfg.Base.RequestRetryAttempts, cfg.Base.RequestRetryMaxWait, cfg.AssetList)
 		}
 	case cfg.Lens.ChainID != "":
-		function, ok := tasks.ChainSpecificDenomUpsertFunctions[cfg.Lens.ChainID]
-		if ok {
+		function := core.NewChainRegistry(cfg.Lens.ChainID).DenomUpsert()
+		if function != nil {
 			config.Log.Infof("Running denom update task for chain %s found in config", cfg.Lens.ChainID)
 			function(db, cfg.Base.RequestRetryAttempts, cfg.Base.RequestRetryMaxWait, cfg.AssetList)
 			config.Log.Info("Done")
@@ -4,9 +4,8 @@ import (
 	"time"
 
 	"github.com/DefiantLabs/cosmos-tax-cli/config"
+	"github.com/DefiantLabs/cosmos-tax-cli/core"
 	dbTypes "github.com/DefiantLabs/cosmos-tax-cli/db"
-	"github.com/DefiantLabs/cosmos-tax-cli/osmosis"
-	epochsTypes "github.com/DefiantLabs/cosmos-tax-cli/osmosis/modules/epochs"
 	"github.com/DefiantLabs/cosmos-tax-cli/rpc"
 	"github.com/DefiantLabs/lens/client"
 	"github.com/spf13/cobra"
@@ -70,7 +69,8 @@ func updateEpochs(cmd *cobra.Command, args []string) {
 
 	epochIdentifier := cfg.Base.EpochIdentifier
 
-	if cl.Config.ChainID == osmosis.ChainID {
+	// Only the epochs the chain's plugin has event handlers for can be indexed
+	if core.NewChainRegistry(cl.Config.ChainID).HasEpochIdentifier(epochIdentifier) {
 		// Setup Chain model item
 		var chain dbTypes.Chain
 		chain.ChainID = cl.Config.ChainID
@@ -154,7 +154,7 @@ func updateEpochs(cmd *cobra.Command, args []string) {
 			}
 		}
 	} else {
-		config.Log.Infof("Chain %s is not supported by this command.", cl.Config.ChainID)
+		config.Log.Infof("Chain %s is not supported by this command for epoch identifier %s.", cl.Config.ChainID, epochIdentifier)
 	}
 }
 
@@ -178,10 +178,8 @@ func indexEpochsAtStartingHeight(db *gorm.DB, cl *client.ChainClient, startingHe
 
 		// Get the index of the shortest duration EpochInfo, this will be used for the querying mechanism
 		for _, epoch := range resp.Epochs {
-			// Make sure we have the ability to index this EpochInfo
-			// This will save us trouble if Osmosis adds more Epochs in the future
-			indexable, identifierExists := epochsTypes.OsmosisIndexableEpochs[epoch.Identifier]
-			if identifierExists && indexable && epoch.Identifier == identifierToIndex {
+			// The chain supports indexing the identifier, see updateEpochs
+			if epoch.Identifier == identifierToIndex {
 
 				if epoch.CurrentEpochStartHeight <= 0 {
 					config.Log.Debugf("Found Epoch %d that contains 0 for CurrentEpochStartHeight, cannot continue", epoch.CurrentEpoch)
@@ -0,0 +1,273 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/DefiantLabs/cosmos-tax-cli/config"
+	"github.com/DefiantLabs/cosmos-tax-cli/core"
+	dbTypes "github.com/DefiantLabs/cosmos-tax-cli/db"
+	"github.com/DefiantLabs/cosmos-tax-cli/rpc"
+	"github.com/spf13/cobra"
+	"gorm.io/gorm"
+)
+
+var (
+	verifyConfig       config.VerifyConfig
+	verifyDbConnection *gorm.DB
+)
+
+// How many heights are loaded from the DB at a time
+const verifyChunkSize = 1000
+
+func init() {
+	config.SetupLogFlags(&verifyConfig.Log, verifyCmd)
+	config.SetupDatabaseFlags(&verifyConfig.Database, verifyCmd)
+	config.SetupLensFlags(&verifyConfig.Lens, verifyCmd)
+	config.SetupVerifySpecificFlags(&verifyConfig, verifyCmd)
+	rootCmd.AddCommand(verifyCmd)
+}
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Verify that a range of blocks is completely indexed by comparing the DB to the chain.",
+	Long: `Checks every block in the height range for missing blocks, blocks without a block time, failed blocks and
+	failed block events, and compares the number of txs and messages stored for each block to the chain. The counts
+	are not compared for chains with a watch list, their blocks only store the txs of the watched addresses.
+	Exits with a non zero exit code if any block has a discrepancy, optionally enqueueing those blocks to be reindexed.`,
+	PreRunE: setupVerify,
+	Run:     verify,
+}
+
+// blockDiscrepancy is a block that is not indexed the way the chain says it should be
+type blockDiscrepancy struct {
+	height  int64
+	blockID uint // 0 if the block is not stored
+	reasons []string
+	reindex bool // false if reindexing the block would not fix the discrepancies
+}
+
+func setupVerify(cmd *cobra.Command, args []string) error {
+	bindFlags(cmd, viperConf)
+
+	err := verifyConfig.Validate()
+	if err != nil {
+		return err
+	}
+
+	ignoredKeys := config.CheckSuperfluousVerifyKeys(viperConf.AllKeys())
+
+	if len(ignoredKeys) > 0 {
+		config.Log.Warnf("Warning, the following invalid keys will be ignored: %v", ignoredKeys)
+	}
+
+	setupLogger(verifyConfig.Log.Level, verifyConfig.Log.Path, verifyConfig.Log.Pretty)
+
+	db, err := connectToDBAndMigrate(verifyConfig.Database)
+	if err != nil {
+		config.Log.Fatal("Could not establish connection to the database", err)
+	}
+
+	verifyDbConnection = db
+
+	return nil
+}
+
+func verify(cmd *cobra.Command, args []string) {
+	cfg := verifyConfig
+	db := verifyDbConnection
+
+	dbConn, err := db.DB()
+	if err != nil {
+		config.Log.Fatal("Failed to connect to DB", err)
+	}
+	defer dbConn.Close()
+
+	pool := rpc.NewClientPool(config.GetLensClients(cfg.Lens))
+
+	dbChainID, err := dbTypes.GetDBChainID(db, dbTypes.Chain{ChainID: cfg.Lens.ChainID, Name: cfg.Lens.ChainName})
+	if err != nil {
+		config.Log.Fatal("Failed to add/create chain in DB", err)
+	}
+
+	startBlock := cfg.Base.StartBlock
+	endBlock := cfg.Base.EndBlock
+	if endBlock == -1 {
+		endBlock = dbTypes.GetHighestIndexedBlock(db, dbChainID).Height
+	}
+	if endBlock < startBlock {
+		config.Log.Fatalf("Nothing to verify, the highest indexed block %d is below the start block %d", endBlock, startBlock)
+	}
+
+	// Watch mode only stores the txs of the watched addresses, the stored counts can't be compared to the chain
+	watched, err := dbTypes.GetWatchedAddresses(db, dbChainID)
+	if err != nil {
+		config.Log.Fatal("Error getting the watched addresses", err)
+	}
+	compareCounts := len(watched) == 0
+	if !compareCounts {
+		config.Log.Info("The chain has a watch list, the tx and message counts of the blocks are not compared to the chain")
+	}
+
+	config.Log.Infof("Verifying blocks %d to %d", startBlock, endBlock)
+
+	var discrepancies []*blockDiscrepancy
+	checkPool := newOrderedPool(int(cfg.Base.RPCWorkers), func(discrepancy *blockDiscrepancy) {
+		config.Log.Warnf("Block %d: %s", discrepancy.height, strings.Join(discrepancy.reasons, ", "))
+		discrepancies = append(discrepancies, discrepancy)
+	})
+
+	ctx := context.Background()
+	for chunkStart := startBlock; chunkStart <= endBlock; chunkStart += verifyChunkSize {
+		chunkEnd := min(chunkStart+verifyChunkSize-1, endBlock)
+
+		summaries, err := dbTypes.GetBlockIndexSummaries(db, dbChainID, chunkStart, chunkEnd)
+		if err != nil {
+			config.Log.Fatal("Error getting indexed blocks", err)
+		}
+		failedHeights, err := dbTypes.GetFailedBlockHeightsInRange(db, dbChainID, chunkStart, chunkEnd)
+		if err != nil {
+			config.Log.Fatal("Error getting failed blocks", err)
+		}
+		failedEventHeights, err := dbTypes.GetFailedEventBlockHeightsInRange(db, dbChainID, chunkStart, chunkEnd)
+		if err != nil {
+			config.Log.Fatal("Error getting failed event blocks", err)
+		}
+
+		failed := heightSet(failedHeights)
+		failedEvents := heightSet(failedEventHeights)
+
+		for height := chunkStart; height <= chunkEnd; height++ {
+			height := height
+			summary, stored := summaries[height]
+			_, isFailed := failed[height]
+			_, isFailedEvents := failedEvents[height]
+			checkPool.Submit(ctx, func() (*blockDiscrepancy, bool, error) {
+				discrepancy := checkBlock(pool, height, summary, stored, isFailed, isFailedEvents, compareCounts)
+				return discrepancy, discrepancy != nil, nil
+			})
+		}
+
+		config.Log.Infof("Checked blocks up to %d", chunkEnd)
+	}
+	checkPool.Close()
+
+	if len(discrepancies) == 0 {
+		config.Log.Infof("Verification passed, blocks %d to %d are completely indexed", startBlock, endBlock)
+		return
+	}
+
+	var reindexHeights []int64
+	for _, discrepancy := range discrepancies {
+		if discrepancy.reindex {
+			reindexHeights = append(reindexHeights, discrepancy.height)
+		}
+	}
+
+	if cfg.Base.OutputFile != "" {
+		heightsJSON, err := json.Marshal(reindexHeights)
+		if err != nil {
+			config.Log.Fatal("Error encoding the block heights", err)
+		}
+		err = os.WriteFile(cfg.Base.OutputFile, heightsJSON, 0o600)
+		if err != nil {
+			config.Log.Fatal("Error writing the output file", err)
+		}
+		config.Log.Infof("Wrote %d block heights to reindex to %s", len(reindexHeights), cfg.Base.OutputFile)
+	}
+
+	if cfg.Base.Enqueue && len(reindexHeights) > 0 {
+		// Stale txs would survive a reindex, the blocks are indexed again from scratch
+		for _, discrepancy := range discrepancies {
+			if discrepancy.reindex && discrepancy.blockID != 0 {
+				err := dbTypes.ResetBlock(db, discrepancy.blockID)
+				if err != nil {
+					config.Log.Fatal(fmt.Sprintf("Error resetting block %d", discrepancy.height), err)
+				}
+			}
+		}
+
+		err := dbTypes.EnqueueBlockJobs(db, dbChainID, reindexHeights, true)
+		if err != nil {
+			config.Log.Fatal("Error adding the blocks to the work queue", err)
+		}
+		config.Log.Infof("Added %d blocks to the work queue to be reindexed", len(reindexHeights))
+	}
+
+	config.Log.Errorf("Verification failed, %d of blocks %d to %d have discrepancies (%d can be fixed by reindexing)", len(discrepancies), startBlock, endBlock, len(reindexHeights))
+	os.Exit(1)
+}
+
+// checkBlock compares what is stored for the block to the chain, it returns nil if they match. The tx and message
+// counts are only compared if compareCounts is set.
+func checkBlock(pool *rpc.ClientPool, height int64, summary dbTypes.BlockIndexSummary, stored bool, failed bool, failedEvents bool, compareCounts bool) *blockDiscrepancy {
+	discrepancy := &blockDiscrepancy{height: height, blockID: summary.ID}
+
+	if failed {
+		discrepancy.reasons = append(discrepancy.reasons, "in failed blocks")
+		discrepancy.reindex = true
+	}
+	if failedEvents {
+		discrepancy.reasons = append(discrepancy.reasons, "in failed event blocks")
+	}
+
+	switch {
+	case !stored || !summary.Indexed:
+		discrepancy.reasons = append(discrepancy.reasons, "not indexed")
+		discrepancy.reindex = true
+	case summary.TimeStamp.IsZero():
+		discrepancy.reasons = append(discrepancy.reasons, "no block time")
+		discrepancy.reindex = true
+	case compareCounts:
+		txCount, messageCount, err := getChainCounts(pool, height)
+		if err != nil {
+			discrepancy.reasons = append(discrepancy.reasons, fmt.Sprintf("could not be checked against the chain: %v", err))
+			break
+		}
+		if txCount != summary.TxCount {
+			discrepancy.reasons = append(discrepancy.reasons, fmt.Sprintf("%d txs indexed, %d on chain", summary.TxCount, txCount))
+			discrepancy.reindex = true
+		}
+		if messageCount != summary.MessageCount {
+			discrepancy.reasons = append(discrepancy.reasons, fmt.Sprintf("%d messages indexed, %d on chain", summary.MessageCount, messageCount))
+			discrepancy.reindex = true
+		}
+	}
+
+	if len(discrepancy.reasons) == 0 {
+		return nil
+	}
+	return discrepancy
+}
+
+// getChainCounts gets the number of txs in the block and the number of messages the indexer should have stored for it
+func getChainCounts(pool *rpc.ClientPool, height int64) (int, int, error) {
+	block, err := rpc.GetBlockFromPool(pool, height)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// Messages of failed txs are not stored, the tx results say which txs failed
+	blockResults, err := rpc.GetBlockByHeightFromPool(pool, height)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	messageCount, err := core.CountBlockMessages(pool.Primary(), block, blockResults)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return len(block.Block.Txs), messageCount, nil
+}
+
+func heightSet(heights []int64) map[int64]struct{} {
+	set := make(map[int64]struct{}, len(heights))
+	for _, height := range heights {
+		set[height] = struct{}{}
+	}
+	return set
+}
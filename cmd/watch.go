@@ -0,0 +1,219 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/DefiantLabs/cosmos-tax-cli/config"
+	dbTypes "github.com/DefiantLabs/cosmos-tax-cli/db"
+	"github.com/DefiantLabs/cosmos-tax-cli/rpc"
+	"github.com/spf13/cobra"
+	"gorm.io/gorm"
+)
+
+var (
+	watchConfig       config.WatchConfig
+	watchDbConnection *gorm.DB
+)
+
+// How often an indexer in watch mode reloads the watch list from the DB
+const watchListReloadInterval = time.Minute
+
+func init() {
+	config.SetupLogFlags(&watchConfig.Log, watchCmd)
+	config.SetupDatabaseFlags(&watchConfig.Database, watchCmd)
+	config.SetupLensFlags(&watchConfig.Lens, watchCmd)
+	config.SetupWatchSpecificFlags(&watchConfig, watchCmd)
+	watchCmd.AddCommand(watchAddCmd, watchRemoveCmd, watchListCmd)
+	rootCmd.AddCommand(watchCmd)
+}
+
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Manage the addresses an indexer in watch mode stores data for.",
+	Long: `An indexer running with base.watch-mode only stores the txs, fees and block events that touch an address on the
+	watch list of its chain. Running indexers pick up changes to the watch list within a minute.`,
+}
+
+var watchAddCmd = &cobra.Command{
+	Use:   "add",
+	Short: "Add addresses to the watch list and backfill their data.",
+	Long: `Adds the addresses to the watch list. Unless base.backfill is false, the tx search of the RPC node is used to
+	find the already indexed blocks between base.start-block and base.end-block with txs of the new addresses, and
+	those blocks are requeued in the work queue so the indexer of the chain stores the txs of the new addresses.
+	Backfilling requires the chain to be indexed with base.work-queue, set base.work-queue to confirm it. Block
+	events are not requeued, index them again with base.index-block-events over the same range to backfill them.`,
+	PreRunE: setupWatch,
+	Run:     addWatchedAddresses,
+}
+
+var watchRemoveCmd = &cobra.Command{
+	Use:     "remove",
+	Short:   "Remove addresses from the watch list, their stored data is kept.",
+	PreRunE: setupWatch,
+	Run:     removeWatchedAddresses,
+}
+
+var watchListCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "List the watched addresses.",
+	PreRunE: setupWatch,
+	Run:     listWatchedAddresses,
+}
+
+func setupWatch(cmd *cobra.Command, args []string) error {
+	bindFlags(cmd, viperConf)
+
+	err := watchConfig.Validate()
+	if err != nil {
+		return err
+	}
+
+	ignoredKeys := config.CheckSuperfluousWatchKeys(viperConf.AllKeys())
+
+	if len(ignoredKeys) > 0 {
+		config.Log.Warnf("Warning, the following invalid keys will be ignored: %v", ignoredKeys)
+	}
+
+	setupLogger(watchConfig.Log.Level, watchConfig.Log.Path, watchConfig.Log.Pretty)
+
+	db, err := connectToDBAndMigrate(watchConfig.Database)
+	if err != nil {
+		config.Log.Fatal("Could not establish connection to the database", err)
+	}
+
+	watchDbConnection = db
+
+	return nil
+}
+
+func getWatchChain() uint {
+	dbChainID, err := dbTypes.GetDBChainID(watchDbConnection, dbTypes.Chain{ChainID: watchConfig.Lens.ChainID, Name: watchConfig.Lens.ChainName})
+	if err != nil {
+		config.Log.Fatal("Failed to add/create chain in DB", err)
+	}
+	return dbChainID
+}
+
+func addWatchedAddresses(cmd *cobra.Command, args []string) {
+	cfg := watchConfig
+	db := watchDbConnection
+	chainID := getWatchChain()
+
+	if len(cfg.Base.Addresses) == 0 {
+		config.Log.Fatal("base.addresses must contain at least one address")
+	}
+
+	if cfg.Base.Backfill {
+		if err := cfg.ValidateBackfill(); err != nil {
+			config.Log.Fatal("Cannot backfill the new addresses", err)
+		}
+	}
+
+	added, err := dbTypes.AddWatchedAddresses(db, chainID, cfg.Base.Addresses)
+	if err != nil {
+		config.Log.Fatal("Error adding the watched addresses", err)
+	}
+	config.Log.Infof("Added %d new addresses to the watch list: %v", len(added), added)
+
+	if !cfg.Base.Backfill || len(added) == 0 {
+		return
+	}
+
+	endBlock := cfg.Base.EndBlock
+	if endBlock == -1 {
+		endBlock = dbTypes.GetHighestIndexedBlock(db, chainID).Height
+	}
+	if endBlock < cfg.Base.StartBlock {
+		config.Log.Infof("Nothing to backfill, the highest indexed block %d is below the start block %d", endBlock, cfg.Base.StartBlock)
+		return
+	}
+
+	// Only the blocks with txs of the new addresses are requeued, the others have nothing more to store
+	cl := config.GetLensClient(cfg.Lens)
+	queries := addressSearchQueries(added, addressTxSearchKeys, "tx.height", cfg.Base.StartBlock, endBlock)
+	heights, err := searchHeights(queries, func(query string) ([]int64, error) {
+		return rpc.SearchTxHeights(cl, query)
+	})
+	if err != nil {
+		config.Log.Fatal("Error searching the RPC node for the blocks of the new addresses", err)
+	}
+
+	err = dbTypes.EnqueueBlockJobs(db, chainID, heights, true)
+	if err != nil {
+		config.Log.Fatal("Error requeueing the blocks of the new addresses", err)
+	}
+	config.Log.Infof("Requeued %d blocks between %d and %d with txs of the new addresses in the work queue", len(heights), cfg.Base.StartBlock, endBlock)
+}
+
+func removeWatchedAddresses(cmd *cobra.Command, args []string) {
+	chainID := getWatchChain()
+
+	removed, err := dbTypes.RemoveWatchedAddresses(watchDbConnection, chainID, watchConfig.Base.Addresses)
+	if err != nil {
+		config.Log.Fatal("Error removing the watched addresses", err)
+	}
+	config.Log.Infof("Removed %d addresses from the watch list", removed)
+}
+
+func listWatchedAddresses(cmd *cobra.Command, args []string) {
+	chainID := getWatchChain()
+
+	watched, err := dbTypes.GetWatchedAddresses(watchDbConnection, chainID)
+	if err != nil {
+		config.Log.Fatal("Error getting the watched addresses", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ADDRESS\tADDED")
+	for _, address := range watched {
+		fmt.Fprintf(w, "%s\t%s\n", address.Address.Address, address.AddedAt.Format(time.RFC3339))
+	}
+	w.Flush()
+
+	fmt.Printf("%d watched addresses\n", len(watched))
+}
+
+// setupWatchList adds the configured addresses to the watch list, loads it and keeps it up to date until ctx is done
+func (idxr *Indexer) setupWatchList(ctx context.Context, dbChainID uint) error {
+	if len(idxr.cfg.Base.WatchedAddresses) > 0 {
+		added, err := dbTypes.AddWatchedAddresses(idxr.db, dbChainID, idxr.cfg.Base.WatchedAddresses)
+		if err != nil {
+			return fmt.Errorf("error adding the configured watched addresses: %w", err)
+		}
+		if len(added) > 0 {
+			config.Log.Infof("Added %d configured addresses to the watch list, run the watch add command to backfill them", len(added))
+		}
+	}
+
+	watchList, err := dbTypes.LoadWatchList(idxr.db, dbChainID)
+	if err != nil {
+		return fmt.Errorf("error loading the watch list: %w", err)
+	}
+	if watchList.Len() == 0 {
+		config.Log.Warn("Watch mode is enabled but the watch list is empty, no txs or events will be stored")
+	} else {
+		config.Log.Infof("Watch mode, only storing data for %d watched addresses", watchList.Len())
+	}
+	idxr.watchList = watchList
+
+	go func() {
+		ticker := time.NewTicker(watchListReloadInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := watchList.Reload(idxr.db, dbChainID); err != nil {
+					config.Log.Error("Error reloading the watch list, keeping the previous one.", err)
+				}
+			}
+		}
+	}()
+
+	return nil
+}
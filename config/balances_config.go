@@ -0,0 +1,100 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/DefiantLabs/cosmos-tax-cli/util"
+	"github.com/spf13/cobra"
+)
+
+type BalancesConfig struct {
+	Database Database
+	Lens     lens
+	Base     balancesBase
+	Log      log
+}
+
+type balancesBase struct {
+	Addresses []string `mapstructure:"addresses"`
+	Height    int64    `mapstructure:"height"`
+	Date      string   `mapstructure:"date"`
+	ChunkSize int64    `mapstructure:"chunk-size"`
+}
+
+// Layout of base.date, the same as the query command dates
+const balancesDateLayout = "2006-01-02:15:04:05"
+
+func SetupBalancesSpecificFlags(conf *BalancesConfig, cmd *cobra.Command) {
+	cmd.PersistentFlags().StringSliceVar(&conf.Base.Addresses, "base.addresses", nil, "show: a comma separated list of the address(s) to show the balances of")
+	cmd.PersistentFlags().Int64Var(&conf.Base.Height, "base.height", -1, "show: show the balances after the block at this height. -1 uses the latest balances")
+	cmd.PersistentFlags().StringVar(&conf.Base.Date, "base.date", "", "show: show the balances as of this date instead of a height, in the format 'YYYY-MM-DD:HH:MM:SS' in UTC")
+	cmd.PersistentFlags().Int64Var(&conf.Base.ChunkSize, "base.chunk-size", 1000, "rebuild: number of blocks whose balances are derived in a single DB transaction")
+}
+
+func (conf *BalancesConfig) Validate() error {
+	err := validateDatabaseConf(conf.Database)
+	if err != nil {
+		return err
+	}
+
+	// Only the chain is needed, the balances are read from the DB
+	if util.StrNotSet(conf.Lens.ChainID) {
+		return errors.New("lens chain-id must be set")
+	}
+
+	for _, address := range conf.Base.Addresses {
+		if strings.Contains(address, " ") {
+			return fmt.Errorf("invalid address '%v', addresses cannot contain spaces", address)
+		}
+	}
+
+	if conf.Base.Height != -1 && conf.Base.Height < 1 {
+		return errors.New("base.height must be -1 or 1 or greater")
+	}
+
+	if conf.Base.Date != "" {
+		if conf.Base.Height != -1 {
+			return errors.New("base.height and base.date cannot both be set")
+		}
+		if _, err := conf.GetDate(); err != nil {
+			return fmt.Errorf("invalid date '%v'", conf.Base.Date)
+		}
+	}
+
+	if conf.Base.ChunkSize < 1 {
+		return errors.New("base.chunk-size must be 1 or greater")
+	}
+
+	return nil
+}
+
+// GetDate parses base.date, it must be set
+func (conf *BalancesConfig) GetDate() (time.Time, error) {
+	return time.Parse(balancesDateLayout, conf.Base.Date)
+}
+
+func CheckSuperfluousBalancesKeys(keys []string) []string {
+	validKeys := make(map[string]struct{})
+
+	addDatabaseConfigKeys(validKeys)
+	addLogConfigKeys(validKeys)
+	addLensConfigKeys(validKeys)
+
+	// add base keys
+	for _, key := range getValidConfigKeys(balancesBase{}, "base") {
+		validKeys[key] = struct{}{}
+	}
+
+	// Check keys
+	ignoredKeys := make([]string, 0)
+	for _, key := range keys {
+		if _, ok := validKeys[key]; !ok {
+			ignoredKeys = append(ignoredKeys, key)
+		}
+	}
+
+	return ignoredKeys
+}
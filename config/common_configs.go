@@ -28,9 +28,10 @@ type Database struct {
 
 type lens struct {
 	RPC           string
-	AccountPrefix string `mapstructure:"account-prefix"`
-	ChainID       string `mapstructure:"chain-id"`
-	ChainName     string `mapstructure:"chain-name"`
+	RPCPool       []string `mapstructure:"rpc-pool"`
+	AccountPrefix string   `mapstructure:"account-prefix"`
+	ChainID       string   `mapstructure:"chain-id"`
+	ChainName     string   `mapstructure:"chain-name"`
 }
 
 type throttlingBase struct {
@@ -59,6 +60,7 @@ func SetupDatabaseFlags(databaseConf *Database, cmd *cobra.Command) {
 
 func SetupLensFlags(lensConf *lens, cmd *cobra.Command) {
 	cmd.PersistentFlags().StringVar(&lensConf.RPC, "lens.rpc", "", "node rpc endpoint")
+	cmd.PersistentFlags().StringSliceVar(&lensConf.RPCPool, "lens.rpc-pool", []string{}, "additional node rpc endpoints, block queries are routed to the healthiest endpoint that has the history for the block")
 	cmd.PersistentFlags().StringVar(&lensConf.AccountPrefix, "lens.account-prefix", "", "lens account prefix")
 	cmd.PersistentFlags().StringVar(&lensConf.ChainID, "lens.chain-id", "", "lens chain ID")
 	cmd.PersistentFlags().StringVar(&lensConf.ChainName, "lens.chain-name", "", "lens chain name")
@@ -92,13 +94,12 @@ func validateLensConf(lensConf lens) (lens, error) {
 	if util.StrNotSet(lensConf.RPC) {
 		return lensConf, errors.New("lens rpc must be set")
 	}
-	// add port if not set
-	if strings.Count(lensConf.RPC, ":") != 2 {
-		if strings.HasPrefix(lensConf.RPC, "https:") {
-			lensConf.RPC = fmt.Sprintf("%s:443", lensConf.RPC)
-		} else if strings.HasPrefix(lensConf.RPC, "http:") {
-			lensConf.RPC = fmt.Sprintf("%s:80", lensConf.RPC)
+	lensConf.RPC = addDefaultRPCPort(lensConf.RPC)
+	for i, rpc := range lensConf.RPCPool {
+		if util.StrNotSet(rpc) {
+			return lensConf, errors.New("lens rpc-pool endpoints must not be empty")
 		}
+		lensConf.RPCPool[i] = addDefaultRPCPort(rpc)
 	}
 
 	if util.StrNotSet(lensConf.AccountPrefix) {
@@ -113,6 +114,18 @@ func validateLensConf(lensConf lens) (lens, error) {
 	return lensConf, nil
 }
 
+// addDefaultRPCPort adds the default port for the scheme if the endpoint does not have one
+func addDefaultRPCPort(rpc string) string {
+	if strings.Count(rpc, ":") != 2 {
+		if strings.HasPrefix(rpc, "https:") {
+			return fmt.Sprintf("%s:443", rpc)
+		} else if strings.HasPrefix(rpc, "http:") {
+			return fmt.Sprintf("%s:80", rpc)
+		}
+	}
+	return rpc
+}
+
 func validateThrottlingConf(throttlingConf throttlingBase) error {
 	if throttlingConf.Throttling < 0 {
 		return errors.New("throttling must be a positive number or 0")
@@ -120,6 +133,15 @@ func validateThrottlingConf(throttlingConf throttlingBase) error {
 	return nil
 }
 
+func validateBankEventFallback(mode string) error {
+	switch mode {
+	case BankEventFallbackOff, BankEventFallbackUnknown, BankEventFallbackAll:
+		return nil
+	default:
+		return fmt.Errorf("base.bank-event-fallback must be one of %s, %s or %s", BankEventFallbackOff, BankEventFallbackUnknown, BankEventFallbackAll)
+	}
+}
+
 // Reads the Viper mapstructure tag to get the valid keys for a given config struct
 func getValidConfigKeys(section any, baseName string) (keys []string) {
 	v := reflect.ValueOf(section)
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"errors"
+
+	"github.com/DefiantLabs/cosmos-tax-cli/util"
+	"github.com/spf13/cobra"
+)
+
+type FailedBlocksConfig struct {
+	Database Database
+	Lens     lens
+	Base     failedBlocksBase
+	Log      log
+}
+
+type failedBlocksBase struct {
+	StartBlock  int64  `mapstructure:"start-block"`
+	EndBlock    int64  `mapstructure:"end-block"`
+	Code        string `mapstructure:"code"`
+	MessageType string `mapstructure:"message-type"`
+	OutputFile  string `mapstructure:"output-file"`
+}
+
+func SetupFailedBlocksSpecificFlags(conf *FailedBlocksConfig, cmd *cobra.Command) {
+	cmd.PersistentFlags().Int64Var(&conf.Base.StartBlock, "base.start-block", 0, "only include failed blocks at or above this height")
+	cmd.PersistentFlags().Int64Var(&conf.Base.EndBlock, "base.end-block", -1, "only include failed blocks at or below this height (-1 for no limit)")
+	cmd.PersistentFlags().StringVar(&conf.Base.Code, "base.code", "", "only include failed blocks that failed with this failure code (e.g. unprocessable_tx_error)")
+	cmd.PersistentFlags().StringVar(&conf.Base.MessageType, "base.message-type", "", "only include failed blocks that failed on a message of this type")
+	cmd.PersistentFlags().StringVar(&conf.Base.OutputFile, "base.output-file", "", "retry: also write the heights to retry to this file as a JSON list, usable as base.block-input-file")
+}
+
+func (conf *FailedBlocksConfig) Validate() error {
+	err := validateDatabaseConf(conf.Database)
+	if err != nil {
+		return err
+	}
+
+	// Only the chain is needed, the failed blocks are read from the DB
+	if util.StrNotSet(conf.Lens.ChainID) {
+		return errors.New("lens chain-id must be set")
+	}
+
+	if conf.Base.EndBlock != -1 && conf.Base.EndBlock < conf.Base.StartBlock {
+		return errors.New("base.end-block must be -1 or at least base.start-block")
+	}
+
+	return nil
+}
+
+func CheckSuperfluousFailedBlocksKeys(keys []string) []string {
+	validKeys := make(map[string]struct{})
+
+	addDatabaseConfigKeys(validKeys)
+	addLogConfigKeys(validKeys)
+	addLensConfigKeys(validKeys)
+
+	// add base keys
+	for _, key := range getValidConfigKeys(failedBlocksBase{}, "base") {
+		validKeys[key] = struct{}{}
+	}
+
+	// Check keys
+	ignoredKeys := make([]string, 0)
+	for _, key := range keys {
+		if _, ok := validKeys[key]; !ok {
+			ignoredKeys = append(ignoredKeys, key)
+		}
+	}
+
+	return ignoredKeys
+}
@@ -6,9 +6,23 @@ import (
 	"os"
 	"strings"
 
+	"github.com/mitchellh/mapstructure"
 	"github.com/spf13/cobra"
 )
 
+const (
+	ErrorPolicyRetry = "retry"
+	ErrorPolicySkip  = "skip"
+	ErrorPolicyAbort = "abort"
+
+	UnknownMessagesStrict  = "strict"
+	UnknownMessagesLenient = "lenient"
+
+	BankEventFallbackOff     = "off"
+	BankEventFallbackUnknown = "unknown"
+	BankEventFallbackAll     = "all"
+)
+
 type IndexConfig struct {
 	Database           Database
 	ConfigFileLocation string
@@ -22,27 +36,46 @@ type IndexConfig struct {
 type indexBase struct {
 	throttlingBase
 	retryBase
-	ReindexMessageType        string `mapstructure:"re-index-message-type"`
-	ReattemptFailedBlocks     bool   `mapstructure:"reattempt-failed-blocks"`
-	API                       string `mapstructure:"api"`
-	StartBlock                int64  `mapstructure:"start-block"`
-	EndBlock                  int64  `mapstructure:"end-block"`
-	BlockInputFile            string `mapstructure:"block-input-file"`
-	ReIndex                   bool   `mapstructure:"reindex"`
-	RPCWorkers                int64  `mapstructure:"rpc-workers"`
-	BlockTimer                int64  `mapstructure:"block-timer"`
-	WaitForChain              bool   `mapstructure:"wait-for-chain"`
-	WaitForChainDelay         int64  `mapstructure:"wait-for-chain-delay"`
-	ChainIndexingEnabled      bool   `mapstructure:"index-chain"`
-	ExitWhenCaughtUp          bool   `mapstructure:"exit-when-caught-up"`
-	BlockEventIndexingEnabled bool   `mapstructure:"index-block-events"`
-	Dry                       bool   `mapstructure:"dry"`
-	BlockEventsStartBlock     int64  `mapstructure:"block-events-start-block"`
-	BlockEventsEndBlock       int64  `mapstructure:"block-events-end-block"`
-	EpochEventIndexingEnabled bool   `mapstructure:"index-epoch-events"`
-	EpochIndexingIdentifier   string `mapstructure:"epoch-indexing-identifier"`
-	EpochEventsStartEpoch     int64  `mapstructure:"epoch-events-start-epoch"`
-	EpochEventsEndEpoch       int64  `mapstructure:"epoch-events-end-epoch"`
+	ReindexMessageType        string   `mapstructure:"re-index-message-type"`
+	ReattemptFailedBlocks     bool     `mapstructure:"reattempt-failed-blocks"`
+	FailedBlockRetryInterval  int64    `mapstructure:"failed-block-retry-interval"`
+	FailedBlockRetryMaxWait   int64    `mapstructure:"failed-block-retry-max-wait"`
+	FailedBlockMaxAttempts    int64    `mapstructure:"failed-block-max-attempts"`
+	API                       string   `mapstructure:"api"`
+	StartBlock                int64    `mapstructure:"start-block"`
+	EndBlock                  int64    `mapstructure:"end-block"`
+	BlockInputFile            string   `mapstructure:"block-input-file"`
+	ReIndex                   bool     `mapstructure:"reindex"`
+	RPCWorkers                int64    `mapstructure:"rpc-workers"`
+	EventWorkers              int64    `mapstructure:"event-workers"`
+	DBBatchSize               int64    `mapstructure:"db-batch-size"`
+	StoreRawTxs               bool     `mapstructure:"store-raw-txs"`
+	BlockTimer                int64    `mapstructure:"block-timer"`
+	WaitForChain              bool     `mapstructure:"wait-for-chain"`
+	WaitForChainDelay         int64    `mapstructure:"wait-for-chain-delay"`
+	ChainIndexingEnabled      bool     `mapstructure:"index-chain"`
+	ExitWhenCaughtUp          bool     `mapstructure:"exit-when-caught-up"`
+	BlockEventIndexingEnabled bool     `mapstructure:"index-block-events"`
+	Dry                       bool     `mapstructure:"dry"`
+	BlockEventsStartBlock     int64    `mapstructure:"block-events-start-block"`
+	BlockEventsEndBlock       int64    `mapstructure:"block-events-end-block"`
+	EpochEventIndexingEnabled bool     `mapstructure:"index-epoch-events"`
+	EpochIndexingIdentifier   string   `mapstructure:"epoch-indexing-identifier"`
+	EpochEventsStartEpoch     int64    `mapstructure:"epoch-events-start-epoch"`
+	EpochEventsEndEpoch       int64    `mapstructure:"epoch-events-end-epoch"`
+	ConfirmationDepth         int64    `mapstructure:"confirmation-depth"`
+	ReorgCheckDepth           int64    `mapstructure:"reorg-check-depth"`
+	WorkQueue                 bool     `mapstructure:"work-queue"`
+	WorkQueueLease            int64    `mapstructure:"work-queue-lease"`
+	MetricsAddress            string   `mapstructure:"metrics-address"`
+	ErrorPolicy               string   `mapstructure:"error-policy"`
+	ErrorRetryAttempts        int64    `mapstructure:"error-retry-attempts"`
+	UnknownMessages           string   `mapstructure:"unknown-messages"`
+	BankEventFallback         string   `mapstructure:"bank-event-fallback"`
+	WatchMode                 bool     `mapstructure:"watch-mode"`
+	WatchedAddresses          []string `mapstructure:"watched-addresses"`
+	Addresses                 []string `mapstructure:"addresses"`
+	IBCLinkInterval           int64    `mapstructure:"ibc-link-interval"`
 }
 
 func SetupIndexSpecificFlags(conf *IndexConfig, cmd *cobra.Command) {
@@ -53,7 +86,14 @@ func SetupIndexSpecificFlags(conf *IndexConfig, cmd *cobra.Command) {
 	cmd.PersistentFlags().StringVar(&conf.Base.BlockInputFile, "base.block-input-file", "", "A file location containing a JSON list of block heights to index. Will override start and end block flags.")
 	cmd.PersistentFlags().BoolVar(&conf.Base.ReIndex, "base.reindex", false, "if true, this will re-attempt to index blocks we have already indexed (defaults to false)")
 	cmd.PersistentFlags().BoolVar(&conf.Base.ReattemptFailedBlocks, "base.reattempt-failed-blocks", false, "re-enqueue failed blocks for reattempts at startup.")
+	cmd.PersistentFlags().Int64Var(&conf.Base.FailedBlockRetryInterval, "base.failed-block-retry-interval", 0, "seconds between background retries of failed blocks and failed block events while indexing (0 to disable)")
+	cmd.PersistentFlags().Int64Var(&conf.Base.FailedBlockRetryMaxWait, "base.failed-block-retry-max-wait", 3600, "max seconds a failed block waits before it is retried again, the wait grows with every failed attempt")
+	cmd.PersistentFlags().Int64Var(&conf.Base.FailedBlockMaxAttempts, "base.failed-block-max-attempts", 20, "failed blocks are no longer retried in the background after failing this many times")
 	cmd.PersistentFlags().StringVar(&conf.Base.ReindexMessageType, "base.reindex-message-type", "", "a Cosmos message type URL. When set, the block enqueue method will reindex all blocks between start and end block that contain this message type.")
+	cmd.PersistentFlags().Int64Var(&conf.Base.ConfirmationDepth, "base.confirmation-depth", 0, "number of blocks a height must be behind the chain tip before it is indexed")
+	cmd.PersistentFlags().Int64Var(&conf.Base.ReorgCheckDepth, "base.reorg-check-depth", 10, "when following the chain tip, the number of most recently indexed blocks whose hashes are periodically checked against the node and reindexed if they differ (0 to disable)")
+	cmd.PersistentFlags().BoolVar(&conf.Base.WorkQueue, "base.work-queue", false, "enqueue blocks in a persistent work queue table, allows multiple indexers to share a block range and restarts to resume where they stopped")
+	cmd.PersistentFlags().Int64Var(&conf.Base.WorkQueueLease, "base.work-queue-lease", 600, "seconds a claimed work queue block is leased to an indexer before another indexer may claim it")
 	// block event indexing
 	cmd.PersistentFlags().BoolVar(&conf.Base.BlockEventIndexingEnabled, "base.index-block-events", false, "enable block beginblocker and endblocker event indexing?")
 	cmd.PersistentFlags().Int64Var(&conf.Base.BlockEventsStartBlock, "base.block-events-start-block", 0, "block to start indexing block events at")
@@ -67,9 +107,20 @@ func SetupIndexSpecificFlags(conf *IndexConfig, cmd *cobra.Command) {
 	cmd.PersistentFlags().BoolVar(&conf.Base.Dry, "base.dry", false, "index the chain but don't insert data in the DB.")
 	cmd.PersistentFlags().StringVar(&conf.Base.API, "base.api", "", "node api endpoint")
 	cmd.PersistentFlags().Int64Var(&conf.Base.RPCWorkers, "base.rpc-workers", 1, "rpc workers")
+	cmd.PersistentFlags().BoolVar(&conf.Base.StoreRawTxs, "base.store-raw-txs", false, "store the raw tx, memo, gas and message events of every indexed tx for auditing and reparsing")
+	cmd.PersistentFlags().Int64Var(&conf.Base.DBBatchSize, "base.db-batch-size", 10, "max number of blocks written to the DB in a single transaction")
+	cmd.PersistentFlags().Int64Var(&conf.Base.EventWorkers, "base.event-workers", 1, "rpc workers for the block and epoch event indexers, events are still written to the DB in height order")
 	cmd.PersistentFlags().BoolVar(&conf.Base.WaitForChain, "base.wait-for-chain", false, "wait for chain to be in sync?")
 	cmd.PersistentFlags().Int64Var(&conf.Base.WaitForChainDelay, "base.wait-for-chain-delay", 10, "seconds to wait between each check for node to catch up to the chain")
 	cmd.PersistentFlags().Int64Var(&conf.Base.BlockTimer, "base.block-timer", 10000, "print out how long it takes to process this many blocks")
+	cmd.PersistentFlags().StringVar(&conf.Base.ErrorPolicy, "base.error-policy", ErrorPolicyRetry, "what to do when a block fails: retry (with backoff, then mark it failed), skip (mark it failed and continue) or abort (stop indexing)")
+	cmd.PersistentFlags().Int64Var(&conf.Base.ErrorRetryAttempts, "base.error-retry-attempts", 3, "number of times a failed block is retried when the error policy is retry")
+	cmd.PersistentFlags().StringVar(&conf.Base.UnknownMessages, "base.unknown-messages", UnknownMessagesStrict, "what to do with messages that have no parser and are not ignored: strict (fail the block) or lenient (store them as unparsed messages and continue)")
+	cmd.PersistentFlags().StringVar(&conf.Base.BankEventFallback, "base.bank-event-fallback", BankEventFallbackOff, "infer the taxable txs of messages without a parser from their bank events: off, unknown (messages that are not on the ignore list) or all")
+	cmd.PersistentFlags().BoolVar(&conf.Base.WatchMode, "base.watch-mode", false, "only store the txs, fees and block events that touch an address on the watch list of the chain, blocks are still all marked indexed")
+	cmd.PersistentFlags().StringSliceVar(&conf.Base.WatchedAddresses, "base.watched-addresses", nil, "addresses added to the watch list at startup, without a backfill (see the watch command)")
+	cmd.PersistentFlags().Int64Var(&conf.Base.IBCLinkInterval, "base.ibc-link-interval", 0, "seconds between runs of the job linking IBC transfers to their packets received on the other indexed chains in the DB (0 to disable)")
+	cmd.PersistentFlags().StringVar(&conf.Base.MetricsAddress, "base.metrics-address", "", "address to serve Prometheus metrics on (e.g. :9090), metrics are disabled if empty")
 	cmd.PersistentFlags().BoolVar(&conf.Base.ExitWhenCaughtUp, "base.exit-when-caught-up", false, "mainly used for Osmosis rewards indexing")
 	cmd.PersistentFlags().Int64Var(&conf.Base.RequestRetryAttempts, "base.request-retry-attempts", 0, "number of RPC query retries to make")
 	cmd.PersistentFlags().Uint64Var(&conf.Base.RequestRetryMaxWait, "base.request-retry-max-wait", 30, "max retry incremental backoff wait time in seconds")
@@ -78,6 +129,11 @@ func SetupIndexSpecificFlags(conf *IndexConfig, cmd *cobra.Command) {
 	cmd.PersistentFlags().StringVar(&conf.AssetList.OsmosisAssetListURL, "asset-list.osmosis-asset-list-url", "https://raw.githubusercontent.com/cosmos/chain-registry/master/osmosis/assetlist.json", "osmosis asset list url, must fit the asset list schema")
 }
 
+// SetupIndexAddressFlags adds the flags of the index-address command on top of the index flags
+func SetupIndexAddressFlags(conf *IndexConfig, cmd *cobra.Command) {
+	cmd.PersistentFlags().StringSliceVar(&conf.Base.Addresses, "base.addresses", nil, "a comma separated list of the address(s) to index the blocks of")
+}
+
 func (conf *IndexConfig) Validate() error {
 	err := validateDatabaseConf(conf.Database)
 	if err != nil {
@@ -115,6 +171,72 @@ func (conf *IndexConfig) Validate() error {
 		}
 	}
 
+	switch conf.Base.ErrorPolicy {
+	case ErrorPolicyRetry, ErrorPolicySkip, ErrorPolicyAbort:
+	default:
+		return fmt.Errorf("base.error-policy must be one of %s, %s or %s", ErrorPolicyRetry, ErrorPolicySkip, ErrorPolicyAbort)
+	}
+
+	switch conf.Base.UnknownMessages {
+	case UnknownMessagesStrict, UnknownMessagesLenient:
+	default:
+		return fmt.Errorf("base.unknown-messages must be %s or %s", UnknownMessagesStrict, UnknownMessagesLenient)
+	}
+
+	err = validateBankEventFallback(conf.Base.BankEventFallback)
+	if err != nil {
+		return err
+	}
+
+	if len(conf.Base.WatchedAddresses) > 0 && !conf.Base.WatchMode {
+		return errors.New("base.watched-addresses is only used with base.watch-mode")
+	}
+
+	if conf.Base.DBBatchSize < 1 {
+		return errors.New("base.db-batch-size must be 1 or greater")
+	}
+
+	if conf.Base.EventWorkers < 1 {
+		return errors.New("base.event-workers must be 1 or greater")
+	}
+
+	if conf.Base.FailedBlockRetryInterval < 0 {
+		return errors.New("base.failed-block-retry-interval must be 0 or greater")
+	}
+
+	if conf.Base.IBCLinkInterval < 0 {
+		return errors.New("base.ibc-link-interval must be 0 or greater")
+	}
+
+	if conf.Base.FailedBlockRetryMaxWait < 1 {
+		return errors.New("base.failed-block-retry-max-wait must be 1 or greater")
+	}
+
+	if conf.Base.FailedBlockMaxAttempts < 1 {
+		return errors.New("base.failed-block-max-attempts must be 1 or greater")
+	}
+
+	if conf.Base.ConfirmationDepth < 0 {
+		return errors.New("base.confirmation-depth must be 0 or greater")
+	}
+
+	if conf.Base.ReorgCheckDepth < 0 {
+		return errors.New("base.reorg-check-depth must be 0 or greater")
+	}
+
+	if conf.Base.ErrorRetryAttempts < 0 {
+		return errors.New("base.error-retry-attempts must be 0 or greater")
+	}
+
+	if conf.Base.WorkQueue {
+		if conf.Base.Dry {
+			return errors.New("base.work-queue cannot be used with base.dry, the work queue is stored in the DB")
+		}
+		if conf.Base.WorkQueueLease <= 0 {
+			return errors.New("base.work-queue-lease must be greater than 0 when work-queue is enabled")
+		}
+	}
+
 	// Check for required configs when block event indexer is enabled
 	if conf.Base.BlockEventIndexingEnabled {
 		// If block event indexes are not valid, error
@@ -156,6 +278,87 @@ func (conf *IndexConfig) Validate() error {
 	return nil
 }
 
+// Base settings of the whole process, they can't be set per chain
+var sharedIndexBaseKeys = []string{"metrics-address", "ibc-link-interval"}
+
+// ChainConfigs returns the config of every chain to index. The chains are the [[chains]] tables of the config file,
+// each one only holds the lens and base settings of its chain. Those are applied over a copy of this config, so the
+// settings all chains share only have to be set once at the top level. Without chains this config is the only chain.
+func (conf *IndexConfig) ChainConfigs(chains any) ([]*IndexConfig, error) {
+	if chains == nil {
+		return []*IndexConfig{conf}, nil
+	}
+
+	entries, ok := chains.([]any)
+	if !ok {
+		return nil, errors.New("chains must be a list of chain tables")
+	}
+	if len(entries) == 0 {
+		return []*IndexConfig{conf}, nil
+	}
+
+	var chainConfs []*IndexConfig
+	chainIDs := make(map[string]int)
+	for i, entry := range entries {
+		settings, ok := entry.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("chains[%d] must be a table of chain settings", i)
+		}
+
+		for section, values := range settings {
+			switch strings.ToLower(section) {
+			case "lens":
+			case "base":
+				baseSettings, ok := values.(map[string]any)
+				if !ok {
+					return nil, fmt.Errorf("chains[%d].base must be a table", i)
+				}
+				for key := range baseSettings {
+					for _, shared := range sharedIndexBaseKeys {
+						if strings.ToLower(key) == shared {
+							return nil, fmt.Errorf("chains[%d]: base.%s is shared by all chains and can only be set at the top level", i, shared)
+						}
+					}
+				}
+			default:
+				return nil, fmt.Errorf("chains[%d]: only the lens and base settings can be set per chain, not %s", i, section)
+			}
+		}
+
+		chainConf := *conf
+		// The slices are copied, validating a chain config updates them in place
+		chainConf.Lens.RPCPool = append([]string(nil), conf.Lens.RPCPool...)
+		chainConf.Base.WatchedAddresses = append([]string(nil), conf.Base.WatchedAddresses...)
+		chainConf.Base.Addresses = append([]string(nil), conf.Base.Addresses...)
+
+		decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+			Result:           &chainConf,
+			WeaklyTypedInput: true,
+			ZeroFields:       true,
+			ErrorUnused:      true,
+			Squash:           true,
+		})
+		if err != nil {
+			return nil, err
+		}
+		err = decoder.Decode(settings)
+		if err != nil {
+			return nil, fmt.Errorf("chains[%d]: %w", i, err)
+		}
+
+		if chainConf.Lens.ChainID != "" {
+			if other, ok := chainIDs[chainConf.Lens.ChainID]; ok {
+				return nil, fmt.Errorf("chains[%d] and chains[%d] both index chain %s", other, i, chainConf.Lens.ChainID)
+			}
+			chainIDs[chainConf.Lens.ChainID] = i
+		}
+
+		chainConfs = append(chainConfs, &chainConf)
+	}
+
+	return chainConfs, nil
+}
+
 func CheckSuperfluousIndexKeys(keys []string) []string {
 	validKeys := make(map[string]struct{})
 
@@ -176,6 +379,9 @@ func CheckSuperfluousIndexKeys(keys []string) []string {
 		validKeys[key] = struct{}{}
 	}
 
+	// The chain tables are checked when they are decoded, see ChainConfigs
+	validKeys["chains"] = struct{}{}
+
 	// Check keys
 	ignoredKeys := make([]string, 0)
 	for _, key := range keys {
@@ -18,6 +18,17 @@ func GetLensClient(conf lens) *lensClient.ChainClient {
 	return cl
 }
 
+// GetLensClients returns a client for the lens RPC endpoint followed by a client for each RPC pool endpoint
+func GetLensClients(conf lens) []*lensClient.ChainClient {
+	clients := []*lensClient.ChainClient{GetLensClient(conf)}
+	for _, rpc := range conf.RPCPool {
+		poolConf := conf
+		poolConf.RPC = rpc
+		clients = append(clients, GetLensClient(poolConf))
+	}
+	return clients
+}
+
 func RegisterAdditionalTypes(cc *lensClient.ChainClient) {
 	// Register IBC types
 	// ibcTypes.RegisterLegacyAminoCodec(cc.Codec.Amino)
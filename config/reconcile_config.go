@@ -0,0 +1,83 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+type ReconcileConfig struct {
+	Database Database
+	Lens     lens
+	Base     reconcileBase
+	Log      log
+}
+
+type reconcileBase struct {
+	Addresses  []string `mapstructure:"addresses"`
+	Height     int64    `mapstructure:"height"`
+	OutputFile string   `mapstructure:"output-file"`
+}
+
+func SetupReconcileSpecificFlags(conf *ReconcileConfig, cmd *cobra.Command) {
+	cmd.PersistentFlags().StringSliceVar(&conf.Base.Addresses, "base.addresses", nil, "A comma separated list of the address(s) to reconcile")
+	cmd.PersistentFlags().Int64Var(&conf.Base.Height, "base.height", -1, "height to reconcile the addresses at. -1 uses the highest indexed block")
+	cmd.PersistentFlags().StringVar(&conf.Base.OutputFile, "base.output-file", "", "write the per-denom positions and drift of each address to this file as JSON")
+}
+
+func (conf *ReconcileConfig) Validate() error {
+	err := validateDatabaseConf(conf.Database)
+	if err != nil {
+		return err
+	}
+
+	lensConf := conf.Lens
+
+	lensConf, err = validateLensConf(lensConf)
+	if err != nil {
+		return err
+	}
+
+	conf.Lens = lensConf
+
+	if len(conf.Base.Addresses) == 0 {
+		return errors.New("base.addresses must contain at least one address")
+	}
+
+	for _, address := range conf.Base.Addresses {
+		if strings.Contains(address, " ") {
+			return fmt.Errorf("invalid address '%v', addresses cannot contain spaces", address)
+		}
+	}
+
+	if conf.Base.Height != -1 && conf.Base.Height < 1 {
+		return errors.New("base.height must be -1 or 1 or greater")
+	}
+
+	return nil
+}
+
+func CheckSuperfluousReconcileKeys(keys []string) []string {
+	validKeys := make(map[string]struct{})
+
+	addDatabaseConfigKeys(validKeys)
+	addLogConfigKeys(validKeys)
+	addLensConfigKeys(validKeys)
+
+	// add base keys
+	for _, key := range getValidConfigKeys(reconcileBase{}, "base") {
+		validKeys[key] = struct{}{}
+	}
+
+	// Check keys
+	ignoredKeys := make([]string, 0)
+	for _, key := range keys {
+		if _, ok := validKeys[key]; !ok {
+			ignoredKeys = append(ignoredKeys, key)
+		}
+	}
+
+	return ignoredKeys
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"errors"
+
+	"github.com/DefiantLabs/cosmos-tax-cli/util"
+	"github.com/spf13/cobra"
+)
+
+// The reparse command never queries the node, the lens client is only used for its codec
+const offlineLensRPC = "http://localhost:26657"
+
+type ReparseConfig struct {
+	Database Database
+	Lens     lens
+	Base     reparseBase
+	Log      log
+}
+
+type reparseBase struct {
+	StartBlock        int64  `mapstructure:"start-block"`
+	EndBlock          int64  `mapstructure:"end-block"`
+	MessageType       string `mapstructure:"message-type"`
+	Apply             bool   `mapstructure:"apply"`
+	BatchSize         int    `mapstructure:"batch-size"`
+	BankEventFallback string `mapstructure:"bank-event-fallback"`
+}
+
+func SetupReparseSpecificFlags(conf *ReparseConfig, cmd *cobra.Command) {
+	cmd.PersistentFlags().Int64Var(&conf.Base.StartBlock, "base.start-block", 0, "block to start reparsing at")
+	cmd.PersistentFlags().Int64Var(&conf.Base.EndBlock, "base.end-block", -1, "block to stop reparsing at. -1 reparses up to the highest stored tx")
+	cmd.PersistentFlags().StringVar(&conf.Base.MessageType, "base.message-type", "", "only reparse txs that contain a message of this type")
+	cmd.PersistentFlags().BoolVar(&conf.Base.Apply, "base.apply", false, "write the changed taxable txs to the DB. Without this the changes are only reported")
+	cmd.PersistentFlags().IntVar(&conf.Base.BatchSize, "base.batch-size", 500, "number of raw txs to load and write at a time")
+	cmd.PersistentFlags().StringVar(&conf.Base.BankEventFallback, "base.bank-event-fallback", BankEventFallbackOff, "infer the taxable txs of messages without a parser from their bank events: off, unknown (messages that are not on the ignore list) or all")
+}
+
+func (conf *ReparseConfig) Validate() error {
+	err := validateDatabaseConf(conf.Database)
+	if err != nil {
+		return err
+	}
+
+	lensConf := conf.Lens
+	if util.StrNotSet(lensConf.RPC) {
+		lensConf.RPC = offlineLensRPC
+	}
+
+	lensConf, err = validateLensConf(lensConf)
+	if err != nil {
+		return err
+	}
+
+	conf.Lens = lensConf
+
+	if conf.Base.EndBlock != -1 && conf.Base.EndBlock < conf.Base.StartBlock {
+		return errors.New("base.end-block must be -1 or at least base.start-block")
+	}
+
+	if conf.Base.BatchSize < 1 {
+		return errors.New("base.batch-size must be 1 or greater")
+	}
+
+	err = validateBankEventFallback(conf.Base.BankEventFallback)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func CheckSuperfluousReparseKeys(keys []string) []string {
+	validKeys := make(map[string]struct{})
+
+	addDatabaseConfigKeys(validKeys)
+	addLogConfigKeys(validKeys)
+	addLensConfigKeys(validKeys)
+
+	// add base keys
+	for _, key := range getValidConfigKeys(reparseBase{}, "base") {
+		validKeys[key] = struct{}{}
+	}
+
+	// Check keys
+	ignoredKeys := make([]string, 0)
+	for _, key := range keys {
+		if _, ok := validKeys[key]; !ok {
+			ignoredKeys = append(ignoredKeys, key)
+		}
+	}
+
+	return ignoredKeys
+}
@@ -0,0 +1,66 @@
+package config
+
+import (
+	"errors"
+
+	"github.com/DefiantLabs/cosmos-tax-cli/util"
+	"github.com/spf13/cobra"
+)
+
+type UnknownMessagesConfig struct {
+	Database Database
+	Lens     lens
+	Base     unknownMessagesBase
+	Log      log
+}
+
+type unknownMessagesBase struct {
+	StartBlock int64 `mapstructure:"start-block"`
+	EndBlock   int64 `mapstructure:"end-block"`
+}
+
+func SetupUnknownMessagesSpecificFlags(conf *UnknownMessagesConfig, cmd *cobra.Command) {
+	cmd.PersistentFlags().Int64Var(&conf.Base.StartBlock, "base.start-block", 0, "only include unparsed messages at or above this height")
+	cmd.PersistentFlags().Int64Var(&conf.Base.EndBlock, "base.end-block", -1, "only include unparsed messages at or below this height (-1 for no limit)")
+}
+
+func (conf *UnknownMessagesConfig) Validate() error {
+	err := validateDatabaseConf(conf.Database)
+	if err != nil {
+		return err
+	}
+
+	// Only the chain is needed, the unparsed messages are read from the DB
+	if util.StrNotSet(conf.Lens.ChainID) {
+		return errors.New("lens chain-id must be set")
+	}
+
+	if conf.Base.EndBlock != -1 && conf.Base.EndBlock < conf.Base.StartBlock {
+		return errors.New("base.end-block must be -1 or at least base.start-block")
+	}
+
+	return nil
+}
+
+func CheckSuperfluousUnknownMessagesKeys(keys []string) []string {
+	validKeys := make(map[string]struct{})
+
+	addDatabaseConfigKeys(validKeys)
+	addLogConfigKeys(validKeys)
+	addLensConfigKeys(validKeys)
+
+	// add base keys
+	for _, key := range getValidConfigKeys(unknownMessagesBase{}, "base") {
+		validKeys[key] = struct{}{}
+	}
+
+	// Check keys
+	ignoredKeys := make([]string, 0)
+	for _, key := range keys {
+		if _, ok := validKeys[key]; !ok {
+			ignoredKeys = append(ignoredKeys, key)
+		}
+	}
+
+	return ignoredKeys
+}
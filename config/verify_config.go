@@ -0,0 +1,83 @@
+package config
+
+import (
+	"errors"
+
+	"github.com/spf13/cobra"
+)
+
+type VerifyConfig struct {
+	Database Database
+	Lens     lens
+	Base     verifyBase
+	Log      log
+}
+
+type verifyBase struct {
+	StartBlock int64  `mapstructure:"start-block"`
+	EndBlock   int64  `mapstructure:"end-block"`
+	RPCWorkers int64  `mapstructure:"rpc-workers"`
+	Enqueue    bool   `mapstructure:"enqueue"`
+	OutputFile string `mapstructure:"output-file"`
+}
+
+func SetupVerifySpecificFlags(conf *VerifyConfig, cmd *cobra.Command) {
+	cmd.PersistentFlags().Int64Var(&conf.Base.StartBlock, "base.start-block", 1, "block to start verifying at")
+	cmd.PersistentFlags().Int64Var(&conf.Base.EndBlock, "base.end-block", -1, "block to stop verifying at. -1 verifies up to the highest indexed block")
+	cmd.PersistentFlags().Int64Var(&conf.Base.RPCWorkers, "base.rpc-workers", 1, "rpc workers")
+	cmd.PersistentFlags().BoolVar(&conf.Base.Enqueue, "base.enqueue", false, "add the blocks with discrepancies to the work queue to be reindexed by an indexer running with base.work-queue. Their stored txs are deleted first")
+	cmd.PersistentFlags().StringVar(&conf.Base.OutputFile, "base.output-file", "", "write the heights of the blocks with discrepancies to this file as a JSON list, usable as base.block-input-file")
+}
+
+func (conf *VerifyConfig) Validate() error {
+	err := validateDatabaseConf(conf.Database)
+	if err != nil {
+		return err
+	}
+
+	lensConf := conf.Lens
+
+	lensConf, err = validateLensConf(lensConf)
+	if err != nil {
+		return err
+	}
+
+	conf.Lens = lensConf
+
+	if conf.Base.StartBlock < 1 {
+		return errors.New("base.start-block must be 1 or greater")
+	}
+
+	if conf.Base.EndBlock != -1 && conf.Base.EndBlock < conf.Base.StartBlock {
+		return errors.New("base.end-block must be -1 or at least base.start-block")
+	}
+
+	if conf.Base.RPCWorkers < 1 {
+		return errors.New("base.rpc-workers must be 1 or greater")
+	}
+
+	return nil
+}
+
+func CheckSuperfluousVerifyKeys(keys []string) []string {
+	validKeys := make(map[string]struct{})
+
+	addDatabaseConfigKeys(validKeys)
+	addLogConfigKeys(validKeys)
+	addLensConfigKeys(validKeys)
+
+	// add base keys
+	for _, key := range getValidConfigKeys(verifyBase{}, "base") {
+		validKeys[key] = struct{}{}
+	}
+
+	// Check keys
+	ignoredKeys := make([]string, 0)
+	for _, key := range keys {
+		if _, ok := validKeys[key]; !ok {
+			ignoredKeys = append(ignoredKeys, key)
+		}
+	}
+
+	return ignoredKeys
+}
@@ -0,0 +1,99 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/DefiantLabs/cosmos-tax-cli/util"
+	"github.com/spf13/cobra"
+)
+
+type WatchConfig struct {
+	Database Database
+	Lens     lens
+	Base     watchBase
+	Log      log
+}
+
+type watchBase struct {
+	Addresses  []string `mapstructure:"addresses"`
+	Backfill   bool     `mapstructure:"backfill"`
+	StartBlock int64    `mapstructure:"start-block"`
+	EndBlock   int64    `mapstructure:"end-block"`
+	WorkQueue  bool     `mapstructure:"work-queue"`
+}
+
+func SetupWatchSpecificFlags(conf *WatchConfig, cmd *cobra.Command) {
+	cmd.PersistentFlags().StringSliceVar(&conf.Base.Addresses, "base.addresses", nil, "add, remove: a comma separated list of the address(s) to add to or remove from the watch list")
+	cmd.PersistentFlags().BoolVar(&conf.Base.Backfill, "base.backfill", true, "add: search the RPC node for the already indexed blocks with txs of the new addresses and requeue them in the work queue")
+	cmd.PersistentFlags().BoolVar(&conf.Base.WorkQueue, "base.work-queue", false, "add: the chain is indexed by an indexer running with base.work-queue, required to backfill since only such an indexer picks up requeued blocks")
+	cmd.PersistentFlags().Int64Var(&conf.Base.StartBlock, "base.start-block", 1, "add: first block to backfill")
+	cmd.PersistentFlags().Int64Var(&conf.Base.EndBlock, "base.end-block", -1, "add: last block to backfill. -1 backfills up to the highest indexed block")
+}
+
+func (conf *WatchConfig) Validate() error {
+	err := validateDatabaseConf(conf.Database)
+	if err != nil {
+		return err
+	}
+
+	// Only the chain is needed, the watch list is stored in the DB
+	if util.StrNotSet(conf.Lens.ChainID) {
+		return errors.New("lens chain-id must be set")
+	}
+
+	for _, address := range conf.Base.Addresses {
+		if strings.Contains(address, " ") || strings.Contains(address, "'") {
+			return fmt.Errorf("invalid address '%v', addresses cannot contain spaces or quotes", address)
+		}
+	}
+
+	if conf.Base.StartBlock < 1 {
+		return errors.New("base.start-block must be 1 or greater")
+	}
+
+	if conf.Base.EndBlock != -1 && conf.Base.EndBlock < conf.Base.StartBlock {
+		return errors.New("base.end-block must be -1 or at least base.start-block")
+	}
+
+	return nil
+}
+
+// ValidateBackfill checks the config can backfill added addresses, the heights to requeue are searched on the RPC node
+func (conf *WatchConfig) ValidateBackfill() error {
+	if !conf.Base.WorkQueue {
+		return errors.New("base.backfill requeues blocks in the work queue, which only an indexer running with base.work-queue picks up. Set base.work-queue if the chain is indexed that way, otherwise set base.backfill to false and run index-address for the new addresses")
+	}
+
+	lensConf, err := validateLensConf(conf.Lens)
+	if err != nil {
+		return err
+	}
+	conf.Lens = lensConf
+
+	return nil
+}
+
+func CheckSuperfluousWatchKeys(keys []string) []string {
+	validKeys := make(map[string]struct{})
+
+	addDatabaseConfigKeys(validKeys)
+	addLogConfigKeys(validKeys)
+	addLensConfigKeys(validKeys)
+
+	// add base keys
+	for _, key := range getValidConfigKeys(watchBase{}, "base") {
+		validKeys[key] = struct{}{}
+	}
+
+	// Check keys
+	ignoredKeys := make([]string, 0)
+	for _, key := range keys {
+		if _, ok := validKeys[key]; !ok {
+			ignoredKeys = append(ignoredKeys, key)
+		}
+	}
+
+	return ignoredKeys
+}
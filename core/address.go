@@ -18,12 +18,6 @@ import (
 	"github.com/cosmos/cosmos-sdk/types/bech32/legacybech32" // nolint:staticcheck
 )
 
-// consider not using globals
-var (
-	addressRegex  *regexp.Regexp
-	addressPrefix string
-)
-
 // TODO query this list from the DB
 var baseChainPrefixes = []string{
 	"juno",
@@ -59,18 +53,11 @@ func IsAddressEqual(addr1 string, prefix1 string, addr2 string, prefix2 string)
 	return bytes.Equal(bAddr1, bAddr2)
 }
 
-func SetupAddressRegex(addressRegexPattern string) {
-	addressRegex, _ = regexp.Compile(addressRegexPattern)
-}
-
-func SetupAddressPrefix(addressPrefixString string) {
-	addressPrefix = addressPrefixString
-}
-
-func ExtractTransactionAddresses(tx tx.MergedTx) []string {
-	messagesAddresses := util.WalkFindStrings(tx.Tx.Body.Messages, addressRegex)
+// ExtractTransactionAddresses finds the addresses of the chain in the messages and raw log of the tx
+func (p *ChainProcessor) ExtractTransactionAddresses(tx tx.MergedTx) []string {
+	messagesAddresses := util.WalkFindStrings(tx.Tx.Body.Messages, p.addressRegex)
 	// Consider walking logs - needs benchmarking compared to whole string search on raw log
-	logAddresses := addressRegex.FindAllString(tx.TxResponse.RawLog, -1)
+	logAddresses := p.addressRegex.FindAllString(tx.TxResponse.RawLog, -1)
 	addressMap := make(map[string]string)
 	for _, v := range append(messagesAddresses, logAddresses...) {
 		addressMap[v] = ""
@@ -84,7 +71,7 @@ func ExtractTransactionAddresses(tx tx.MergedTx) []string {
 	return uniqueAddresses
 }
 
-func ParseSignerAddress(pubkeyString string, keytype string) (retstring string, reterror error) {
+func ParseSignerAddress(pubkeyString string, keytype string, addressPrefix string) (retstring string, reterror error) {
 	defer func() {
 		if r := recover(); r != nil {
 			reterror = fmt.Errorf(fmt.Sprintf("Error parsing signer address into Bech32: %v", r))
@@ -0,0 +1,99 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/DefiantLabs/cosmos-tax-cli/config"
+	"github.com/DefiantLabs/cosmos-tax-cli/cosmos/modules/authz"
+	txtypes "github.com/DefiantLabs/cosmos-tax-cli/cosmos/modules/tx"
+	dbTypes "github.com/DefiantLabs/cosmos-tax-cli/db"
+	"github.com/cosmos/cosmos-sdk/types"
+	authztypes "github.com/cosmos/cosmos-sdk/x/authz"
+	"gorm.io/gorm"
+)
+
+// processMsgExec parses the messages a grantee executed on behalf of a granter.
+// The inner messages are indexed as messages of the Tx at the same message index as the MsgExec, distinguished by
+// their position in the MsgExec (a dotted path for nested MsgExecs). Since the inner messages are signed on behalf of
+// the granter, the taxable txs parsed from them belong to the granter and not to the Tx signer.
+func (p *ChainProcessor) processMsgExec(db *gorm.DB, height string, msgExec *authztypes.MsgExec, execLog *txtypes.LogMessage, messageIndex int, parentPath string) ([]dbTypes.MessageDBWrapper, error) {
+	innerMsgs, err := msgExec.GetMessages()
+	if err != nil {
+		config.Log.Error(fmt.Sprintf("[Block: %v] Error unpacking MsgExec inner messages.", height), err)
+		return nil, err
+	}
+
+	msgTypes := make([]string, len(innerMsgs))
+	for i, innerMsg := range innerMsgs {
+		msgTypes[i] = types.MsgTypeURL(innerMsg)
+	}
+
+	// Without a log per inner message their taxable data can't be parsed. Unless unknown messages are stored as
+	// unparsed messages, this fails the block rather than indexing the inner messages without their taxable data.
+	innerLogs, splitOk := authz.SplitMsgExecLog(execLog, msgTypes)
+	if !splitOk {
+		if !p.lenientUnknownMessages {
+			config.Log.Error(fmt.Sprintf("[Block: %v] Unable to split MsgExec log between %d inner messages.", height, len(innerMsgs)))
+			return nil, newMessageError(authz.MsgExec, fmt.Errorf("unable to split MsgExec log between %d inner messages", len(innerMsgs)))
+		}
+		config.Log.Warnf("[Block: %v] Unable to split MsgExec log between %d inner messages, storing them as unparsed messages.", height, len(innerMsgs))
+	}
+
+	var messages []dbTypes.MessageDBWrapper
+	for i, innerMsg := range innerMsgs {
+		path := strconv.Itoa(i)
+		if parentPath != "" {
+			path = parentPath + "." + path
+		}
+
+		currMessageDBWrapper := dbTypes.MessageDBWrapper{
+			Message: dbTypes.Message{
+				MessageIndex:  messageIndex,
+				AuthzMsgIndex: path,
+				MessageType:   dbTypes.MessageType{MessageType: msgTypes[i]},
+			},
+			TaxableTxs: []dbTypes.TaxableTxDBWrapper{},
+		}
+
+		if splitOk {
+			cosmosMessage, msgType, err := p.ParseCosmosMessage(innerMsg, innerLogs[i])
+			if err != nil {
+				if err != txtypes.ErrUnknownMessage {
+					config.Log.Error(fmt.Sprintf("[Block: %v] ParseCosmosMessage failed for MsgExec inner msg of type '%v'.", height, msgType), err)
+					return nil, newMessageError(msgType, fmt.Errorf("error parsing MsgExec inner message we have a parser for: '%v'", msgType))
+				}
+				if !p.registry.isIgnored(msgType) {
+					currMessageDBWrapper.Unparsed, err = p.handleUnknownMessage(height, innerMsg, msgType)
+					if err != nil {
+						return nil, err
+					}
+				}
+			} else {
+				currMessageDBWrapper.TaxableTxs, err = p.toTaxableTxDBWrappers(db, cosmosMessage.ParseRelevantData())
+				if err != nil {
+					return nil, newMessageError(msgType, err)
+				}
+				currMessageDBWrapper.IBCPacket = toIBCPacket(cosmosMessage)
+			}
+		} else if !p.registry.isIgnored(msgTypes[i]) {
+			currMessageDBWrapper.Unparsed = p.newUnparsedMessage(innerMsg, msgTypes[i])
+		}
+
+		messages = append(messages, currMessageDBWrapper)
+
+		if nestedExec, ok := innerMsg.(*authztypes.MsgExec); ok {
+			var nestedLog *txtypes.LogMessage
+			if splitOk {
+				nestedLog = &innerLogs[i]
+			}
+			nestedMessages, err := p.processMsgExec(db, height, nestedExec, nestedLog, messageIndex, path)
+			if err != nil {
+				return nil, err
+			}
+			messages = append(messages, nestedMessages...)
+		}
+	}
+
+	return messages, nil
+}
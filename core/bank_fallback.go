@@ -0,0 +1,24 @@
+package core
+
+import (
+	"github.com/DefiantLabs/cosmos-tax-cli/config"
+	"github.com/DefiantLabs/cosmos-tax-cli/cosmos/modules/authz"
+)
+
+// useBankEventFallback is true if the taxable txs of a message type without a parser should be inferred from its bank
+// events
+func (p *ChainProcessor) useBankEventFallback(msgType string) bool {
+	// The inner messages of a MsgExec are parsed on their own, the MsgExec events would count their funds twice
+	if msgType == authz.MsgExec {
+		return false
+	}
+
+	switch p.bankEventFallback {
+	case config.BankEventFallbackAll:
+		return true
+	case config.BankEventFallbackUnknown:
+		return !p.registry.isIgnored(msgType)
+	default:
+		return false
+	}
+}
@@ -5,38 +5,14 @@ import (
 
 	"github.com/DefiantLabs/cosmos-tax-cli/config"
 	eventTypes "github.com/DefiantLabs/cosmos-tax-cli/cosmos/events"
-	"github.com/DefiantLabs/cosmos-tax-cli/cosmoshub"
 	ctypes "github.com/cometbft/cometbft/rpc/core/types"
 )
 
-var (
-	beginBlockerEventTypeHandlers = map[string][]func() eventTypes.CosmosEvent{}
-	endBlockerEventTypeHandlers   = map[string][]func() eventTypes.CosmosEvent{}
-)
-
-func ChainSpecificEndBlockerEventTypeHandlerBootstrap(chainID string) {
-	var chainSpecificEndBlockerEventTypeHandler map[string][]func() eventTypes.CosmosEvent
-	if chainID == cosmoshub.ChainID {
-		chainSpecificEndBlockerEventTypeHandler = cosmoshub.EndBlockerEventTypeHandlers
-	}
-	for key, value := range chainSpecificEndBlockerEventTypeHandler {
-		if list, ok := endBlockerEventTypeHandlers[key]; ok {
-			endBlockerEventTypeHandlers[key] = append(value, list...)
-		} else {
-			endBlockerEventTypeHandlers[key] = value
-		}
-	}
-}
-
-func ChainSpecificBeginBlockerEventTypeHandlerBootstrap(chainID string) {
-	// Stub, for use when we have begin blocker events
-}
-
-func ProcessRPCBlockEvents(blockResults *ctypes.ResultBlockResults) ([]eventTypes.EventRelevantInformation, error) {
+func (p *ChainProcessor) ProcessRPCBlockEvents(blockResults *ctypes.ResultBlockResults) ([]eventTypes.EventRelevantInformation, error) {
 	var taxableEvents []eventTypes.EventRelevantInformation
-	if len(endBlockerEventTypeHandlers) != 0 {
+	if len(p.registry.endBlockerEventTypeHandlers) != 0 {
 		for _, event := range blockResults.EndBlockEvents {
-			handlers, handlersFound := endBlockerEventTypeHandlers[event.Type]
+			handlers, handlersFound := p.registry.endBlockerEventTypeHandlers[event.Type]
 
 			if !handlersFound {
 				continue
@@ -65,9 +41,9 @@ func ProcessRPCBlockEvents(blockResults *ctypes.ResultBlockResults) ([]eventType
 		}
 	}
 
-	if len(beginBlockerEventTypeHandlers) != 0 {
+	if len(p.registry.beginBlockerEventTypeHandlers) != 0 {
 		for _, event := range blockResults.BeginBlockEvents {
-			handlers, handlersFound := beginBlockerEventTypeHandlers[event.Type]
+			handlers, handlersFound := p.registry.beginBlockerEventTypeHandlers[event.Type]
 
 			if !handlersFound {
 				continue
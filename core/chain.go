@@ -0,0 +1,87 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/DefiantLabs/cosmos-tax-cli/config"
+	"github.com/DefiantLabs/cosmos-tax-cli/cosmwasm"
+	"github.com/DefiantLabs/cosmos-tax-cli/cosmwasm/modules/wasm"
+	dbTypes "github.com/DefiantLabs/cosmos-tax-cli/db"
+	"github.com/DefiantLabs/lens/client"
+	"github.com/cosmos/cosmos-sdk/types"
+	cosmosTx "github.com/cosmos/cosmos-sdk/types/tx"
+)
+
+// ChainProcessor parses the txs and block events of a single chain. Each indexed chain has its own, so chains indexed
+// by the same process don't share their client, address prefix or message and event handlers.
+type ChainProcessor struct {
+	cl                     *client.ChainClient
+	registry               *Registry
+	addressRegex           *regexp.Regexp
+	addressPrefix          string
+	storeRawTxs            bool
+	lenientUnknownMessages bool
+	bankEventFallback      string
+	denoms                 *dbTypes.DenomCache
+}
+
+// ChainProcessorOptions are the chain specific settings of a ChainProcessor
+type ChainProcessorOptions struct {
+	AccountPrefix string
+	// StoreRawTxs keeps the on-chain data of every processed tx alongside the parsed data
+	StoreRawTxs bool
+	// LenientUnknownMessages stores messages without a parser or ignore list entry as unparsed messages, instead of
+	// failing the block they are in
+	LenientUnknownMessages bool
+	// BankEventFallback sets which messages without a parser have their taxable txs inferred from their bank events
+	BankEventFallback string
+	// Denoms is the chain's cache of the denoms in the DB, the denoms of the parsed txs are looked up in it
+	Denoms *dbTypes.DenomCache
+}
+
+// NewChainProcessor sets up the parsing of the chain with the handlers of its registry. The CosmWasm handlers are looked
+// up on the chain through the client and added to the registry.
+func NewChainProcessor(cl *client.ChainClient, registry *Registry, opts ChainProcessorOptions) (*ChainProcessor, error) {
+	addressRegex, err := regexp.Compile(opts.AccountPrefix + "(valoper)?1[a-z0-9]{38}")
+	if err != nil {
+		return nil, fmt.Errorf("invalid account prefix '%v': %w", opts.AccountPrefix, err)
+	}
+
+	var customContractAddressHandlers []wasm.ContractExecutionMessageHandler
+	cosmWasmHandlers, err := cosmwasm.GetCosmWasmMessageTypeHandlers(customContractAddressHandlers, cl)
+	if err != nil {
+		return nil, fmt.Errorf("error getting CosmWasm message type handlers: %w", err)
+	}
+	registry.AddMessageTypeHandlers(cosmWasmHandlers)
+
+	return &ChainProcessor{
+		cl:                     cl,
+		registry:               registry,
+		addressRegex:           addressRegex,
+		addressPrefix:          opts.AccountPrefix,
+		storeRawTxs:            opts.StoreRawTxs,
+		lenientUnknownMessages: opts.LenientUnknownMessages,
+		bankEventFallback:      opts.BankEventFallback,
+		denoms:                 opts.Denoms,
+	}, nil
+}
+
+// signers returns the signers and the bech32 address of the fee payer of the tx. The SDK checks the addresses of the
+// messages against its global bech32 prefixes, so those are set to the prefixes of this chain while decoding them.
+func (p *ChainProcessor) signers(tx *cosmosTx.Tx) ([]types.AccAddress, string) {
+	unlock := p.cl.SetSDKContext()
+	defer unlock()
+
+	// AccAddress.String() caches the bech32 address by its bytes, which are the same for an account on every chain
+	signers := tx.GetSigners()
+	if len(signers) == 0 {
+		// Only messages of types the codec can't resolve, which have no known signers
+		feePayer, err := p.firstSignerAddress(*tx.AuthInfo)
+		if err != nil {
+			config.Log.Warnf("Fee payer of tx could not be derived from its signer infos. Err: %v", err)
+		}
+		return signers, feePayer
+	}
+	return signers, types.MustBech32ifyAddressBytes(p.addressPrefix, tx.FeePayer())
+}
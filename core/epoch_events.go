@@ -5,24 +5,13 @@ import (
 
 	"github.com/DefiantLabs/cosmos-tax-cli/config"
 	eventTypes "github.com/DefiantLabs/cosmos-tax-cli/cosmos/events"
-	osmosisTypes "github.com/DefiantLabs/cosmos-tax-cli/osmosis"
-	osmosisEpochTypes "github.com/DefiantLabs/cosmos-tax-cli/osmosis/epochs"
 	coretypes "github.com/cometbft/cometbft/rpc/core/types"
 )
 
-var epochIdentifierEventTypeHandlers = map[string]map[string]map[string][]func() eventTypes.CosmosEvent{}
-
-func ChainSpecificEpochIdentifierEventTypeHandlersBootstrap(chainID string) {
-	if chainID == osmosisTypes.ChainID {
-		// This is overwriting the entire map, but we only have one epoch module to worry about for now
-		epochIdentifierEventTypeHandlers = osmosisEpochTypes.EpochIdentifierBlockEventHandlers
-	}
-}
-
-func ProcessRPCEpochEvents(blockResults *coretypes.ResultBlockResults, epochIdentifier string) ([]eventTypes.EventRelevantInformation, error) {
+func (p *ChainProcessor) ProcessRPCEpochEvents(blockResults *coretypes.ResultBlockResults, epochIdentifier string) ([]eventTypes.EventRelevantInformation, error) {
 	var taxableEvents []eventTypes.EventRelevantInformation
 
-	if handlers, ok := epochIdentifierEventTypeHandlers[epochIdentifier]; ok {
+	if handlers, ok := p.registry.epochIdentifierEventTypeHandlers[epochIdentifier]; ok {
 		beginBlockHandlers, beginHandlersExist := handlers["begin_block"]
 		endBlockHandlers, endHandlersExist := handlers["end_block"]
 
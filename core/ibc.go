@@ -0,0 +1,44 @@
+package core
+
+import (
+	"strings"
+
+	"github.com/DefiantLabs/cosmos-tax-cli/cosmos/modules/ibc"
+	txtypes "github.com/DefiantLabs/cosmos-tax-cli/cosmos/modules/tx"
+	dbTypes "github.com/DefiantLabs/cosmos-tax-cli/db"
+)
+
+// toIBCPacket returns the identity of the ICS-20 packet sent or received by the message, or nil if it is not an IBC
+// transfer. The sides of a transfer are matched on it across the indexed chains, see db.LinkIBCPackets.
+func toIBCPacket(cosmosMessage txtypes.CosmosMessage) *dbTypes.IBCPacket {
+	switch msg := cosmosMessage.(type) {
+	case *ibc.WrapperMsgTransfer:
+		return &dbTypes.IBCPacket{
+			Direction:          dbTypes.IBCPacketSent,
+			SourcePort:         msg.SourcePort,
+			SourceChannel:      msg.SourceChannel,
+			DestinationPort:    msg.DestinationPort,
+			DestinationChannel: msg.DestinationChannel,
+			Sequence:           msg.Sequence,
+			Sender:             strings.ToLower(msg.SenderAddress),
+			Receiver:           strings.ToLower(msg.ReceiverAddress),
+		}
+	case *ibc.WrapperMsgRecvPacket:
+		// Only token transfer packets have their amount set
+		if msg.Amount.IsNil() {
+			return nil
+		}
+		packet := msg.MsgRecvPacket.Packet
+		return &dbTypes.IBCPacket{
+			Direction:          dbTypes.IBCPacketReceived,
+			SourcePort:         packet.SourcePort,
+			SourceChannel:      packet.SourceChannel,
+			DestinationPort:    packet.DestinationPort,
+			DestinationChannel: packet.DestinationChannel,
+			Sequence:           packet.Sequence,
+			Sender:             strings.ToLower(msg.SenderAddress),
+			Receiver:           strings.ToLower(msg.ReceiverAddress),
+		}
+	}
+	return nil
+}
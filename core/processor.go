@@ -1,6 +1,7 @@
 package core
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/DefiantLabs/cosmos-tax-cli/config"
@@ -16,12 +17,105 @@ const (
 	OsmosisNodeRewardIndexError
 	NodeMissingHistoryForBlock
 	FailedBlockEventHandling
+	BlockDBWriteError
 )
 
+// String returns a short name for the failure code, used as a metrics label
+func (code BlockProcessingFailure) String() string {
+	switch code {
+	case NodeMissingBlockTxs:
+		return "node_missing_block_txs"
+	case BlockQueryError:
+		return "block_query_error"
+	case UnprocessableTxError:
+		return "unprocessable_tx_error"
+	case OsmosisNodeRewardLookupError:
+		return "osmosis_node_reward_lookup_error"
+	case OsmosisNodeRewardIndexError:
+		return "osmosis_node_reward_index_error"
+	case NodeMissingHistoryForBlock:
+		return "node_missing_history_for_block"
+	case FailedBlockEventHandling:
+		return "failed_block_event_handling"
+	case BlockDBWriteError:
+		return "block_db_write_error"
+	}
+	return "unknown"
+}
+
+// BlockProcessingError is a failure to process a block, it carries the failure code up to the indexer error policy
+type BlockProcessingError struct {
+	Height int64
+	Code   BlockProcessingFailure
+	Err    error
+}
+
+func (e *BlockProcessingError) Error() string {
+	return fmt.Sprintf("block %d failed (%s): %v", e.Height, e.Code, e.Err)
+}
+
+func (e *BlockProcessingError) Unwrap() error {
+	return e.Err
+}
+
+// AsBlockProcessingError returns the BlockProcessingError in the error chain, or wraps the error in one with the default code
+func AsBlockProcessingError(height int64, defaultCode BlockProcessingFailure, err error) *BlockProcessingError {
+	var blockErr *BlockProcessingError
+	if errors.As(err, &blockErr) {
+		return blockErr
+	}
+	return &BlockProcessingError{Height: height, Code: defaultCode, Err: err}
+}
+
+// TxError is a failure to process a tx, it carries the tx hash and the type of the offending message (if known) into
+// the failed block record
+type TxError struct {
+	TxHash      string
+	MessageType string
+	Err         error
+}
+
+func (e *TxError) Error() string {
+	if e.MessageType != "" {
+		return fmt.Sprintf("tx %s, message type %s: %v", e.TxHash, e.MessageType, e.Err)
+	}
+	return fmt.Sprintf("tx %s: %v", e.TxHash, e.Err)
+}
+
+func (e *TxError) Unwrap() error {
+	return e.Err
+}
+
+// newMessageError marks the error as caused by a message of the type, the tx hash is added further up
+func newMessageError(messageType string, err error) error {
+	return &TxError{MessageType: messageType, Err: err}
+}
+
+// withTxHash adds the tx hash to the error, keeping the message type if the error already has one
+func withTxHash(txHash string, err error) error {
+	var txErr *TxError
+	if errors.As(err, &txErr) {
+		if txErr.TxHash == "" {
+			txErr.TxHash = txHash
+		}
+		return err
+	}
+	return &TxError{TxHash: txHash, Err: err}
+}
+
+// TxErrorDetails gets the tx hash and message type from the error chain, they are empty if unknown
+func TxErrorDetails(err error) (txHash string, messageType string) {
+	var txErr *TxError
+	if errors.As(err, &txErr) {
+		return txErr.TxHash, txErr.MessageType
+	}
+	return "", ""
+}
+
 type FailedBlockHandler func(height int64, code BlockProcessingFailure, err error)
 
-// Log error to stdout. Not much else we can do to handle right now.
-func HandleFailedBlock(height int64, code BlockProcessingFailure, err error) {
+// HandleFailedBlock logs the failure to stdout. Not much else we can do to handle right now.
+func (p *ChainProcessor) HandleFailedBlock(height int64, code BlockProcessingFailure, err error) {
 	reason := "{unknown error}"
 	switch code {
 	case NodeMissingBlockTxs:
@@ -36,6 +130,8 @@ func HandleFailedBlock(height int64, code BlockProcessingFailure, err error) {
 		reason = "Node has no TX history for block"
 	case FailedBlockEventHandling:
 		reason = "Failed to process block event"
+	case BlockDBWriteError:
+		reason = "Failed to write block to the DB"
 	}
 
 	config.Log.Error(fmt.Sprintf("Block %v failed. Reason: %v", height, reason), err)
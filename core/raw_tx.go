@@ -0,0 +1,80 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/DefiantLabs/cosmos-tax-cli/config"
+	txtypes "github.com/DefiantLabs/cosmos-tax-cli/cosmos/modules/tx"
+	dbTypes "github.com/DefiantLabs/cosmos-tax-cli/db"
+	cosmosTx "github.com/cosmos/cosmos-sdk/types/tx"
+	"gorm.io/gorm"
+)
+
+// newRawTx builds the raw tx record for a tx. txBytes is the protobuf encoded tx, logs are the per message events
+// the parsers were given.
+func (p *ChainProcessor) newRawTx(tx *cosmosTx.Tx, txBytes []byte, gasWanted int64, gasUsed int64, rawLog string, logs []txtypes.LogMessage) (*dbTypes.RawTx, error) {
+	logsJSON, err := json.Marshal(logs)
+	if err != nil {
+		return nil, err
+	}
+
+	rawTx := &dbTypes.RawTx{
+		TxBytes:   txBytes,
+		GasWanted: gasWanted,
+		GasUsed:   gasUsed,
+		RawLog:    rawLog,
+		Logs:      string(logsJSON),
+	}
+	if tx.Body != nil {
+		rawTx.Memo = tx.Body.Memo
+	}
+
+	// The JSON is only for reading, the tx bytes are kept either way so the tx can still be parsed again later
+	txJSON, err := p.cl.Codec.Marshaler.MarshalJSON(tx)
+	if err != nil {
+		config.Log.Warnf("Tx could not be converted to JSON for the raw tx record, only the tx bytes will be stored. Err: %v", err)
+	} else {
+		txJSONStr := string(txJSON)
+		rawTx.TxJSON = &txJSONStr
+	}
+
+	return rawTx, nil
+}
+
+// ReparseRawTx runs a stored raw tx through the current message parsers, the same way it was processed when its block
+// was indexed. Nothing is queried from the chain, so parser fixes can be applied to already indexed txs offline.
+func (p *ChainProcessor) ReparseRawTx(db *gorm.DB, rawTx dbTypes.StoredRawTx) (dbTypes.TxDBWrapper, error) {
+	txFull, err := p.decodeTxBytes(rawTx.TxBytes)
+	if err != nil {
+		return dbTypes.TxDBWrapper{}, fmt.Errorf("raw tx %s cannot be decoded. Err: %v", rawTx.Hash, err)
+	}
+
+	var logs []txtypes.LogMessage
+	if err := json.Unmarshal([]byte(rawTx.Logs), &logs); err != nil {
+		return dbTypes.TxDBWrapper{}, fmt.Errorf("logs of raw tx %s cannot be parsed. Err: %v", rawTx.Hash, err)
+	}
+
+	var indexerMergedTx txtypes.MergedTx
+	indexerMergedTx.Tx.Body.Messages = txFull.GetMsgs()
+	indexerMergedTx.Tx.AuthInfo = *txFull.AuthInfo
+	signers, feePayer := p.signers(txFull)
+	indexerMergedTx.Tx.Signers = signers
+	indexerMergedTx.TxResponse = txtypes.Response{
+		TxHash:    rawTx.Hash,
+		Height:    fmt.Sprintf("%d", rawTx.Height),
+		TimeStamp: rawTx.TimeStamp.Format(time.RFC3339),
+		RawLog:    rawTx.RawLog,
+		Log:       logs,
+		Code:      rawTx.Code,
+	}
+
+	processedTx, _, err := p.ProcessTx(db, indexerMergedTx)
+	if err != nil {
+		return processedTx, err
+	}
+
+	processedTx.SignerAddress = dbTypes.Address{Address: feePayer}
+	return processedTx, nil
+}
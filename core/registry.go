@@ -0,0 +1,184 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/DefiantLabs/cosmos-tax-cli/config"
+	eventTypes "github.com/DefiantLabs/cosmos-tax-cli/cosmos/events"
+	txtypes "github.com/DefiantLabs/cosmos-tax-cli/cosmos/modules/tx"
+	"github.com/DefiantLabs/lens/client"
+	"gorm.io/gorm"
+)
+
+// DenomUpsertFunc downloads the denom metadata of a chain that does not have it available on chain and upserts it in
+// the DB
+type DenomUpsertFunc func(db *gorm.DB, retryMaxAttempts int64, retryMaxWaitSeconds uint64, conf config.AssetList)
+
+// EpochIndexerSetupFunc prepares the registry of a chain for indexing the events of the epochs with the identifier, e.g.
+// by adding handlers that need data looked up on the chain through the client
+type EpochIndexerSetupFunc func(cl *client.ChainClient, registry *Registry, epochIdentifier string) error
+
+// ChainPlugin adds the chain specific parsing of a chain to the registry of the chain. A chain package registers its
+// plugin with RegisterChainPlugin in an init func, so supporting a new chain doesn't require changes to core.
+type ChainPlugin interface {
+	// ChainID is the ID of the chain the plugin is for
+	ChainID() string
+	// Register adds the handlers, ignored message types, denom upsert and epoch indexer setup of the chain to the registry
+	Register(registry *Registry)
+}
+
+var (
+	chainPluginsMu sync.RWMutex
+	chainPlugins   = map[string]ChainPlugin{}
+)
+
+// RegisterChainPlugin makes the plugin available to the chain with its chain ID. It panics if the chain already has a
+// plugin, the same way registering a database driver twice does.
+func RegisterChainPlugin(plugin ChainPlugin) {
+	chainPluginsMu.Lock()
+	defer chainPluginsMu.Unlock()
+
+	if _, ok := chainPlugins[plugin.ChainID()]; ok {
+		panic(fmt.Sprintf("core: chain plugin registered twice for chain %s", plugin.ChainID()))
+	}
+	chainPlugins[plugin.ChainID()] = plugin
+}
+
+// ChainPlugins returns the registered plugins sorted by chain ID
+func ChainPlugins() []ChainPlugin {
+	chainPluginsMu.RLock()
+	defer chainPluginsMu.RUnlock()
+
+	plugins := make([]ChainPlugin, 0, len(chainPlugins))
+	for _, plugin := range chainPlugins {
+		plugins = append(plugins, plugin)
+	}
+	sort.Slice(plugins, func(i, j int) bool { return plugins[i].ChainID() < plugins[j].ChainID() })
+	return plugins
+}
+
+// Registry holds the message handlers, ignored message types, block and epoch event handlers and denom upsert of a
+// single chain. There can be more than one handler for each type, they are tried in the order they were registered.
+type Registry struct {
+	messageTypeHandler               map[string][]func() txtypes.CosmosMessage
+	messageTypeIgnorer               map[string]interface{}
+	beginBlockerEventTypeHandlers    map[string][]func() eventTypes.CosmosEvent
+	endBlockerEventTypeHandlers      map[string][]func() eventTypes.CosmosEvent
+	epochIdentifierEventTypeHandlers map[string]map[string]map[string][]func() eventTypes.CosmosEvent
+	denomUpsert                      DenomUpsertFunc
+	epochIndexerSetup                EpochIndexerSetupFunc
+}
+
+// NewRegistry returns a registry with the generic handlers and ignored message types every chain has
+func NewRegistry() *Registry {
+	r := &Registry{
+		messageTypeHandler:               mergeHandlers(nil, messageTypeHandler),
+		messageTypeIgnorer:               make(map[string]interface{}, len(messageTypeIgnorer)),
+		beginBlockerEventTypeHandlers:    map[string][]func() eventTypes.CosmosEvent{},
+		endBlockerEventTypeHandlers:      map[string][]func() eventTypes.CosmosEvent{},
+		epochIdentifierEventTypeHandlers: map[string]map[string]map[string][]func() eventTypes.CosmosEvent{},
+	}
+	for msgType := range messageTypeIgnorer {
+		r.messageTypeIgnorer[msgType] = nil
+	}
+	return r
+}
+
+// NewChainRegistry returns the registry of the chain, the generic handlers and those of the chain's plugin if it has one
+func NewChainRegistry(chainID string) *Registry {
+	registry := NewRegistry()
+
+	chainPluginsMu.RLock()
+	plugin, ok := chainPlugins[chainID]
+	chainPluginsMu.RUnlock()
+	if ok {
+		plugin.Register(registry)
+	}
+
+	return registry
+}
+
+// AddMessageTypeHandlers registers the handlers BEFORE the handlers already registered for their message types
+func (r *Registry) AddMessageTypeHandlers(handlers map[string][]func() txtypes.CosmosMessage) {
+	r.messageTypeHandler = mergeHandlers(r.messageTypeHandler, handlers)
+}
+
+// IgnoreMessageTypes adds the message types to the types that are ignored for tax purposes
+func (r *Registry) IgnoreMessageTypes(msgTypes ...string) {
+	for _, msgType := range msgTypes {
+		r.messageTypeIgnorer[msgType] = nil
+	}
+}
+
+// AddBeginBlockerEventTypeHandlers registers the handlers BEFORE the handlers already registered for their event types
+func (r *Registry) AddBeginBlockerEventTypeHandlers(handlers map[string][]func() eventTypes.CosmosEvent) {
+	r.beginBlockerEventTypeHandlers = mergeHandlers(r.beginBlockerEventTypeHandlers, handlers)
+}
+
+// AddEndBlockerEventTypeHandlers registers the handlers BEFORE the handlers already registered for their event types
+func (r *Registry) AddEndBlockerEventTypeHandlers(handlers map[string][]func() eventTypes.CosmosEvent) {
+	r.endBlockerEventTypeHandlers = mergeHandlers(r.endBlockerEventTypeHandlers, handlers)
+}
+
+// AddEpochIdentifierEventTypeHandlers registers the handlers of the begin_block and end_block events of epochs, by
+// epoch identifier, BEFORE the handlers already registered for their event types
+func (r *Registry) AddEpochIdentifierEventTypeHandlers(handlers map[string]map[string]map[string][]func() eventTypes.CosmosEvent) {
+	for identifier, phases := range handlers {
+		if _, ok := r.epochIdentifierEventTypeHandlers[identifier]; !ok {
+			r.epochIdentifierEventTypeHandlers[identifier] = map[string]map[string][]func() eventTypes.CosmosEvent{}
+		}
+		for phase, phaseHandlers := range phases {
+			r.epochIdentifierEventTypeHandlers[identifier][phase] = mergeHandlers(r.epochIdentifierEventTypeHandlers[identifier][phase], phaseHandlers)
+		}
+	}
+}
+
+// HasEpochIdentifier is true if event handlers are registered for the epochs with the identifier
+func (r *Registry) HasEpochIdentifier(epochIdentifier string) bool {
+	_, ok := r.epochIdentifierEventTypeHandlers[epochIdentifier]
+	return ok
+}
+
+// SetEpochIndexerSetup sets the setup run before the epoch events of the chain are indexed
+func (r *Registry) SetEpochIndexerSetup(setup EpochIndexerSetupFunc) {
+	r.epochIndexerSetup = setup
+}
+
+// SetupEpochIndexer runs the epoch indexer setup of the chain for the epoch identifier, if the chain has one
+func (r *Registry) SetupEpochIndexer(cl *client.ChainClient, epochIdentifier string) error {
+	if r.epochIndexerSetup == nil {
+		return nil
+	}
+	return r.epochIndexerSetup(cl, r, epochIdentifier)
+}
+
+// SetDenomUpsert sets the download of the denom metadata of the chain
+func (r *Registry) SetDenomUpsert(upsert DenomUpsertFunc) {
+	r.denomUpsert = upsert
+}
+
+// DenomUpsert is the download of the denom metadata of the chain, nil if the chain has its metadata on chain
+func (r *Registry) DenomUpsert() DenomUpsertFunc {
+	return r.denomUpsert
+}
+
+// isIgnored is true if the message type is ignored for tax purposes
+func (r *Registry) isIgnored(msgType string) bool {
+	_, ignored := r.messageTypeIgnorer[msgType]
+	return ignored
+}
+
+// mergeHandlers adds the handlers to the map, registering them BEFORE any handlers the map already has for the type.
+// The map is created if it is nil.
+func mergeHandlers[T any](handlers map[string][]func() T, add map[string][]func() T) map[string][]func() T {
+	if handlers == nil {
+		handlers = make(map[string][]func() T, len(add))
+	}
+	for key, value := range add {
+		// Copied so later merges never append into the backing array of a shared handler list
+		handlers[key] = append(append([]func() T{}, value...), handlers[key]...)
+	}
+	return handlers
+}
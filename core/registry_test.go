@@ -0,0 +1,135 @@
+package core
+
+import (
+	"testing"
+
+	eventTypes "github.com/DefiantLabs/cosmos-tax-cli/cosmos/events"
+	parsingTypes "github.com/DefiantLabs/cosmos-tax-cli/cosmos/modules"
+	txtypes "github.com/DefiantLabs/cosmos-tax-cli/cosmos/modules/tx"
+	"github.com/DefiantLabs/lens/client"
+	abciTypes "github.com/cometbft/cometbft/abci/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/assert"
+)
+
+const testMsgType = "/registry.test.v1.MsgTest"
+
+type testMessage struct{ name string }
+
+func (m *testMessage) HandleMsg(string, sdk.Msg, *txtypes.LogMessage) error { return nil }
+func (m *testMessage) ParseRelevantData() []parsingTypes.MessageRelevantInformation {
+	return nil
+}
+func (m *testMessage) GetType() string { return testMsgType }
+func (m *testMessage) String() string  { return m.name }
+
+type testEvent struct{ name string }
+
+func (e *testEvent) HandleEvent(string, abciTypes.Event) error { return nil }
+func (e *testEvent) ParseRelevantData() []eventTypes.EventRelevantInformation {
+	return nil
+}
+func (e *testEvent) GetType() string { return "transfer" }
+func (e *testEvent) String() string  { return e.name }
+
+func messageHandler(name string) func() txtypes.CosmosMessage {
+	return func() txtypes.CosmosMessage { return &testMessage{name: name} }
+}
+
+func eventHandler(name string) func() eventTypes.CosmosEvent {
+	return func() eventTypes.CosmosEvent { return &testEvent{name: name} }
+}
+
+func handlerNames[T interface{ String() string }](handlers []func() T) []string {
+	names := make([]string, len(handlers))
+	for i, handler := range handlers {
+		names[i] = handler().String()
+	}
+	return names
+}
+
+func TestRegistryHandlersRegisteredBeforeExisting(t *testing.T) {
+	registry := NewRegistry()
+
+	registry.AddMessageTypeHandlers(map[string][]func() txtypes.CosmosMessage{testMsgType: {messageHandler("generic")}})
+	registry.AddMessageTypeHandlers(map[string][]func() txtypes.CosmosMessage{testMsgType: {messageHandler("chain1"), messageHandler("chain2")}})
+	assert.Equal(t, []string{"chain1", "chain2", "generic"}, handlerNames(registry.messageTypeHandler[testMsgType]))
+
+	registry.AddBeginBlockerEventTypeHandlers(map[string][]func() eventTypes.CosmosEvent{"transfer": {eventHandler("first")}})
+	registry.AddBeginBlockerEventTypeHandlers(map[string][]func() eventTypes.CosmosEvent{"transfer": {eventHandler("second")}})
+	assert.Equal(t, []string{"second", "first"}, handlerNames(registry.beginBlockerEventTypeHandlers["transfer"]))
+
+	registry.AddEpochIdentifierEventTypeHandlers(map[string]map[string]map[string][]func() eventTypes.CosmosEvent{
+		"week": {"begin_block": {"transfer": {eventHandler("first")}}},
+	})
+	registry.AddEpochIdentifierEventTypeHandlers(map[string]map[string]map[string][]func() eventTypes.CosmosEvent{
+		"week": {"begin_block": {"transfer": {eventHandler("second")}}},
+	})
+	assert.Equal(t, []string{"second", "first"}, handlerNames(registry.epochIdentifierEventTypeHandlers["week"]["begin_block"]["transfer"]))
+}
+
+func TestMergeHandlersCopiesBackingArray(t *testing.T) {
+	// Spare capacity, appending to the list directly would write into the array the other registries share
+	shared := make([]func() eventTypes.CosmosEvent, 1, 4)
+	shared[0] = eventHandler("shared")
+	add := map[string][]func() eventTypes.CosmosEvent{"transfer": shared}
+
+	first := mergeHandlers(nil, add)
+	second := mergeHandlers(nil, add)
+
+	first = mergeHandlers(first, map[string][]func() eventTypes.CosmosEvent{"transfer": {eventHandler("first")}})
+	second = mergeHandlers(second, map[string][]func() eventTypes.CosmosEvent{"transfer": {eventHandler("second")}})
+	first["transfer"][1] = eventHandler("replaced")
+
+	assert.Equal(t, []string{"first", "replaced"}, handlerNames(first["transfer"]))
+	assert.Equal(t, []string{"second", "shared"}, handlerNames(second["transfer"]))
+	assert.Equal(t, []string{"shared"}, handlerNames(add["transfer"]))
+	assert.Nil(t, shared[:2][1], "nothing is appended into the spare capacity of the shared list")
+}
+
+func TestRegistriesAreIsolated(t *testing.T) {
+	genericHandlers := len(messageTypeHandler)
+
+	chain1 := NewRegistry()
+	chain2 := NewRegistry()
+
+	chain1.AddMessageTypeHandlers(map[string][]func() txtypes.CosmosMessage{testMsgType: {messageHandler("chain1")}})
+	chain1.IgnoreMessageTypes("/registry.test.v1.MsgIgnored")
+	chain1.AddEpochIdentifierEventTypeHandlers(map[string]map[string]map[string][]func() eventTypes.CosmosEvent{
+		"day": {"begin_block": {"transfer": {eventHandler("chain1")}}},
+	})
+
+	assert.Contains(t, chain1.messageTypeHandler, testMsgType)
+	assert.NotContains(t, chain2.messageTypeHandler, testMsgType)
+	assert.True(t, chain1.isIgnored("/registry.test.v1.MsgIgnored"))
+	assert.False(t, chain2.isIgnored("/registry.test.v1.MsgIgnored"))
+	assert.True(t, chain1.HasEpochIdentifier("day"))
+	assert.False(t, chain2.HasEpochIdentifier("day"))
+
+	// A chain handler for a generic message type is only tried by the chain that registered it
+	for msgType, handlers := range messageTypeHandler {
+		chain1.AddMessageTypeHandlers(map[string][]func() txtypes.CosmosMessage{msgType: {messageHandler("chain1")}})
+		assert.Len(t, chain1.messageTypeHandler[msgType], len(handlers)+1)
+		assert.Len(t, chain2.messageTypeHandler[msgType], len(handlers))
+		assert.Len(t, messageTypeHandler[msgType], len(handlers))
+	}
+
+	// The generic handlers the registries start with are not changed by either of them
+	assert.Len(t, messageTypeHandler, genericHandlers)
+	assert.NotContains(t, messageTypeHandler, testMsgType)
+	assert.NotContains(t, messageTypeIgnorer, "/registry.test.v1.MsgIgnored")
+}
+
+func TestRegistryEpochIndexerSetup(t *testing.T) {
+	registry := NewRegistry()
+	assert.Nil(t, registry.SetupEpochIndexer(nil, "week"))
+
+	registry.SetEpochIndexerSetup(func(cl *client.ChainClient, r *Registry, epochIdentifier string) error {
+		r.AddEpochIdentifierEventTypeHandlers(map[string]map[string]map[string][]func() eventTypes.CosmosEvent{
+			epochIdentifier: {"begin_block": {"transfer": {eventHandler("setup")}}},
+		})
+		return nil
+	})
+	assert.Nil(t, registry.SetupEpochIndexer(nil, "week"))
+	assert.Equal(t, []string{"setup"}, handlerNames(registry.epochIdentifierEventTypeHandlers["week"]["begin_block"]["transfer"]))
+}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/DefiantLabs/cosmos-tax-cli/block-sdk/modules/auction"
 	"github.com/DefiantLabs/cosmos-tax-cli/config"
+	parsingTypes "github.com/DefiantLabs/cosmos-tax-cli/cosmos/modules"
 	"github.com/DefiantLabs/cosmos-tax-cli/cosmos/modules/authz"
 	"github.com/DefiantLabs/cosmos-tax-cli/cosmos/modules/bank"
 	"github.com/DefiantLabs/cosmos-tax-cli/cosmos/modules/distribution"
@@ -21,10 +22,8 @@ import (
 	"github.com/DefiantLabs/cosmos-tax-cli/cosmos/modules/staking"
 	txtypes "github.com/DefiantLabs/cosmos-tax-cli/cosmos/modules/tx"
 	"github.com/DefiantLabs/cosmos-tax-cli/cosmos/modules/vesting"
-	"github.com/DefiantLabs/cosmos-tax-cli/cosmwasm"
 	"github.com/DefiantLabs/cosmos-tax-cli/cosmwasm/modules/wasm"
 	dbTypes "github.com/DefiantLabs/cosmos-tax-cli/db"
-	"github.com/DefiantLabs/cosmos-tax-cli/osmosis"
 	"github.com/DefiantLabs/cosmos-tax-cli/osmosis/modules/gamm"
 	"github.com/DefiantLabs/cosmos-tax-cli/osmosis/modules/incentives"
 	"github.com/DefiantLabs/cosmos-tax-cli/osmosis/modules/lockup"
@@ -41,12 +40,14 @@ import (
 	cryptoTypes "github.com/cosmos/cosmos-sdk/crypto/types"
 	"github.com/cosmos/cosmos-sdk/types"
 	cosmosTx "github.com/cosmos/cosmos-sdk/types/tx"
+	authztypes "github.com/cosmos/cosmos-sdk/x/authz"
 	"gorm.io/gorm"
 
 	indexerEvents "github.com/DefiantLabs/cosmos-tax-cli/cosmos/events"
 )
 
 // Unmarshal JSON to a particular type. There can be more than one handler for each type.
+// These are the generic handlers every Registry starts with.
 var messageTypeHandler = map[string][]func() txtypes.CosmosMessage{
 	bank.MsgSend:                                {func() txtypes.CosmosMessage { return &bank.WrapperMsgSend{} }},
 	bank.MsgMultiSend:                           {func() txtypes.CosmosMessage { return &bank.WrapperMsgMultiSend{} }},
@@ -62,6 +63,9 @@ var messageTypeHandler = map[string][]func() txtypes.CosmosMessage{
 	staking.MsgBeginRedelegate:                  {func() txtypes.CosmosMessage { return &staking.WrapperMsgBeginRedelegate{} }},
 	ibc.MsgRecvPacket:                           {func() txtypes.CosmosMessage { return &ibc.WrapperMsgRecvPacket{} }},
 	ibc.MsgAcknowledgement:                      {func() txtypes.CosmosMessage { return &ibc.WrapperMsgAcknowledgement{} }},
+	ibc.MsgTransfer:                             {func() txtypes.CosmosMessage { return &ibc.WrapperMsgTransfer{} }},
+	ibc.MsgTimeout:                              {func() txtypes.CosmosMessage { return &ibc.WrapperMsgTimeout{} }},
+	ibc.MsgTimeoutOnClose:                       {func() txtypes.CosmosMessage { return &ibc.WrapperMsgTimeout{} }},
 	// Support is not fully built out for this message parser
 	// auction.MsgAuctionBid:                       {func() txtypes.CosmosMessage { return &auction.WrapperMsgAuctionBid{} }},
 }
@@ -73,7 +77,7 @@ var messageTypeIgnorer = map[string]interface{}{
 	/////// Nontaxable Events ///////
 	/////////////////////////////////
 	// Authz module actions are not taxable
-	authz.MsgExec:   nil,
+	authz.MsgExec:   nil, // inner messages are parsed separately, see processMsgExec
 	authz.MsgGrant:  nil,
 	authz.MsgRevoke: nil,
 
@@ -81,7 +85,7 @@ var messageTypeIgnorer = map[string]interface{}{
 	auction.MsgUpdateParams: nil,
 	auction.MsgAuctionBid:   nil,
 
-	// Making a config change is not taxable
+	// Making a config change is not taxable, rewards withdrawn later are credited to the withdraw address it sets
 	distribution.MsgSetWithdrawAddress: nil,
 	// Making a stableswap config change is not taxable
 	gamm.MsgStableSwapAdjustScalingFactors: nil,
@@ -91,10 +95,7 @@ var messageTypeIgnorer = map[string]interface{}{
 	gov.MsgVoteV1:         nil,
 	gov.MsgVoteWeightedV1: nil,
 	// The IBC msgs below do not create taxable events
-	ibc.MsgTransfer:              nil,
 	ibc.MsgUpdateClient:          nil,
-	ibc.MsgTimeout:               nil,
-	ibc.MsgTimeoutOnClose:        nil,
 	ibc.MsgCreateClient:          nil,
 	ibc.MsgConnectionOpenTry:     nil,
 	ibc.MsgConnectionOpenConfirm: nil,
@@ -187,39 +188,8 @@ var messageTypeIgnorer = map[string]interface{}{
 	wasm.MsgStoreAndMigrateContract:         nil,
 }
 
-// Merge the chain specific message type handlers into the core message type handler map.
-// Chain specific handlers will be registered BEFORE any generic handlers.
-func ChainSpecificMessageTypeHandlerBootstrap(chainID string, lensClient *client.ChainClient) {
-	var customContractAddressHandlers []wasm.ContractExecutionMessageHandler
-	var chainSpecificMessageTpeHandler map[string][]func() txtypes.CosmosMessage
-	if chainID == osmosis.ChainID {
-		chainSpecificMessageTpeHandler = osmosis.MessageTypeHandler
-	}
-
-	for key, value := range chainSpecificMessageTpeHandler {
-		if list, ok := messageTypeHandler[key]; ok {
-			messageTypeHandler[key] = append(value, list...)
-		} else {
-			messageTypeHandler[key] = value
-		}
-	}
-
-	cosmWasmHandlers, err := cosmwasm.GetCosmWasmMessageTypeHandlers(customContractAddressHandlers, lensClient)
-	if err != nil {
-		config.Log.Fatal("Error getting CosmWasm message type handlers.", err)
-	}
-
-	for key, value := range cosmWasmHandlers {
-		if list, ok := messageTypeHandler[key]; ok {
-			messageTypeHandler[key] = append(value, list...)
-		} else {
-			messageTypeHandler[key] = value
-		}
-	}
-}
-
 // ParseCosmosMessageJSON - Parse a SINGLE Cosmos Message into the appropriate type.
-func ParseCosmosMessage(message types.Msg, log txtypes.LogMessage) (txtypes.CosmosMessage, string, error) {
+func (p *ChainProcessor) ParseCosmosMessage(message types.Msg, log txtypes.LogMessage) (txtypes.CosmosMessage, string, error) {
 	var ok bool
 	var err error
 	var msgHandler txtypes.CosmosMessage
@@ -228,10 +198,21 @@ func ParseCosmosMessage(message types.Msg, log txtypes.LogMessage) (txtypes.Cosm
 	// Figure out what type of Message this is based on the '@type' field that is included
 	// in every Cosmos Message (can be seen in raw JSON for any cosmos transaction).
 	cosmosMessage := txtypes.Message{}
-	cosmosMessage.Type = types.MsgTypeURL(message)
+	cosmosMessage.Type = msgTypeURL(message)
 
 	// So far we only parsed the '@type' field. Now we get a struct for that specific type.
-	if handlerList, ok = messageTypeHandler[cosmosMessage.Type]; !ok {
+	if handlerList, ok = p.registry.messageTypeHandler[cosmosMessage.Type]; !ok {
+		// Without a parser, the funds the message moved can still be inferred from its bank events
+		if p.useBankEventFallback(cosmosMessage.Type) {
+			fallback := &bank.WrapperBankEvents{}
+			err = fallback.HandleMsg(cosmosMessage.Type, message, &log)
+			if err == nil {
+				return fallback, cosmosMessage.Type, nil
+			}
+			if err != bank.ErrNoBankEvents {
+				config.Log.Warnf("Bank events of msg of type '%v' could not be parsed. Err: %v", cosmosMessage.Type, err)
+			}
+		}
 		return nil, cosmosMessage.Type, txtypes.ErrUnknownMessage
 	}
 
@@ -273,9 +254,51 @@ func getUnexportedField(field reflect.Value) interface{} {
 	return reflect.NewAt(field.Type(), unsafe.Pointer(field.UnsafeAddr())).Elem().Interface()
 }
 
-func ProcessRPCBlockByHeightTXs(db *gorm.DB, cl *client.ChainClient, blockResults *coretypes.ResultBlock, resultBlockRes *coretypes.ResultBlockResults) ([]dbTypes.TxDBWrapper, *time.Time, error) {
+// decodeTx decodes a protobuf encoded tx into the full SDK tx
+func decodeTx(cl *client.ChainClient, txBytes []byte) (*cosmosTx.Tx, error) {
+	txDecoder := cl.Codec.TxConfig.TxDecoder()
+	txBasic, err := txDecoder(txBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	// This is a hack, but as far as I can tell necessary. "wrapper" struct is private in Cosmos SDK.
+	field := reflect.ValueOf(txBasic).Elem().FieldByName("tx")
+	iTx := getUnexportedField(field)
+	return iTx.(*cosmosTx.Tx), nil
+}
+
+// CountBlockMessages counts the top level messages of the successful txs in a block, which are the messages the
+// indexer stores for the block
+func CountBlockMessages(cl *client.ChainClient, block *coretypes.ResultBlock, blockResults *coretypes.ResultBlockResults) (int, error) {
+	if len(block.Block.Txs) != len(blockResults.TxsResults) {
+		return 0, fmt.Errorf("block & block results: different length for block %v", block.Block.Height)
+	}
+
+	count := 0
+	for txIdx, tendermintTx := range block.Block.Txs {
+		// Messages of failed txs are not stored
+		if blockResults.TxsResults[txIdx].Code != 0 {
+			continue
+		}
+
+		txFull, err := decodeTx(cl, tendermintTx)
+		if isUnresolvableTypeURL(err) {
+			// Messages of unknown types are still stored, as unparsed messages
+			txFull, err = decodeTxLeniently(cl, tendermintTx)
+		}
+		if err != nil {
+			return 0, fmt.Errorf("TX cannot be parsed from block %v. Err: %v", block.Block.Height, err)
+		}
+		count += len(txFull.GetMsgs())
+	}
+
+	return count, nil
+}
+
+func (p *ChainProcessor) ProcessRPCBlockByHeightTXs(db *gorm.DB, blockResults *coretypes.ResultBlock, resultBlockRes *coretypes.ResultBlockResults) ([]dbTypes.TxDBWrapper, *time.Time, error) {
 	if len(blockResults.Block.Txs) != len(resultBlockRes.TxsResults) {
-		config.Log.Fatalf("blockResults & resultBlockRes: different length")
+		return nil, nil, fmt.Errorf("blockResults & resultBlockRes: different length for block %v", blockResults.Block.Height)
 	}
 
 	blockTime := &blockResults.Block.Time
@@ -292,16 +315,10 @@ func ProcessRPCBlockByHeightTXs(db *gorm.DB, cl *client.ChainClient, blockResult
 		var currMessages []types.Msg
 		var currLogMsgs []txtypes.LogMessage
 
-		txDecoder := cl.Codec.TxConfig.TxDecoder()
-		txBasic, err := txDecoder(tendermintTx)
+		txFull, err := p.decodeTxBytes(tendermintTx)
 		if err != nil {
 			return nil, blockTime, fmt.Errorf("ProcessRPCBlockByHeightTXs: TX cannot be parsed from block %v. Err: %v", blockResults.Block.Height, err)
 		}
-
-		// This is a hack, but as far as I can tell necessary. "wrapper" struct is private in Cosmos SDK.
-		field := reflect.ValueOf(txBasic).Elem().FieldByName("tx")
-		iTx := getUnexportedField(field)
-		txFull := iTx.(*cosmosTx.Tx)
 		logs := types.ABCIMessageLogs{}
 
 		// Failed TXs do not have proper JSON in the .Log field, causing ParseABCILogs to fail to unmarshal the logs
@@ -350,18 +367,25 @@ func ProcessRPCBlockByHeightTXs(db *gorm.DB, cl *client.ChainClient, blockResult
 			Code:      txResult.Code,
 		}
 
+		signers, feePayer := p.signers(txFull)
 		indexerTx.AuthInfo = *txFull.AuthInfo
-		indexerTx.Signers = txFull.GetSigners()
+		indexerTx.Signers = signers
 		indexerMergedTx.TxResponse = indexerTxResp
 		indexerMergedTx.Tx = indexerTx
 		indexerMergedTx.Tx.AuthInfo = *txFull.AuthInfo
 
-		processedTx, _, err := ProcessTx(cl, db, indexerMergedTx)
+		processedTx, _, err := p.ProcessTx(db, indexerMergedTx)
 		if err != nil {
 			return currTxDbWrappers, blockTime, err
 		}
 
-		processedTx.SignerAddress = dbTypes.Address{Address: txFull.FeePayer().String()}
+		processedTx.SignerAddress = dbTypes.Address{Address: feePayer}
+		if p.storeRawTxs {
+			processedTx.RawTx, err = p.newRawTx(txFull, tendermintTx, txResult.GasWanted, txResult.GasUsed, txResult.Log, currLogMsgs)
+			if err != nil {
+				return currTxDbWrappers, blockTime, err
+			}
+		}
 		currTxDbWrappers[txIdx] = processedTx
 	}
 
@@ -369,7 +393,7 @@ func ProcessRPCBlockByHeightTXs(db *gorm.DB, cl *client.ChainClient, blockResult
 }
 
 // ProcessRPCTXs - Given an RPC response, build out the more specific data used by the parser.
-func ProcessRPCTXs(db *gorm.DB, cl *client.ChainClient, txEventResp *cosmosTx.GetTxsEventResponse) ([]dbTypes.TxDBWrapper, *time.Time, error) {
+func (p *ChainProcessor) ProcessRPCTXs(db *gorm.DB, txEventResp *cosmosTx.GetTxsEventResponse) ([]dbTypes.TxDBWrapper, *time.Time, error) {
 	currTxDbWrappers := make([]dbTypes.TxDBWrapper, len(txEventResp.Txs))
 	var blockTime *time.Time
 
@@ -429,13 +453,14 @@ func ProcessRPCTXs(db *gorm.DB, cl *client.ChainClient, txEventResp *cosmosTx.Ge
 			Code:      currTxResp.Code,
 		}
 
+		signers, feePayer := p.signers(currTx)
 		indexerTx.AuthInfo = *currTx.AuthInfo
-		indexerTx.Signers = currTx.GetSigners()
+		indexerTx.Signers = signers
 		indexerMergedTx.TxResponse = indexerTxResp
 		indexerMergedTx.Tx = indexerTx
 		indexerMergedTx.Tx.AuthInfo = *currTx.AuthInfo
 
-		processedTx, txTime, err := ProcessTx(cl, db, indexerMergedTx)
+		processedTx, txTime, err := p.ProcessTx(db, indexerMergedTx)
 		if err != nil {
 			return currTxDbWrappers, blockTime, err
 		}
@@ -444,7 +469,17 @@ func ProcessRPCTXs(db *gorm.DB, cl *client.ChainClient, txEventResp *cosmosTx.Ge
 			blockTime = &txTime
 		}
 
-		processedTx.SignerAddress = dbTypes.Address{Address: currTx.FeePayer().String()}
+		processedTx.SignerAddress = dbTypes.Address{Address: feePayer}
+		if p.storeRawTxs {
+			txBytes, err := currTx.Marshal()
+			if err != nil {
+				return currTxDbWrappers, blockTime, err
+			}
+			processedTx.RawTx, err = p.newRawTx(currTx, txBytes, currTxResp.GasWanted, currTxResp.GasUsed, currTxResp.RawLog, currLogMsgs)
+			if err != nil {
+				return currTxDbWrappers, blockTime, err
+			}
+		}
 		currTxDbWrappers[txIdx] = processedTx
 	}
 
@@ -479,7 +514,13 @@ func AnalyzeSwaps() {
 	fmt.Printf("Profit (OSMO): %.10f, days: %f\n", profit, latestTime.Sub(earliestTime).Hours()/24)
 }
 
-func ProcessTx(cl *client.ChainClient, db *gorm.DB, tx txtypes.MergedTx) (txDBWapper dbTypes.TxDBWrapper, txTime time.Time, err error) {
+func (p *ChainProcessor) ProcessTx(db *gorm.DB, tx txtypes.MergedTx) (txDBWapper dbTypes.TxDBWrapper, txTime time.Time, err error) {
+	defer func() {
+		if err != nil {
+			err = withTxHash(tx.TxResponse.TxHash, err)
+		}
+	}()
+
 	txTime, err = time.Parse(time.RFC3339, tx.TxResponse.TimeStamp)
 	if err != nil {
 		config.Log.Error("Error parsing tx timestamp.", err)
@@ -500,7 +541,7 @@ func ProcessTx(cl *client.ChainClient, db *gorm.DB, tx txtypes.MergedTx) (txDBWa
 			// Get the message log that corresponds to the current message
 			var currMessageDBWrapper dbTypes.MessageDBWrapper
 			messageLog := txtypes.GetMessageLogForIndex(tx.TxResponse.Log, messageIndex)
-			cosmosMessage, msgType, err := ParseCosmosMessage(message, *messageLog)
+			cosmosMessage, msgType, err := p.ParseCosmosMessage(message, *messageLog)
 			if err != nil {
 				currMessageType.MessageType = msgType
 				currMessage.MessageType = currMessageType
@@ -511,13 +552,16 @@ func ProcessTx(cl *client.ChainClient, db *gorm.DB, tx txtypes.MergedTx) (txDBWa
 					config.Log.Error(fmt.Sprint(messageLog))
 					config.Log.Error(tx.TxResponse.TxHash)
 					config.Log.Error("Issue parsing a cosmos msg that we DO have a parser for! PLEASE INVESTIGATE")
-					return txDBWapper, txTime, fmt.Errorf("error parsing message we have a parser for: '%v'", msgType)
+					return txDBWapper, txTime, newMessageError(msgType, fmt.Errorf("error parsing message we have a parser for: '%v'", msgType))
 				}
 				// if this msg isn't include in our list of those we are explicitly ignoring, do something about it.
-				// we have decided to throw the error back up the call stack, which will prevent any indexing from happening on this block and add this to the failed block table
-				if _, ok := messageTypeIgnorer[msgType]; !ok {
-					config.Log.Error(fmt.Sprintf("[Block: %v] ParseCosmosMessage failed for msg of type '%v'. Missing parser and ignore list entry.", tx.TxResponse.Height, msgType))
-					return txDBWapper, txTime, fmt.Errorf("missing parser and ignore list entry for msg type '%v'", msgType)
+				// Unless unknown messages are stored as unparsed messages, the error is thrown back up the call stack,
+				// which will prevent any indexing from happening on this block and add this to the failed block table
+				if !p.registry.isIgnored(msgType) {
+					currMessageDBWrapper.Unparsed, err = p.handleUnknownMessage(tx.TxResponse.Height, message, msgType)
+					if err != nil {
+						return txDBWapper, txTime, err
+					}
 				}
 			} else {
 				config.Log.Debug(fmt.Sprintf("[Block: %v] Cosmos message of known type: %s", tx.TxResponse.Height, cosmosMessage))
@@ -525,55 +569,12 @@ func ProcessTx(cl *client.ChainClient, db *gorm.DB, tx txtypes.MergedTx) (txDBWa
 				currMessage.MessageType = currMessageType
 				currMessageDBWrapper.Message = currMessage
 
-				relevantData := cosmosMessage.ParseRelevantData()
-
-				if len(relevantData) > 0 {
-					taxableTxs := make([]dbTypes.TaxableTxDBWrapper, len(relevantData))
-					for i, v := range relevantData {
-						if v.AmountSent != nil {
-							taxableTxs[i].TaxableTx.AmountSent = util.ToNumeric(v.AmountSent)
-						}
-						if v.AmountReceived != nil {
-							taxableTxs[i].TaxableTx.AmountReceived = util.ToNumeric(v.AmountReceived)
-						}
-
-						if v.DenominationSent != "" {
-							denomSent, err := getDenom(v.DenominationSent)
-							if err != nil {
-								// attempt to add missing denoms to the database
-								config.Log.Warnf("Denom lookup failed. Will be inserted as UNKNOWN. Denom Sent: %v. Err: %v", denomSent.Base, err)
-								denomSent, err = dbTypes.AddUnknownDenom(db, denomSent.Base)
-								if err != nil {
-									config.Log.Error(fmt.Sprintf("There was an error adding a missing denom. Denom sent: %v", denomSent.Base), err)
-									return txDBWapper, txTime, err
-								}
-							}
-
-							taxableTxs[i].TaxableTx.DenominationSent = denomSent
-						}
-
-						if v.DenominationReceived != "" {
-							denomReceived, err := getDenom(v.DenominationReceived)
-							if err != nil {
-								// attempt to add missing denoms to the database
-								config.Log.Warnf("Denom lookup failed. Will be inserted as UNKNOWN. Denom Received: %v. Err: %v", denomReceived.Base, err)
-								denomReceived, err = dbTypes.AddUnknownDenom(db, denomReceived.Base)
-								if err != nil {
-									config.Log.Error(fmt.Sprintf("There was an error adding a missing denom. Denom received: %v", denomReceived.Base), err)
-									return txDBWapper, txTime, err
-								}
-							}
-
-							taxableTxs[i].TaxableTx.DenominationReceived = denomReceived
-						}
-
-						taxableTxs[i].SenderAddress = dbTypes.Address{Address: strings.ToLower(v.SenderAddress)}
-						taxableTxs[i].ReceiverAddress = dbTypes.Address{Address: strings.ToLower(v.ReceiverAddress)}
-					}
-					currMessageDBWrapper.TaxableTxs = taxableTxs
-				} else {
-					currMessageDBWrapper.TaxableTxs = []dbTypes.TaxableTxDBWrapper{}
+				taxableTxs, err := p.toTaxableTxDBWrappers(db, cosmosMessage.ParseRelevantData())
+				if err != nil {
+					return txDBWapper, txTime, newMessageError(currMessageType.MessageType, err)
 				}
+				currMessageDBWrapper.TaxableTxs = taxableTxs
+				currMessageDBWrapper.IBCPacket = toIBCPacket(cosmosMessage)
 			}
 
 			if msgSwapExactIn, ok := cosmosMessage.(*gamm.WrapperMsgSwapExactAmountIn); ok {
@@ -581,10 +582,19 @@ func ProcessTx(cl *client.ChainClient, db *gorm.DB, tx txtypes.MergedTx) (txDBWa
 				allSwaps = append(allSwaps, newSwap)
 			}
 			messages = append(messages, currMessageDBWrapper)
+
+			// The messages executed on behalf of a granter are indexed as their own messages alongside the MsgExec
+			if msgExec, ok := message.(*authztypes.MsgExec); ok {
+				execMessages, err := p.processMsgExec(db, tx.TxResponse.Height, msgExec, messageLog, messageIndex, "")
+				if err != nil {
+					return txDBWapper, txTime, err
+				}
+				messages = append(messages, execMessages...)
+			}
 		}
 	}
 
-	fees, err := ProcessFees(cl, db, tx.Tx.AuthInfo, tx.Tx.Signers)
+	fees, err := p.ProcessFees(db, tx.Tx.AuthInfo, tx.Tx.Signers)
 	if err != nil {
 		return txDBWapper, txTime, err
 	}
@@ -595,8 +605,92 @@ func ProcessTx(cl *client.ChainClient, db *gorm.DB, tx txtypes.MergedTx) (txDBWa
 	return txDBWapper, txTime, nil
 }
 
+// toTaxableTxDBWrappers converts the relevant data of a parsed message into taxable txs, looking up (or creating) the denoms
+func (p *ChainProcessor) toTaxableTxDBWrappers(db *gorm.DB, relevantData []parsingTypes.MessageRelevantInformation) ([]dbTypes.TaxableTxDBWrapper, error) {
+	taxableTxs := make([]dbTypes.TaxableTxDBWrapper, len(relevantData))
+	for i, v := range relevantData {
+		if v.AmountSent != nil {
+			taxableTxs[i].TaxableTx.AmountSent = util.ToNumeric(v.AmountSent)
+		}
+		if v.AmountReceived != nil {
+			taxableTxs[i].TaxableTx.AmountReceived = util.ToNumeric(v.AmountReceived)
+		}
+
+		if v.DenominationSent != "" {
+			denomSent, err := p.getDenom(v.DenominationSent)
+			if err != nil {
+				// attempt to add missing denoms to the database
+				config.Log.Warnf("Denom lookup failed. Will be inserted as UNKNOWN. Denom Sent: %v. Err: %v", denomSent.Base, err)
+				denomSent, err = p.denoms.AddUnknownDenom(db, denomSent.Base)
+				if err != nil {
+					config.Log.Error(fmt.Sprintf("There was an error adding a missing denom. Denom sent: %v", denomSent.Base), err)
+					return nil, err
+				}
+			}
+
+			taxableTxs[i].TaxableTx.DenominationSent = denomSent
+		}
+
+		if v.DenominationReceived != "" {
+			denomReceived, err := p.getDenom(v.DenominationReceived)
+			if err != nil {
+				// attempt to add missing denoms to the database
+				config.Log.Warnf("Denom lookup failed. Will be inserted as UNKNOWN. Denom Received: %v. Err: %v", denomReceived.Base, err)
+				denomReceived, err = p.denoms.AddUnknownDenom(db, denomReceived.Base)
+				if err != nil {
+					config.Log.Error(fmt.Sprintf("There was an error adding a missing denom. Denom received: %v", denomReceived.Base), err)
+					return nil, err
+				}
+			}
+
+			taxableTxs[i].TaxableTx.DenominationReceived = denomReceived
+		}
+
+		taxableTxs[i].TaxableTx.Inferred = v.Inferred
+		taxableTxs[i].SenderAddress = dbTypes.Address{Address: strings.ToLower(v.SenderAddress)}
+		taxableTxs[i].ReceiverAddress = dbTypes.Address{Address: strings.ToLower(v.ReceiverAddress)}
+	}
+
+	return taxableTxs, nil
+}
+
+// firstSignerAddress derives the address of the first signer of the tx from its public key. It is empty if the
+// address can't be parsed from the key.
+func (p *ChainProcessor) firstSignerAddress(authInfo cosmosTx.AuthInfo) (string, error) {
+	if len(authInfo.SignerInfos) == 0 || authInfo.SignerInfos[0].PublicKey == nil {
+		return "", nil
+	}
+
+	var pubKey cryptoTypes.PubKey
+
+	pubKeyType, err := p.cl.Codec.InterfaceRegistry.Resolve(authInfo.SignerInfos[0].PublicKey.TypeUrl)
+	if err != nil {
+		return "", err
+	}
+	err = p.cl.Codec.InterfaceRegistry.UnpackAny(authInfo.SignerInfos[0].PublicKey, &pubKeyType)
+	if err != nil {
+		return "", err
+	}
+
+	multisigKey, ok := pubKeyType.(*multisig.LegacyAminoPubKey)
+
+	if ok {
+		pubKey = multisigKey.GetPubKeys()[0]
+	} else {
+		pubKey = pubKeyType.(cryptoTypes.PubKey)
+	}
+
+	hexPub := hex.EncodeToString(pubKey.Bytes())
+	bechAddr, err := ParseSignerAddress(hexPub, "", p.addressPrefix)
+	if err != nil {
+		config.Log.Error(fmt.Sprintf("Error parsing signer address '%v' for tx.", hexPub), err)
+		return "", nil
+	}
+	return bechAddr, nil
+}
+
 // ProcessFees returns a comma delimited list of fee amount/denoms
-func ProcessFees(cl *client.ChainClient, db *gorm.DB, authInfo cosmosTx.AuthInfo, signers []types.AccAddress) ([]dbTypes.Fee, error) {
+func (p *ChainProcessor) ProcessFees(db *gorm.DB, authInfo cosmosTx.AuthInfo, signers []types.AccAddress) ([]dbTypes.Fee, error) {
 	feeCoins := authInfo.Fee.Amount
 	payer := authInfo.Fee.GetPayer()
 	fees := []dbTypes.Fee{}
@@ -607,11 +701,11 @@ func ProcessFees(cl *client.ChainClient, db *gorm.DB, authInfo cosmosTx.AuthInfo
 		// There are chains like Osmosis that do not require TX fees for certain TXs
 		if zeroFee.Cmp(coin.Amount.BigInt()) != 0 {
 			amount := util.ToNumeric(coin.Amount.BigInt())
-			denom, err := dbTypes.GetDenomForBase(coin.Denom)
+			denom, err := p.denoms.GetDenomForBase(coin.Denom)
 			if err != nil {
 				// attempt to add missing denoms to the database
 				config.Log.Warnf("Denom lookup failed. Will be inserted as UNKNOWN. Denom Received: %v. Err: %v", coin.Denom, err)
-				denom, err = dbTypes.AddUnknownDenom(db, coin.Denom)
+				denom, err = p.denoms.AddUnknownDenom(db, coin.Denom)
 				if err != nil {
 					config.Log.Error(fmt.Sprintf("There was an error adding a missing denom. Denom: %v", coin.Denom), err)
 					return nil, err
@@ -622,34 +716,13 @@ func ProcessFees(cl *client.ChainClient, db *gorm.DB, authInfo cosmosTx.AuthInfo
 				payerAddr.Address = payer
 			} else {
 				if authInfo.SignerInfos[0].PublicKey == nil && len(signers) > 0 {
-					payerAddr.Address = signers[0].String()
+					payerAddr.Address = types.MustBech32ifyAddressBytes(p.addressPrefix, signers[0])
 				} else {
-					var pubKey cryptoTypes.PubKey
-
-					pubKeyType, err := cl.Codec.InterfaceRegistry.Resolve(authInfo.SignerInfos[0].PublicKey.TypeUrl)
-					if err != nil {
-						return nil, err
-					}
-					err = cl.Codec.InterfaceRegistry.UnpackAny(authInfo.SignerInfos[0].PublicKey, &pubKeyType)
+					bechAddr, err := p.firstSignerAddress(authInfo)
 					if err != nil {
 						return nil, err
 					}
-
-					multisigKey, ok := pubKeyType.(*multisig.LegacyAminoPubKey)
-
-					if ok {
-						pubKey = multisigKey.GetPubKeys()[0]
-					} else {
-						pubKey = pubKeyType.(cryptoTypes.PubKey)
-					}
-
-					hexPub := hex.EncodeToString(pubKey.Bytes())
-					bechAddr, err := ParseSignerAddress(hexPub, "")
-					if err != nil {
-						config.Log.Error(fmt.Sprintf("Error parsing signer address '%v' for tx.", hexPub), err)
-					} else {
-						payerAddr.Address = bechAddr
-					}
+					payerAddr.Address = bechAddr
 				}
 			}
 
@@ -663,7 +736,7 @@ func ProcessFees(cl *client.ChainClient, db *gorm.DB, authInfo cosmosTx.AuthInfo
 // getDenom handles denom processing for both IBC denoms and native denoms.
 // If the denom begins with ibc/ we know this is an IBC denom trace, and it's not guaranteed there is an entry in
 // the Denom table.
-func getDenom(denom string) (dbTypes.Denom, error) {
+func (p *ChainProcessor) getDenom(denom string) (dbTypes.Denom, error) {
 	var (
 		denomSent dbTypes.Denom
 		err       error
@@ -671,11 +744,11 @@ func getDenom(denom string) (dbTypes.Denom, error) {
 
 	// if this is an ibc denom trace, get the ibc denom then use the base denom to get the Denom from the db
 	if strings.HasPrefix(denom, "ibc/") {
-		ibcDenom, err := dbTypes.GetIBCDenom(denom)
+		ibcDenom, err := p.denoms.GetIBCDenom(denom)
 		if err != nil {
 			config.Log.Warnf("IBC Denom lookup failed for  %s, err: %v", denom, err)
 		} else {
-			denomSent, err = dbTypes.GetDenomForBase(ibcDenom.BaseDenom)
+			denomSent, err = p.denoms.GetDenomForBase(ibcDenom.BaseDenom)
 			if err != nil {
 				config.Log.Warnf("Denom lookup failed for IBC base denom %s, err: %v", ibcDenom.BaseDenom, err)
 				return dbTypes.Denom{Base: ibcDenom.BaseDenom}, err
@@ -686,7 +759,7 @@ func getDenom(denom string) (dbTypes.Denom, error) {
 	// if this is not an ibc denom trace or there was an issue querying the ibc denom trace in the other table,
 	// attempt to look up this denom in the regular Denom table
 	if denomSent.Base == "" {
-		denomSent, err = dbTypes.GetDenomForBase(denom)
+		denomSent, err = p.denoms.GetDenomForBase(denom)
 		if err != nil {
 			return dbTypes.Denom{Base: denom}, err
 		}
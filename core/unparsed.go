@@ -0,0 +1,127 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/DefiantLabs/cosmos-tax-cli/config"
+	dbTypes "github.com/DefiantLabs/cosmos-tax-cli/db"
+	"github.com/DefiantLabs/lens/client"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/cosmos/cosmos-sdk/types"
+	cosmosTx "github.com/cosmos/cosmos-sdk/types/tx"
+)
+
+// handleUnknownMessage is called for a message that has neither a parser nor an ignore list entry. In strict mode it
+// returns the error that fails the block, in lenient mode it returns the unparsed message record to store instead.
+func (p *ChainProcessor) handleUnknownMessage(height string, msg types.Msg, msgType string) (*dbTypes.UnparsedMessage, error) {
+	if !p.lenientUnknownMessages {
+		config.Log.Error(fmt.Sprintf("[Block: %v] ParseCosmosMessage failed for msg of type '%v'. Missing parser and ignore list entry.", height, msgType))
+		return nil, newMessageError(msgType, fmt.Errorf("missing parser and ignore list entry for msg type '%v'", msgType))
+	}
+
+	config.Log.Warnf("[Block: %v] No parser for msg of type '%v', storing it as an unparsed message.", height, msgType)
+
+	return p.newUnparsedMessage(msg, msgType), nil
+}
+
+// newUnparsedMessage returns the unparsed message record of a message, with the message as JSON if it can be converted
+func (p *ChainProcessor) newUnparsedMessage(msg types.Msg, msgType string) *dbTypes.UnparsedMessage {
+	if unresolved, ok := msg.(*unresolvedMsg); ok {
+		return &dbTypes.UnparsedMessage{TypeURL: unresolved.typeURL, MessageBytes: unresolved.value}
+	}
+
+	unparsed := &dbTypes.UnparsedMessage{TypeURL: msgType}
+	msgAny, err := codectypes.NewAnyWithValue(msg)
+	if err == nil {
+		unparsed.MessageBytes = msgAny.Value
+	}
+
+	// The JSON is only for reading, the message can still be parsed again from the raw tx if those are stored
+	msgJSON, err := p.cl.Codec.Marshaler.MarshalInterfaceJSON(msg)
+	if err != nil {
+		config.Log.Warnf("Msg of type '%v' could not be converted to JSON for the unparsed message record. Err: %v", msgType, err)
+	} else {
+		msgJSONStr := string(msgJSON)
+		unparsed.MessageJSON = &msgJSONStr
+	}
+
+	return unparsed
+}
+
+// isUnresolvableTypeURL is true if decoding failed because the codec has no type registered for a type URL, e.g. a
+// message type added by a chain upgrade. The tx decoder and the interface registry word the error differently.
+func isUnresolvableTypeURL(err error) bool {
+	if err == nil {
+		return false
+	}
+	return strings.Contains(err.Error(), "unable to resolve type URL") ||
+		strings.Contains(err.Error(), "no concrete type registered for type URL")
+}
+
+// unresolvedMsg stands in for a message of a type the codec can't resolve, so the rest of the tx (its fees and its other
+// messages) can still be processed. It is stored as an unparsed message with the type URL and bytes of the message.
+type unresolvedMsg struct {
+	typeURL string
+	value   []byte
+}
+
+func (m *unresolvedMsg) Reset()                         { *m = unresolvedMsg{} }
+func (m *unresolvedMsg) String() string                 { return m.typeURL }
+func (m *unresolvedMsg) ProtoMessage()                  {}
+func (m *unresolvedMsg) ValidateBasic() error           { return nil }
+func (m *unresolvedMsg) GetSigners() []types.AccAddress { return nil }
+
+// msgTypeURL is the type URL of the message, the type URL it had in the tx for an unresolvedMsg
+func msgTypeURL(msg types.Msg) string {
+	if unresolved, ok := msg.(*unresolvedMsg); ok {
+		return unresolved.typeURL
+	}
+	return types.MsgTypeURL(msg)
+}
+
+// decodeTxLeniently decodes a tx that decodeTx could not decode because of unresolvable type URLs. The messages the
+// codec can resolve are decoded as usual, the others are replaced by an unresolvedMsg.
+func decodeTxLeniently(cl *client.ChainClient, txBytes []byte) (*cosmosTx.Tx, error) {
+	var raw cosmosTx.TxRaw
+	if err := raw.Unmarshal(txBytes); err != nil {
+		return nil, err
+	}
+
+	// Unmarshalling only reads the type URLs and bytes of the Anys, they are resolved below
+	var body cosmosTx.TxBody
+	if err := body.Unmarshal(raw.BodyBytes); err != nil {
+		return nil, err
+	}
+	var authInfo cosmosTx.AuthInfo
+	if err := authInfo.Unmarshal(raw.AuthInfoBytes); err != nil {
+		return nil, err
+	}
+	if err := authInfo.UnpackInterfaces(cl.Codec.InterfaceRegistry); err != nil {
+		return nil, err
+	}
+
+	for i, msgAny := range body.Messages {
+		var msg types.Msg
+		if err := cl.Codec.InterfaceRegistry.UnpackAny(msgAny, &msg); err != nil {
+			if !isUnresolvableTypeURL(err) {
+				return nil, err
+			}
+			placeholder := codectypes.UnsafePackAny(&unresolvedMsg{typeURL: msgAny.TypeUrl, value: msgAny.Value})
+			placeholder.TypeUrl = msgAny.TypeUrl
+			body.Messages[i] = placeholder
+		}
+	}
+
+	return &cosmosTx.Tx{Body: &body, AuthInfo: &authInfo, Signatures: raw.Signatures}, nil
+}
+
+// decodeTxBytes decodes a tx, leniently if unknown messages are stored as unparsed messages
+func (p *ChainProcessor) decodeTxBytes(txBytes []byte) (*cosmosTx.Tx, error) {
+	txFull, err := decodeTx(p.cl, txBytes)
+	if isUnresolvableTypeURL(err) && p.lenientUnknownMessages {
+		config.Log.Warnf("Tx has messages of types the codec can't resolve, storing them as unparsed messages. Err: %v", err)
+		return decodeTxLeniently(p.cl, txBytes)
+	}
+	return txFull, err
+}
@@ -0,0 +1,72 @@
+package authz
+
+import (
+	"testing"
+
+	txModule "github.com/DefiantLabs/cosmos-tax-cli/cosmos/modules/tx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitMsgExecLog(t *testing.T) {
+	log := &txModule.LogMessage{
+		Events: []txModule.LogMessageEvent{
+			{Type: "message", Attributes: []txModule.Attribute{{Key: "action", Value: MsgExec}}},
+			{Type: "transfer", Attributes: []txModule.Attribute{
+				{Key: "recipient", Value: "addr1"},
+				{Key: "amount", Value: "1uatom"},
+				{Key: AttributeKeyAuthzMsgIndex, Value: "0"},
+				{Key: "recipient", Value: "addr2"},
+				{Key: "amount", Value: "2uatom"},
+				{Key: AttributeKeyAuthzMsgIndex, Value: "1"},
+			}},
+		},
+	}
+
+	logs, ok := SplitMsgExecLog(log, []string{"/cosmos.bank.v1beta1.MsgSend", "/cosmos.bank.v1beta1.MsgSend"})
+	assert.True(t, ok)
+	assert.Len(t, logs, 2)
+	assert.True(t, txModule.IsMessageActionEquals("/cosmos.bank.v1beta1.MsgSend", &logs[1]))
+
+	transfer := txModule.GetEventWithType("transfer", &logs[1])
+	assert.NotNil(t, transfer)
+	recipient, err := txModule.GetValueForAttribute("recipient", transfer)
+	assert.Nil(t, err)
+	assert.Equal(t, "addr2", recipient)
+}
+
+func TestSplitMsgExecLogNested(t *testing.T) {
+	log := &txModule.LogMessage{
+		Events: []txModule.LogMessageEvent{
+			{Type: "transfer", Attributes: []txModule.Attribute{
+				{Key: "recipient", Value: "addr1"},
+				{Key: AttributeKeyAuthzMsgIndex, Value: "1"},
+				{Key: AttributeKeyAuthzMsgIndex, Value: "0"},
+			}},
+		},
+	}
+
+	logs, ok := SplitMsgExecLog(log, []string{MsgExec})
+	assert.True(t, ok)
+
+	nested, ok := SplitMsgExecLog(&logs[0], []string{MsgGrant, "/cosmos.bank.v1beta1.MsgSend"})
+	assert.True(t, ok)
+	assert.Nil(t, txModule.GetEventWithType("transfer", &nested[0]))
+	recipient, err := txModule.GetValueForAttribute("recipient", txModule.GetEventWithType("transfer", &nested[1]))
+	assert.Nil(t, err)
+	assert.Equal(t, "addr1", recipient)
+}
+
+func TestSplitMsgExecLogWithoutIndexes(t *testing.T) {
+	log := &txModule.LogMessage{
+		Events: []txModule.LogMessageEvent{
+			{Type: "transfer", Attributes: []txModule.Attribute{{Key: "recipient", Value: "addr1"}}},
+		},
+	}
+
+	_, ok := SplitMsgExecLog(log, []string{"/cosmos.bank.v1beta1.MsgSend", "/cosmos.bank.v1beta1.MsgSend"})
+	assert.False(t, ok)
+
+	logs, ok := SplitMsgExecLog(log, []string{"/cosmos.bank.v1beta1.MsgSend"})
+	assert.True(t, ok)
+	assert.NotNil(t, txModule.GetEventWithType("transfer", &logs[0]))
+}
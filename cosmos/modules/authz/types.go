@@ -1,8 +1,92 @@
 package authz
 
-// Explicitly ignored messages for tx parsing purposes
+import (
+	"strconv"
+
+	txModule "github.com/DefiantLabs/cosmos-tax-cli/cosmos/modules/tx"
+)
+
 const (
-	MsgExec   = "/cosmos.authz.v1beta1.MsgExec"
+	MsgExec = "/cosmos.authz.v1beta1.MsgExec"
+
+	// Explicitly ignored messages for tx parsing purposes
 	MsgGrant  = "/cosmos.authz.v1beta1.MsgGrant"
 	MsgRevoke = "/cosmos.authz.v1beta1.MsgRevoke"
+
+	// The authz keeper appends this attribute to every event emitted by a message dispatched through MsgExec
+	AttributeKeyAuthzMsgIndex = "authz_msg_index"
 )
+
+// SplitMsgExecLog splits the log of a MsgExec into one log per inner message.
+// Each event emitted by an inner message ends with an authz_msg_index attribute, events of the same type
+// may have been merged into a single event by the SDK, so the attributes are chunked on that key.
+// Nested MsgExecs append their own index first, the last index in a run of indexes is the one for this level.
+// A message event with the inner message type as the action is prepended to each log so the inner message
+// handlers can validate the log as if the message had been executed directly.
+// The bool return is false if the log does not contain authz message indexes (older SDK versions).
+func SplitMsgExecLog(log *txModule.LogMessage, msgTypes []string) ([]txModule.LogMessage, bool) {
+	logs := make([]txModule.LogMessage, len(msgTypes))
+	for i, msgType := range msgTypes {
+		logs[i].MessageIndex = i
+		logs[i].Events = []txModule.LogMessageEvent{{
+			Type:       "message",
+			Attributes: []txModule.Attribute{{Key: "action", Value: msgType}},
+		}}
+	}
+
+	if log == nil {
+		return logs, false
+	}
+
+	foundIndex := false
+	for _, event := range log.Events {
+		var chunk []txModule.Attribute
+		for i := 0; i < len(event.Attributes); i++ {
+			attr := event.Attributes[i]
+			if attr.Key != AttributeKeyAuthzMsgIndex {
+				chunk = append(chunk, attr)
+				continue
+			}
+
+			// Keep the inner indexes of nested MsgExecs in the chunk, they are needed to split the nested log
+			for i+1 < len(event.Attributes) && event.Attributes[i+1].Key == AttributeKeyAuthzMsgIndex {
+				chunk = append(chunk, attr)
+				i++
+				attr = event.Attributes[i]
+			}
+
+			index, err := strconv.Atoi(attr.Value)
+			if err == nil && index >= 0 && index < len(logs) {
+				foundIndex = true
+				logs[index].Events = appendEventAttributes(logs[index].Events, event.Type, chunk)
+			}
+			chunk = nil
+		}
+	}
+
+	if !foundIndex && len(msgTypes) == 1 {
+		// Without indexes the only safe split is a single inner message owning every event
+		logs[0].Events = append(logs[0].Events, log.Events...)
+		return logs, true
+	}
+
+	return logs, foundIndex
+}
+
+// appendEventAttributes merges the attributes into an existing event of the same type, mirroring how the SDK
+// flattens events of the same type in the ABCI message logs
+func appendEventAttributes(events []txModule.LogMessageEvent, eventType string, attrs []txModule.Attribute) []txModule.LogMessageEvent {
+	if len(attrs) == 0 {
+		return events
+	}
+
+	// The synthetic message event at index 0 must stay first and keep its action, so never merge into it
+	for i := 1; i < len(events); i++ {
+		if events[i].Type == eventType {
+			events[i].Attributes = append(events[i].Attributes, attrs...)
+			return events
+		}
+	}
+
+	return append(events, txModule.LogMessageEvent{Type: eventType, Attributes: attrs})
+}
@@ -0,0 +1,134 @@
+package bank
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	parsingTypes "github.com/DefiantLabs/cosmos-tax-cli/cosmos/modules"
+	txModule "github.com/DefiantLabs/cosmos-tax-cli/cosmos/modules/tx"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	bankTypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+)
+
+// ErrNoBankEvents is returned by WrapperBankEvents when the message log shows no funds moving
+var ErrNoBankEvents = errors.New("no bank events in message log")
+
+// WrapperBankEvents is a fallback for messages without a dedicated parser. It derives the funds the message moved from
+// the transfer, coin_spent and coin_received events in its log, so its taxable txs are flagged as inferred.
+type WrapperBankEvents struct {
+	txModule.Message
+	Transfers []SenderReceiverAmount // Sender is empty for minted coins, Receiver is empty for burned coins
+}
+
+// HandleMsg: Collect the bank events of any message type.
+// Every transfer accounts for one coin_spent and one coin_received of the same coin, the coins spent and received
+// that no transfer accounts for are burns and mints.
+func (sf *WrapperBankEvents) HandleMsg(msgType string, msg sdk.Msg, log *txModule.LogMessage) error {
+	sf.Type = msgType
+	if log == nil {
+		return ErrNoBankEvents
+	}
+
+	var transfers, spent, received []SenderReceiverAmount
+	for _, evt := range log.Events {
+		var err error
+		switch evt.Type {
+		case bankTypes.EventTypeTransfer:
+			transfers, err = appendEventCoins(transfers, evt, bankTypes.AttributeKeySender, bankTypes.AttributeKeyRecipient)
+		case bankTypes.EventTypeCoinSpent:
+			spent, err = appendEventCoins(spent, evt, bankTypes.AttributeKeySpender, "")
+		case bankTypes.EventTypeCoinReceived:
+			received, err = appendEventCoins(received, evt, "", bankTypes.AttributeKeyReceiver)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, transfer := range transfers {
+		spent = removeFirstMatch(spent, SenderReceiverAmount{Sender: transfer.Sender, Amount: transfer.Amount})
+		received = removeFirstMatch(received, SenderReceiverAmount{Receiver: transfer.Receiver, Amount: transfer.Amount})
+	}
+
+	sf.Transfers = append(append(transfers, spent...), received...)
+	if len(sf.Transfers) == 0 {
+		return ErrNoBankEvents
+	}
+
+	return nil
+}
+
+// appendEventCoins appends a SenderReceiverAmount for every coin of every amount in the event. An event holds groups
+// of attributes that each end once the sender key (if any), the receiver key (if any) and the amount have been seen,
+// other attributes (such as msg_index) are skipped.
+func appendEventCoins(amounts []SenderReceiverAmount, evt txModule.LogMessageEvent, senderKey string, receiverKey string) ([]SenderReceiverAmount, error) {
+	var sender, receiver, amount string
+	for _, attr := range evt.Attributes {
+		switch {
+		case senderKey != "" && attr.Key == senderKey:
+			sender = attr.Value
+		case receiverKey != "" && attr.Key == receiverKey:
+			receiver = attr.Value
+		case attr.Key == sdk.AttributeKeyAmount:
+			amount = attr.Value
+		default:
+			continue
+		}
+
+		if (senderKey != "" && sender == "") || (receiverKey != "" && receiver == "") || amount == "" {
+			continue
+		}
+
+		coins, err := sdk.ParseCoinsNormalized(amount)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing amount '%s' of %s event: %w", amount, evt.Type, err)
+		}
+		for _, coin := range coins {
+			amounts = append(amounts, SenderReceiverAmount{Sender: sender, Receiver: receiver, Amount: coin})
+		}
+		sender, receiver, amount = "", "", ""
+	}
+
+	return amounts, nil
+}
+
+func removeFirstMatch(amounts []SenderReceiverAmount, match SenderReceiverAmount) []SenderReceiverAmount {
+	for i, amount := range amounts {
+		if amount.Sender == match.Sender && amount.Receiver == match.Receiver && amount.Amount.Denom == match.Amount.Denom && amount.Amount.Amount.Equal(match.Amount.Amount) {
+			return append(amounts[:i], amounts[i+1:]...)
+		}
+	}
+	return amounts
+}
+
+func (sf *WrapperBankEvents) String() string {
+	var movements []string
+	for _, v := range sf.Transfers {
+		movements = append(movements, fmt.Sprintf("%s %s -> %s", v.Amount, v.Sender, v.Receiver))
+	}
+	return fmt.Sprintf("%s (inferred from bank events): %s", sf.Type, strings.Join(movements, ", "))
+}
+
+func (sf *WrapperBankEvents) ParseRelevantData() (relevantData []parsingTypes.MessageRelevantInformation) {
+	for _, transfer := range sf.Transfers {
+		currRelevantData := parsingTypes.MessageRelevantInformation{Inferred: true}
+
+		if transfer.Sender != "" {
+			currRelevantData.SenderAddress = transfer.Sender
+			currRelevantData.AmountSent = transfer.Amount.Amount.BigInt()
+			currRelevantData.DenominationSent = transfer.Amount.Denom
+		}
+
+		if transfer.Receiver != "" {
+			currRelevantData.ReceiverAddress = transfer.Receiver
+			currRelevantData.AmountReceived = transfer.Amount.Amount.BigInt()
+			currRelevantData.DenominationReceived = transfer.Amount.Denom
+		}
+
+		relevantData = append(relevantData, currRelevantData)
+	}
+
+	return relevantData
+}
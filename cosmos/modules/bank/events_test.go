@@ -0,0 +1,74 @@
+package bank
+
+import (
+	"testing"
+
+	txModule "github.com/DefiantLabs/cosmos-tax-cli/cosmos/modules/tx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBankEventsTransferMintAndBurn(t *testing.T) {
+	log := &txModule.LogMessage{
+		Events: []txModule.LogMessageEvent{
+			{Type: "coin_spent", Attributes: []txModule.Attribute{
+				{Key: "spender", Value: "addr1"},
+				{Key: "amount", Value: "5uatom,3uosmo"},
+				{Key: "spender", Value: "addr2"},
+				{Key: "amount", Value: "7uatom"},
+			}},
+			{Type: "coin_received", Attributes: []txModule.Attribute{
+				{Key: "receiver", Value: "addr2"},
+				{Key: "amount", Value: "5uatom,3uosmo"},
+				{Key: "receiver", Value: "addr1"},
+				{Key: "amount", Value: "2ufoo"},
+			}},
+			{Type: "transfer", Attributes: []txModule.Attribute{
+				{Key: "recipient", Value: "addr2"},
+				{Key: "sender", Value: "addr1"},
+				{Key: "amount", Value: "5uatom,3uosmo"},
+				{Key: "msg_index", Value: "0"},
+			}},
+		},
+	}
+
+	wrapper := &WrapperBankEvents{}
+	err := wrapper.HandleMsg("/some.module.v1.MsgDoSomething", nil, log)
+	assert.Nil(t, err)
+
+	relevantData := wrapper.ParseRelevantData()
+	assert.Len(t, relevantData, 4)
+	for _, data := range relevantData {
+		assert.True(t, data.Inferred)
+	}
+
+	// The transfer, one row per coin
+	assert.Equal(t, "addr1", relevantData[0].SenderAddress)
+	assert.Equal(t, "addr2", relevantData[0].ReceiverAddress)
+	assert.Equal(t, "uatom", relevantData[0].DenominationSent)
+	assert.Equal(t, int64(5), relevantData[0].AmountReceived.Int64())
+	assert.Equal(t, "uosmo", relevantData[1].DenominationReceived)
+
+	// Spent without a transfer is a burn
+	assert.Equal(t, "addr2", relevantData[2].SenderAddress)
+	assert.Equal(t, "", relevantData[2].ReceiverAddress)
+	assert.Equal(t, int64(7), relevantData[2].AmountSent.Int64())
+	assert.Nil(t, relevantData[2].AmountReceived)
+
+	// Received without a transfer is a mint
+	assert.Equal(t, "", relevantData[3].SenderAddress)
+	assert.Equal(t, "addr1", relevantData[3].ReceiverAddress)
+	assert.Equal(t, "ufoo", relevantData[3].DenominationReceived)
+	assert.Nil(t, relevantData[3].AmountSent)
+}
+
+func TestBankEventsWithoutFundsMoving(t *testing.T) {
+	log := &txModule.LogMessage{
+		Events: []txModule.LogMessageEvent{
+			{Type: "message", Attributes: []txModule.Attribute{{Key: "action", Value: "/cosmos.gov.v1beta1.MsgVote"}}},
+		},
+	}
+
+	wrapper := &WrapperBankEvents{}
+	assert.ErrorIs(t, wrapper.HandleMsg("/cosmos.gov.v1beta1.MsgVote", nil, log), ErrNoBankEvents)
+	assert.ErrorIs(t, wrapper.HandleMsg("/cosmos.gov.v1beta1.MsgVote", nil, nil), ErrNoBankEvents)
+}
@@ -0,0 +1,168 @@
+package distribution
+
+import (
+	"bytes"
+	"fmt"
+
+	txModule "github.com/DefiantLabs/cosmos-tax-cli/cosmos/modules/tx"
+
+	stdTypes "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/bech32"
+	authTypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	bankTypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	distTypes "github.com/cosmos/cosmos-sdk/x/distribution/types"
+)
+
+// distributionModuleAddress is the account the distribution module pays rewards from, the same bytes on every chain
+var distributionModuleAddress = authTypes.NewModuleAddress(distTypes.ModuleName)
+
+// WithdrawnReward is the reward of a delegation to a single validator, withdrawn as part of a message
+type WithdrawnReward struct {
+	Validator string
+	Delegator string
+	Recipient string // The withdraw address of the delegator, set with MsgSetWithdrawAddress, the delegator by default
+	Amount    stdTypes.Coins
+}
+
+// GetWithdrawnRewards returns the rewards the withdraw_rewards events in the log show were withdrawn. Every reward is
+// credited to the address the distribution module transferred it to, which is the withdraw address of the delegator
+// at the time. Rewards without a matching transfer are credited to the delegator, rewards of zero are skipped.
+// Older SDKs do not add the delegator to the event, delegator is used for those.
+func GetWithdrawnRewards(log *txModule.LogMessage, delegator string) ([]WithdrawnReward, error) {
+	var rewards []WithdrawnReward
+	for _, evt := range txModule.GetEventsWithType(distTypes.EventTypeWithdrawRewards, log) {
+		eventRewards, err := parseWithdrawRewardsEvent(evt, delegator)
+		if err != nil {
+			return nil, err
+		}
+		rewards = append(rewards, eventRewards...)
+	}
+	if len(rewards) == 0 {
+		return nil, nil
+	}
+
+	payouts, err := getDistributionPayouts(log)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range rewards {
+		rewards[i].Recipient = rewards[i].Delegator
+		for j, payout := range payouts {
+			if payout.Amount.String() == rewards[i].Amount.String() {
+				rewards[i].Recipient = payout.Recipient
+				payouts = append(payouts[:j], payouts[j+1:]...)
+				break
+			}
+		}
+	}
+
+	return rewards, nil
+}
+
+// parseWithdrawRewardsEvent parses every reward in the event. Logs that merge the events of a type hold one group of
+// amount, validator and (in newer SDKs) delegator attributes per reward, a group ends when one of its keys repeats.
+func parseWithdrawRewardsEvent(evt txModule.LogMessageEvent, delegator string) ([]WithdrawnReward, error) {
+	var rewards []WithdrawnReward
+	var current WithdrawnReward
+	var amount string
+	seen := map[string]bool{}
+
+	flush := func() error {
+		if len(seen) == 0 {
+			return nil
+		}
+		coins, err := stdTypes.ParseCoinsNormalized(amount)
+		if err != nil {
+			return fmt.Errorf("error parsing amount '%s' of %s event: %w", amount, evt.Type, err)
+		}
+		if !coins.IsZero() {
+			if current.Delegator == "" {
+				current.Delegator = delegator
+			}
+			current.Amount = coins
+			rewards = append(rewards, current)
+		}
+		current, amount, seen = WithdrawnReward{}, "", map[string]bool{}
+		return nil
+	}
+
+	for _, attr := range evt.Attributes {
+		switch attr.Key {
+		case stdTypes.AttributeKeyAmount, distTypes.AttributeKeyValidator, distTypes.AttributeKeyDelegator:
+		default:
+			continue
+		}
+
+		if seen[attr.Key] {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		}
+		seen[attr.Key] = true
+
+		switch attr.Key {
+		case stdTypes.AttributeKeyAmount:
+			amount = attr.Value
+		case distTypes.AttributeKeyValidator:
+			current.Validator = attr.Value
+		case distTypes.AttributeKeyDelegator:
+			current.Delegator = attr.Value
+		}
+	}
+
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	return rewards, nil
+}
+
+type distributionPayout struct {
+	Recipient string
+	Amount    stdTypes.Coins
+}
+
+// getDistributionPayouts returns the transfers in the log sent by the distribution module account, in order
+func getDistributionPayouts(log *txModule.LogMessage) ([]distributionPayout, error) {
+	var payouts []distributionPayout
+	for _, evt := range txModule.GetEventsWithType(bankTypes.EventTypeTransfer, log) {
+		var sender, recipient, amount string
+		for _, attr := range evt.Attributes {
+			switch attr.Key {
+			case bankTypes.AttributeKeySender:
+				sender = attr.Value
+			case bankTypes.AttributeKeyRecipient:
+				recipient = attr.Value
+			case stdTypes.AttributeKeyAmount:
+				amount = attr.Value
+			default:
+				continue
+			}
+
+			if sender == "" || recipient == "" || amount == "" {
+				continue
+			}
+
+			if isDistributionModuleAddress(sender) {
+				coins, err := stdTypes.ParseCoinsNormalized(amount)
+				if err != nil {
+					return nil, fmt.Errorf("error parsing amount '%s' of %s event: %w", amount, evt.Type, err)
+				}
+				payouts = append(payouts, distributionPayout{Recipient: recipient, Amount: coins})
+			}
+			sender, recipient, amount = "", "", ""
+		}
+	}
+
+	return payouts, nil
+}
+
+// isDistributionModuleAddress compares the address bytes, so it does not depend on the bech32 prefix of the chain
+func isDistributionModuleAddress(address string) bool {
+	_, bz, err := bech32.DecodeAndConvert(address)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(bz, distributionModuleAddress)
+}
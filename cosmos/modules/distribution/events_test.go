@@ -0,0 +1,83 @@
+package distribution
+
+import (
+	"testing"
+
+	txModule "github.com/DefiantLabs/cosmos-tax-cli/cosmos/modules/tx"
+	stdTypes "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithdrawnRewardsCreditedToWithdrawAddress(t *testing.T) {
+	distributionAddress := stdTypes.MustBech32ifyAddressBytes("cosmos", distributionModuleAddress)
+	log := &txModule.LogMessage{
+		Events: []txModule.LogMessageEvent{
+			{Type: "transfer", Attributes: []txModule.Attribute{
+				{Key: "recipient", Value: "withdrawaddr"},
+				{Key: "sender", Value: distributionAddress},
+				{Key: "amount", Value: "15uatom"},
+				{Key: "recipient", Value: "bondedpool"},
+				{Key: "sender", Value: "delegator"},
+				{Key: "amount", Value: "1000uatom"},
+			}},
+			{Type: "withdraw_rewards", Attributes: []txModule.Attribute{
+				{Key: "amount", Value: "15uatom"},
+				{Key: "validator", Value: "valoper1"},
+				{Key: "delegator", Value: "delegator"},
+			}},
+		},
+	}
+
+	rewards, err := GetWithdrawnRewards(log, "delegator")
+	assert.Nil(t, err)
+	assert.Len(t, rewards, 1)
+	assert.Equal(t, "valoper1", rewards[0].Validator)
+	assert.Equal(t, "delegator", rewards[0].Delegator)
+	assert.Equal(t, "withdrawaddr", rewards[0].Recipient)
+	assert.Equal(t, "15uatom", rewards[0].Amount.String())
+}
+
+func TestWithdrawnRewardsMergedEvents(t *testing.T) {
+	distributionAddress := stdTypes.MustBech32ifyAddressBytes("osmo", distributionModuleAddress)
+	log := &txModule.LogMessage{
+		Events: []txModule.LogMessageEvent{
+			{Type: "transfer", Attributes: []txModule.Attribute{
+				{Key: "recipient", Value: "delegator"},
+				{Key: "sender", Value: distributionAddress},
+				{Key: "amount", Value: "3ufoo,7uosmo"},
+			}},
+			// Older SDKs merge the events of a type and leave out the delegator
+			{Type: "withdraw_rewards", Attributes: []txModule.Attribute{
+				{Key: "amount", Value: ""},
+				{Key: "validator", Value: "valoper1"},
+				{Key: "amount", Value: "7uosmo,3ufoo"},
+				{Key: "validator", Value: "valoper2"},
+				{Key: "amount", Value: "2uosmo"},
+				{Key: "validator", Value: "valoper3"},
+			}},
+		},
+	}
+
+	rewards, err := GetWithdrawnRewards(log, "delegator")
+	assert.Nil(t, err)
+	assert.Len(t, rewards, 2)
+
+	assert.Equal(t, "valoper2", rewards[0].Validator)
+	assert.Equal(t, "delegator", rewards[0].Delegator)
+	assert.Equal(t, "delegator", rewards[0].Recipient)
+	assert.Equal(t, "3ufoo,7uosmo", rewards[0].Amount.String())
+
+	// Without a matching transfer the reward is credited to the delegator
+	assert.Equal(t, "valoper3", rewards[1].Validator)
+	assert.Equal(t, "delegator", rewards[1].Recipient)
+}
+
+func TestWithdrawnRewardsWithoutEvents(t *testing.T) {
+	rewards, err := GetWithdrawnRewards(&txModule.LogMessage{}, "delegator")
+	assert.Nil(t, err)
+	assert.Empty(t, rewards)
+
+	rewards, err = GetWithdrawnRewards(nil, "delegator")
+	assert.Nil(t, err)
+	assert.Empty(t, rewards)
+}
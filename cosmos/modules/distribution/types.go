@@ -3,6 +3,7 @@ package distribution
 import (
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/DefiantLabs/cosmos-tax-cli/config"
 	parsingTypes "github.com/DefiantLabs/cosmos-tax-cli/cosmos/modules"
@@ -18,7 +19,7 @@ const (
 	MsgWithdrawValidatorCommission = "/cosmos.distribution.v1beta1.MsgWithdrawValidatorCommission"
 	MsgWithdrawDelegatorReward     = "/cosmos.distribution.v1beta1.MsgWithdrawDelegatorReward"
 	MsgWithdrawRewards             = "withdraw-rewards"                                   // FIXME: this is used in 2 places and only 1 will work....
-	MsgSetWithdrawAddress          = "/cosmos.distribution.v1beta1.MsgSetWithdrawAddress" // An explicitly ignored msg for tx parsing purposes
+	MsgSetWithdrawAddress          = "/cosmos.distribution.v1beta1.MsgSetWithdrawAddress" // An explicitly ignored msg for tx parsing purposes, see GetWithdrawnRewards
 )
 
 type WrapperMsgFundCommunityPool struct {
@@ -39,9 +40,7 @@ type WrapperMsgWithdrawValidatorCommission struct {
 type WrapperMsgWithdrawDelegatorReward struct {
 	txModule.Message
 	CosmosMsgWithdrawDelegatorReward *distTypes.MsgWithdrawDelegatorReward
-	CoinReceived                     stdTypes.Coin
-	MultiCoinsReceived               stdTypes.Coins
-	RecipientAddress                 string
+	Rewards                          []WithdrawnReward
 }
 
 // HandleMsg: Handle type checking for MsgFundCommunityPool
@@ -159,37 +158,35 @@ func (sf *WrapperMsgWithdrawDelegatorReward) HandleMsg(msgType string, msg stdTy
 		return util.ReturnInvalidLog(msgType, log)
 	}
 
-	// The attribute in the log message that shows you the delegator withdrawal address and amount received
-	delegatorReceivedCoinsEvt := txModule.GetEventWithType(bankTypes.EventTypeTransfer, log)
-	if delegatorReceivedCoinsEvt == nil {
-		// A withdrawal without a transfer means no amounts were actually moved.
-		return nil
-	}
+	delegator := sf.CosmosMsgWithdrawDelegatorReward.DelegatorAddress
+	validator := sf.CosmosMsgWithdrawDelegatorReward.ValidatorAddress
 
-	recipientAddress, err := txModule.GetValueForAttribute(bankTypes.AttributeKeyRecipient, delegatorReceivedCoinsEvt)
-	if err != nil {
-		return err
+	// Logs without withdraw_rewards events only show the rewards as transfers from the distribution module
+	if txModule.GetEventWithType(distTypes.EventTypeWithdrawRewards, log) == nil {
+		payouts, err := getDistributionPayouts(log)
+		if err != nil {
+			config.Log.Error("Error parsing the rewards transferred by the distribution module", err)
+			return err
+		}
+		for _, payout := range payouts {
+			sf.Rewards = append(sf.Rewards, WithdrawnReward{Validator: validator, Delegator: delegator, Recipient: payout.Recipient, Amount: payout.Amount})
+		}
+		return nil
 	}
 
-	sf.RecipientAddress = recipientAddress
-	coinsReceived, err := txModule.GetValueForAttribute("amount", delegatorReceivedCoinsEvt)
+	rewards, err := GetWithdrawnRewards(log, delegator)
 	if err != nil {
+		config.Log.Error("Error parsing the withdrawn rewards", err)
 		return err
 	}
-
-	// This may be able to be optimized by doing one or the other
-	coin, err := stdTypes.ParseCoinNormalized(coinsReceived)
-	if err != nil {
-		sf.MultiCoinsReceived, err = stdTypes.ParseCoinsNormalized(coinsReceived)
-		if err != nil {
-			config.Log.Error("Error parsing coins normalized", err)
-			return err
+	for i := range rewards {
+		if rewards[i].Validator == "" {
+			rewards[i].Validator = validator
 		}
-	} else {
-		sf.CoinReceived = coin
 	}
+	sf.Rewards = rewards
 
-	return err
+	return nil
 }
 
 func (sf *WrapperMsgFundCommunityPool) ParseRelevantData() []parsingTypes.MessageRelevantInformation {
@@ -235,38 +232,28 @@ func (sf *WrapperMsgWithdrawValidatorCommission) ParseRelevantData() []parsingTy
 }
 
 func (sf *WrapperMsgWithdrawDelegatorReward) ParseRelevantData() []parsingTypes.MessageRelevantInformation {
-	if sf.CoinReceived.IsNil() {
-		relevantData := make([]parsingTypes.MessageRelevantInformation, len(sf.MultiCoinsReceived))
-		for i, v := range sf.MultiCoinsReceived {
-			relevantData[i] = parsingTypes.MessageRelevantInformation{
+	var relevantData []parsingTypes.MessageRelevantInformation
+	for _, reward := range sf.Rewards {
+		for _, v := range reward.Amount {
+			relevantData = append(relevantData, parsingTypes.MessageRelevantInformation{
 				AmountReceived:       v.Amount.BigInt(),
 				DenominationReceived: v.Denom,
 				SenderAddress:        "",
-				ReceiverAddress:      sf.RecipientAddress,
-			}
+				ReceiverAddress:      reward.Recipient,
+			})
 		}
-		return relevantData
-	}
-	relevantData := make([]parsingTypes.MessageRelevantInformation, 1)
-	relevantData[0] = parsingTypes.MessageRelevantInformation{
-		AmountReceived:       sf.CoinReceived.Amount.BigInt(),
-		DenominationReceived: sf.CoinReceived.Denom,
-		SenderAddress:        "",
-		ReceiverAddress:      sf.RecipientAddress,
 	}
 	return relevantData
 }
 
 func (sf *WrapperMsgWithdrawDelegatorReward) String() string {
-	var coinsReceivedString string
-	if !sf.CoinReceived.IsNil() {
-		coinsReceivedString = sf.CoinReceived.String()
-	} else {
-		coinsReceivedString = sf.MultiCoinsReceived.String()
+	var rewardStrings []string
+	for _, reward := range sf.Rewards {
+		rewardStrings = append(rewardStrings, fmt.Sprintf("%s from validator %s to %s", reward.Amount, reward.Validator, reward.Recipient))
 	}
 
 	return fmt.Sprintf("MsgWithdrawDelegatorReward: Delegator %s received %s",
-		sf.CosmosMsgWithdrawDelegatorReward.DelegatorAddress, coinsReceivedString)
+		sf.CosmosMsgWithdrawDelegatorReward.DelegatorAddress, strings.Join(rewardStrings, ", "))
 }
 
 func (sf *WrapperMsgWithdrawValidatorCommission) String() string {
@@ -0,0 +1,67 @@
+package distribution
+
+import (
+	"testing"
+
+	txModule "github.com/DefiantLabs/cosmos-tax-cli/cosmos/modules/tx"
+	stdTypes "github.com/cosmos/cosmos-sdk/types"
+	distTypes "github.com/cosmos/cosmos-sdk/x/distribution/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithdrawDelegatorRewardCreditedToWithdrawAddress(t *testing.T) {
+	distributionAddress := stdTypes.MustBech32ifyAddressBytes("cosmos", distributionModuleAddress)
+	msg := &distTypes.MsgWithdrawDelegatorReward{DelegatorAddress: "delegator", ValidatorAddress: "valoper1"}
+	log := &txModule.LogMessage{
+		Events: []txModule.LogMessageEvent{
+			{Type: "message", Attributes: []txModule.Attribute{{Key: "action", Value: MsgWithdrawDelegatorReward}}},
+			// A transfer before the reward payout must not be taken for the reward
+			{Type: "transfer", Attributes: []txModule.Attribute{
+				{Key: "recipient", Value: "feecollector"},
+				{Key: "sender", Value: "delegator"},
+				{Key: "amount", Value: "1uatom"},
+				{Key: "recipient", Value: "withdrawaddr"},
+				{Key: "sender", Value: distributionAddress},
+				{Key: "amount", Value: "15uatom"},
+			}},
+			{Type: "withdraw_rewards", Attributes: []txModule.Attribute{
+				{Key: "amount", Value: "15uatom"},
+				{Key: "validator", Value: "valoper1"},
+				{Key: "delegator", Value: "delegator"},
+			}},
+		},
+	}
+
+	wrapper := &WrapperMsgWithdrawDelegatorReward{}
+	assert.Nil(t, wrapper.HandleMsg(MsgWithdrawDelegatorReward, msg, log))
+	assert.Len(t, wrapper.Rewards, 1)
+	assert.Equal(t, "valoper1", wrapper.Rewards[0].Validator)
+
+	relevantData := wrapper.ParseRelevantData()
+	assert.Len(t, relevantData, 1)
+	assert.Equal(t, "withdrawaddr", relevantData[0].ReceiverAddress)
+	assert.Equal(t, "uatom", relevantData[0].DenominationReceived)
+	assert.Equal(t, int64(15), relevantData[0].AmountReceived.Int64())
+}
+
+func TestWithdrawDelegatorRewardWithoutWithdrawRewardsEvent(t *testing.T) {
+	distributionAddress := stdTypes.MustBech32ifyAddressBytes("cosmos", distributionModuleAddress)
+	msg := &distTypes.MsgWithdrawDelegatorReward{DelegatorAddress: "delegator", ValidatorAddress: "valoper1"}
+	log := &txModule.LogMessage{
+		Events: []txModule.LogMessageEvent{
+			{Type: "message", Attributes: []txModule.Attribute{{Key: "action", Value: MsgWithdrawDelegatorReward}}},
+			{Type: "transfer", Attributes: []txModule.Attribute{
+				{Key: "recipient", Value: "delegator"},
+				{Key: "sender", Value: distributionAddress},
+				{Key: "amount", Value: "3ufoo,7uatom"},
+			}},
+		},
+	}
+
+	wrapper := &WrapperMsgWithdrawDelegatorReward{}
+	assert.Nil(t, wrapper.HandleMsg(MsgWithdrawDelegatorReward, msg, log))
+	assert.Len(t, wrapper.Rewards, 1)
+	assert.Equal(t, "valoper1", wrapper.Rewards[0].Validator)
+	assert.Equal(t, "delegator", wrapper.Rewards[0].Recipient)
+	assert.Len(t, wrapper.ParseRelevantData(), 2)
+}
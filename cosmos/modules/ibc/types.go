@@ -2,6 +2,7 @@ package ibc
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	"cosmossdk.io/math"
@@ -16,17 +17,16 @@ import (
 const (
 	MsgRecvPacket      = "/ibc.core.channel.v1.MsgRecvPacket"
 	MsgAcknowledgement = "/ibc.core.channel.v1.MsgAcknowledgement"
+	MsgTransfer        = "/ibc.applications.transfer.v1.MsgTransfer"
+	MsgTimeout         = "/ibc.core.channel.v1.MsgTimeout"
+	MsgTimeoutOnClose  = "/ibc.core.channel.v1.MsgTimeoutOnClose"
 
 	// Explicitly ignored messages for tx parsing purposes
-	MsgTransfer           = "/ibc.applications.transfer.v1.MsgTransfer"
 	MsgChannelOpenTry     = "/ibc.core.channel.v1.MsgChannelOpenTry"
 	MsgChannelOpenConfirm = "/ibc.core.channel.v1.MsgChannelOpenConfirm"
 	MsgChannelOpenInit    = "/ibc.core.channel.v1.MsgChannelOpenInit"
 	MsgChannelOpenAck     = "/ibc.core.channel.v1.MsgChannelOpenAck"
 
-	MsgTimeout        = "/ibc.core.channel.v1.MsgTimeout"
-	MsgTimeoutOnClose = "/ibc.core.channel.v1.MsgTimeoutOnClose"
-
 	MsgConnectionOpenTry     = "/ibc.core.connection.v1.MsgConnectionOpenTry"
 	MsgConnectionOpenConfirm = "/ibc.core.connection.v1.MsgConnectionOpenConfirm"
 	MsgConnectionOpenInit    = "/ibc.core.connection.v1.MsgConnectionOpenInit"
@@ -51,6 +51,8 @@ const (
 
 	AlternateMsgAcknowledgementLogAction = "acknowledge_packet"
 	AlternateMsgRcvLogAction             = "recv_packet"
+	AlternateMsgTimeoutLogAction         = "timeout_packet"
+	AlternateMsgTimeoutOnCloseLogAction  = "timeout_on_close_packet"
 )
 
 type WrapperMsgRecvPacket struct {
@@ -175,7 +177,7 @@ func (w *WrapperMsgAcknowledgement) HandleMsg(msgType string, msg stdTypes.Msg,
 		return fmt.Errorf("failed to convert denom amount to sdk.Int, got(%s)", data.Amount)
 	}
 
-	// Acknowledgements can contain an error & we only want to index successful acks,
+	// Acknowledgements can contain an error, in which case the sender was refunded,
 	// so we need to check the ack bytes to determine if it was a result or an error.
 	var ack chantypes.Acknowledgement
 	if err := types.ModuleCdc.UnmarshalJSON(w.MsgAcknowledgement.Acknowledgement, &ack); err != nil {
@@ -184,25 +186,98 @@ func (w *WrapperMsgAcknowledgement) HandleMsg(msgType string, msg stdTypes.Msg,
 
 	switch ack.Response.(type) {
 	case *chantypes.Acknowledgement_Error:
-		// We index nothing on Acknowledgement errors
 		w.AckResult = AckFailure
+		// A relayer submitting an ack that was already processed does not refund the sender again
+		if txModule.GetEventWithType(chantypes.EventTypeAcknowledgePacket, log) == nil {
+			return nil
+		}
+		w.Amount = amount
+		w.Denom = refundDenom(data.Denom)
 		return nil
 	default:
-		// the acknowledgement succeeded on the receiving chain
+		// the acknowledgement succeeded on the receiving chain, the send was indexed with the MsgTransfer
 		w.AckResult = AckSuccess
-		w.Amount = amount
-		w.Denom = data.Denom
 		return nil
 	}
 }
 
 func (w *WrapperMsgAcknowledgement) ParseRelevantData() []parsingTypes.MessageRelevantInformation {
 	// This prevents the item from being indexed
-	if w.Amount.IsNil() || w.AckType == AckNotFungibleTokenTransfer || w.AckResult == AckFailure {
+	if w.Amount.IsNil() || w.AckType == AckNotFungibleTokenTransfer || w.AckResult == AckSuccess {
 		return nil
 	}
 
-	// MsgAcknowledgement indicates a user has successfully sent a packet
+	// A failed acknowledgement refunds the sender the amount sent with the MsgTransfer
+	return refundRelevantData(w.SenderAddress, w.ReceiverAddress, w.Amount, w.Denom)
+}
+
+func (w *WrapperMsgAcknowledgement) String() string {
+	if w.AckType == AckNotFungibleTokenTransfer {
+		return "MsgAcknowledgement: IBC transfer was not a FungibleTokenTransfer"
+	}
+
+	if w.AckResult == AckSuccess {
+		return fmt.Sprintf("MsgAcknowledgement: IBC transfer from %s to %s was successful", w.SenderAddress, w.ReceiverAddress)
+	}
+
+	if w.Amount.IsNil() {
+		return "MsgAcknowledgement: IBC transfer failure was already acknowledged"
+	}
+
+	return fmt.Sprintf("MsgAcknowledgement: IBC transfer failed, refunded %s%s to %s", w.Amount, w.Denom, w.SenderAddress)
+}
+
+type WrapperMsgTransfer struct {
+	txModule.Message
+	MsgTransfer        *types.MsgTransfer
+	SenderAddress      string
+	ReceiverAddress    string
+	Amount             math.Int
+	Denom              string
+	SourcePort         string
+	SourceChannel      string
+	DestinationPort    string
+	DestinationChannel string
+	Sequence           uint64
+}
+
+func (w *WrapperMsgTransfer) HandleMsg(msgType string, msg stdTypes.Msg, log *txModule.LogMessage) error {
+	w.Type = msgType
+	w.MsgTransfer = msg.(*types.MsgTransfer)
+
+	// Confirm that the action listed in the message log matches the Message type
+	validLog := txModule.IsMessageActionEquals(w.GetType(), log)
+	if !validLog {
+		return util.ReturnInvalidLog(msgType, log)
+	}
+
+	w.SenderAddress = w.MsgTransfer.Sender
+	w.ReceiverAddress = w.MsgTransfer.Receiver
+	w.Amount = w.MsgTransfer.Token.Amount
+	w.Denom = w.MsgTransfer.Token.Denom
+	w.SourcePort = w.MsgTransfer.SourcePort
+	w.SourceChannel = w.MsgTransfer.SourceChannel
+
+	// The counterparty channel and the sequence are only known once the packet is sent
+	sendPacketEvent := txModule.GetEventWithType(chantypes.EventTypeSendPacket, log)
+	if sendPacketEvent == nil {
+		return util.ReturnInvalidLog(msgType, log)
+	}
+
+	w.DestinationPort = txModule.GetLastValueForAttribute(chantypes.AttributeKeyDstPort, sendPacketEvent)
+	w.DestinationChannel = txModule.GetLastValueForAttribute(chantypes.AttributeKeyDstChannel, sendPacketEvent)
+
+	sequence, err := strconv.ParseUint(txModule.GetLastValueForAttribute(chantypes.AttributeKeySequence, sendPacketEvent), 10, 64)
+	if err != nil {
+		return fmt.Errorf("failed to parse the packet sequence of the IBC transfer: %v", err)
+	}
+	w.Sequence = sequence
+
+	return nil
+}
+
+func (w *WrapperMsgTransfer) ParseRelevantData() []parsingTypes.MessageRelevantInformation {
+	// MsgTransfer sends assets off this chain, the receiver is on the counterparty chain
 	// so the received amount will always be zero
 	amountReceived := stdTypes.NewInt(0)
 
@@ -216,18 +291,104 @@ func (w *WrapperMsgAcknowledgement) ParseRelevantData() []parsingTypes.MessageRe
 	}}
 }
 
-func (w *WrapperMsgAcknowledgement) String() string {
-	if w.AckType == AckNotFungibleTokenTransfer {
-		return "MsgAcknowledgement: IBC transfer was not a FungibleTokenTransfer"
+func (w *WrapperMsgTransfer) String() string {
+	return fmt.Sprintf("MsgTransfer: IBC transfer of %s%s from %s to %s over %s/%s to %s/%s (sequence %d)",
+		w.Amount, w.Denom, w.SenderAddress, w.ReceiverAddress, w.SourcePort, w.SourceChannel, w.DestinationPort, w.DestinationChannel, w.Sequence)
+}
+
+// WrapperMsgTimeout handles both MsgTimeout and MsgTimeoutOnClose, the sender of a timed out transfer is refunded
+type WrapperMsgTimeout struct {
+	txModule.Message
+	Packet          chantypes.Packet
+	Sequence        uint64
+	SenderAddress   string
+	ReceiverAddress string
+	Amount          math.Int
+	Denom           string
+}
+
+func (w *WrapperMsgTimeout) HandleMsg(msgType string, msg stdTypes.Msg, log *txModule.LogMessage) error {
+	w.Type = msgType
+
+	var alternateLogAction string
+	switch timeout := msg.(type) {
+	case *chantypes.MsgTimeout:
+		w.Packet = timeout.Packet
+		alternateLogAction = AlternateMsgTimeoutLogAction
+	case *chantypes.MsgTimeoutOnClose:
+		w.Packet = timeout.Packet
+		alternateLogAction = AlternateMsgTimeoutOnCloseLogAction
+	default:
+		return fmt.Errorf("unexpected message %T for IBC timeout", msg)
+	}
+
+	// Confirm that the action listed in the message log matches the Message type
+	validLog := txModule.IsMessageActionEquals(w.GetType(), log)
+	alternateValidLog := txModule.IsMessageActionEquals(alternateLogAction, log)
+
+	if !validLog && !alternateValidLog {
+		return util.ReturnInvalidLog(msgType, log)
+	}
+
+	// Unmarshal the json encoded packet data so we can access sender, receiver and denom info
+	var data types.FungibleTokenPacketData
+	if err := types.ModuleCdc.UnmarshalJSON(w.Packet.GetData(), &data); err != nil {
+		// If there was a failure then this timeout was not for a token transfer packet, nothing was refunded
+		return nil
+	}
+
+	// A relayer submitting a timeout that was already processed does not refund the sender again
+	if txModule.GetEventWithType(chantypes.EventTypeTimeoutPacket, log) == nil {
+		return nil
+	}
+
+	w.SenderAddress = data.Sender
+	w.ReceiverAddress = data.Receiver
+	w.Sequence = w.Packet.Sequence
+
+	amount, ok := stdTypes.NewIntFromString(data.Amount)
+	if !ok {
+		return fmt.Errorf("failed to convert denom amount to sdk.Int, got(%s)", data.Amount)
 	}
 
-	if w.AckType == AckFungibleTokenTransfer && w.AckResult == AckFailure {
-		return "MsgAcknowledgement: IBC transfer was not successful"
+	w.Amount = amount
+	w.Denom = refundDenom(data.Denom)
+
+	return nil
+}
+
+func (w *WrapperMsgTimeout) ParseRelevantData() []parsingTypes.MessageRelevantInformation {
+	// This prevents the item from being indexed
+	if w.Amount.IsNil() {
+		return nil
 	}
 
+	return refundRelevantData(w.SenderAddress, w.ReceiverAddress, w.Amount, w.Denom)
+}
+
+func (w *WrapperMsgTimeout) String() string {
 	if w.Amount.IsNil() {
-		return "MsgAcknowledgement: IBC transfer was not a FungibleTokenTransfer"
+		return fmt.Sprintf("%s: no IBC transfer was refunded", w.Type)
 	}
+	return fmt.Sprintf("%s: IBC transfer timed out, refunded %s%s to %s", w.Type, w.Amount, w.Denom, w.SenderAddress)
+}
+
+// refundDenom is the denom on this chain of the packet denom of a transfer sent from this chain.
+// Packet denoms of tokens that did not originate on this chain are prefixed with their trace.
+func refundDenom(packetDenom string) string {
+	return types.ParseDenomTrace(packetDenom).IBCDenom()
+}
 
-	return fmt.Sprintf("MsgAcknowledgement: IBC transfer of %s%s from %s to %s", w.Amount, w.Denom, w.SenderAddress, w.ReceiverAddress)
+// refundRelevantData returns the amount of a failed transfer to its sender, the counterparty receiver is the sender of the refund
+func refundRelevantData(sender string, receiver string, amount math.Int, denom string) []parsingTypes.MessageRelevantInformation {
+	amountSent := stdTypes.NewInt(0)
+
+	return []parsingTypes.MessageRelevantInformation{{
+		SenderAddress:        receiver,
+		ReceiverAddress:      sender,
+		AmountSent:           amountSent.BigInt(),
+		AmountReceived:       amount.BigInt(),
+		DenominationSent:     "",
+		DenominationReceived: denom,
+	}}
 }
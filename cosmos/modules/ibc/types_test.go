@@ -0,0 +1,106 @@
+package ibc
+
+import (
+	"testing"
+
+	txModule "github.com/DefiantLabs/cosmos-tax-cli/cosmos/modules/tx"
+	stdTypes "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/ibc-go/v7/modules/apps/transfer/types"
+	clienttypes "github.com/cosmos/ibc-go/v7/modules/core/02-client/types"
+	chantypes "github.com/cosmos/ibc-go/v7/modules/core/04-channel/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func transferPacket(denom string) chantypes.Packet {
+	data := types.NewFungibleTokenPacketData(denom, "100", "cosmos1sender", "osmo1receiver", "")
+	return chantypes.Packet{Sequence: 7, SourcePort: "transfer", SourceChannel: "channel-141", Data: data.GetBytes()}
+}
+
+func TestMsgTransfer(t *testing.T) {
+	msg := types.NewMsgTransfer("transfer", "channel-141", stdTypes.NewInt64Coin("uatom", 100), "cosmos1sender", "osmo1receiver", clienttypes.ZeroHeight(), 0, "")
+	log := &txModule.LogMessage{
+		Events: []txModule.LogMessageEvent{
+			{Type: "message", Attributes: []txModule.Attribute{{Key: "action", Value: MsgTransfer}}},
+			{Type: chantypes.EventTypeSendPacket, Attributes: []txModule.Attribute{
+				{Key: chantypes.AttributeKeySequence, Value: "7"},
+				{Key: chantypes.AttributeKeyDstPort, Value: "transfer"},
+				{Key: chantypes.AttributeKeyDstChannel, Value: "channel-0"},
+			}},
+		},
+	}
+
+	w := &WrapperMsgTransfer{}
+	assert.Nil(t, w.HandleMsg(MsgTransfer, msg, log))
+	assert.Equal(t, uint64(7), w.Sequence)
+	assert.Equal(t, "channel-141", w.SourceChannel)
+	assert.Equal(t, "channel-0", w.DestinationChannel)
+
+	relevantData := w.ParseRelevantData()
+	assert.Len(t, relevantData, 1)
+	assert.Equal(t, "cosmos1sender", relevantData[0].SenderAddress)
+	assert.Equal(t, "osmo1receiver", relevantData[0].ReceiverAddress)
+	assert.Equal(t, int64(100), relevantData[0].AmountSent.Int64())
+	assert.Equal(t, "uatom", relevantData[0].DenominationSent)
+}
+
+func TestMsgTimeoutRefund(t *testing.T) {
+	// A token that came from another chain is refunded in its IBC denom
+	packet := transferPacket("transfer/channel-0/uosmo")
+	msg := chantypes.NewMsgTimeout(packet, 1, nil, clienttypes.ZeroHeight(), "relayer")
+	log := &txModule.LogMessage{
+		Events: []txModule.LogMessageEvent{
+			{Type: "message", Attributes: []txModule.Attribute{{Key: "action", Value: MsgTimeout}}},
+			{Type: chantypes.EventTypeTimeoutPacket},
+		},
+	}
+
+	w := &WrapperMsgTimeout{}
+	assert.Nil(t, w.HandleMsg(MsgTimeout, msg, log))
+
+	relevantData := w.ParseRelevantData()
+	assert.Len(t, relevantData, 1)
+	assert.Equal(t, "cosmos1sender", relevantData[0].ReceiverAddress)
+	assert.Equal(t, int64(100), relevantData[0].AmountReceived.Int64())
+	assert.Equal(t, types.ParseDenomTrace("transfer/channel-0/uosmo").IBCDenom(), relevantData[0].DenominationReceived)
+}
+
+func TestMsgTimeoutAlreadyRelayed(t *testing.T) {
+	packet := transferPacket("uatom")
+	msg := chantypes.NewMsgTimeout(packet, 1, nil, clienttypes.ZeroHeight(), "relayer")
+	// No timeout_packet event, another relayer already timed the packet out
+	log := &txModule.LogMessage{
+		Events: []txModule.LogMessageEvent{
+			{Type: "message", Attributes: []txModule.Attribute{{Key: "action", Value: MsgTimeout}}},
+		},
+	}
+
+	w := &WrapperMsgTimeout{}
+	assert.Nil(t, w.HandleMsg(MsgTimeout, msg, log))
+	assert.Nil(t, w.ParseRelevantData())
+}
+
+func TestMsgAcknowledgement(t *testing.T) {
+	packet := transferPacket("uatom")
+	log := &txModule.LogMessage{
+		Events: []txModule.LogMessageEvent{
+			{Type: "message", Attributes: []txModule.Attribute{{Key: "action", Value: MsgAcknowledgement}}},
+			{Type: chantypes.EventTypeAcknowledgePacket},
+		},
+	}
+
+	// The send is indexed with the MsgTransfer, a successful ack adds nothing
+	success := chantypes.NewResultAcknowledgement([]byte{1})
+	w := &WrapperMsgAcknowledgement{}
+	assert.Nil(t, w.HandleMsg(MsgAcknowledgement, chantypes.NewMsgAcknowledgement(packet, success.Acknowledgement(), nil, clienttypes.ZeroHeight(), "relayer"), log))
+	assert.Nil(t, w.ParseRelevantData())
+
+	failure := chantypes.NewErrorAcknowledgement(types.ErrInvalidAmount)
+	w = &WrapperMsgAcknowledgement{}
+	assert.Nil(t, w.HandleMsg(MsgAcknowledgement, chantypes.NewMsgAcknowledgement(packet, failure.Acknowledgement(), nil, clienttypes.ZeroHeight(), "relayer"), log))
+
+	relevantData := w.ParseRelevantData()
+	assert.Len(t, relevantData, 1)
+	assert.Equal(t, "cosmos1sender", relevantData[0].ReceiverAddress)
+	assert.Equal(t, int64(100), relevantData[0].AmountReceived.Int64())
+	assert.Equal(t, "uatom", relevantData[0].DenominationReceived)
+}
@@ -9,4 +9,5 @@ type MessageRelevantInformation struct {
 	AmountReceived       *big.Int
 	DenominationSent     string
 	DenominationReceived string
+	Inferred             bool // derived from the bank events of the message rather than by a parser for its type
 }
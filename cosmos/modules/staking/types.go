@@ -5,11 +5,11 @@ import (
 	"strings"
 
 	parsingTypes "github.com/DefiantLabs/cosmos-tax-cli/cosmos/modules"
+	"github.com/DefiantLabs/cosmos-tax-cli/cosmos/modules/distribution"
 	txModule "github.com/DefiantLabs/cosmos-tax-cli/cosmos/modules/tx"
 	"github.com/DefiantLabs/cosmos-tax-cli/util"
 
 	stdTypes "github.com/cosmos/cosmos-sdk/types"
-	bankTypes "github.com/cosmos/cosmos-sdk/x/bank/types"
 	stakeTypes "github.com/cosmos/cosmos-sdk/x/staking/types"
 )
 
@@ -24,28 +24,27 @@ const (
 
 type WrapperMsgDelegate struct {
 	txModule.Message
-	CosmosMsgDelegate     *stakeTypes.MsgDelegate
-	DelegatorAddress      string
-	AutoWithdrawalReward  *stdTypes.Coin
-	AutoWithdrawalRewards stdTypes.Coins
+	CosmosMsgDelegate    *stakeTypes.MsgDelegate
+	DelegatorAddress     string
+	AutoWithdrawnRewards []distribution.WithdrawnReward
 }
 
 type WrapperMsgUndelegate struct {
 	txModule.Message
-	CosmosMsgUndelegate   *stakeTypes.MsgUndelegate
-	DelegatorAddress      string
-	AutoWithdrawalReward  *stdTypes.Coin
-	AutoWithdrawalRewards stdTypes.Coins
+	CosmosMsgUndelegate  *stakeTypes.MsgUndelegate
+	DelegatorAddress     string
+	AutoWithdrawnRewards []distribution.WithdrawnReward
 }
 
+// Redelegating withdraws the rewards of the delegations to both the source and the destination validator
 type WrapperMsgBeginRedelegate struct {
 	txModule.Message
 	CosmosMsgBeginRedelegate *stakeTypes.MsgBeginRedelegate
 	DelegatorAddress         string
-	AutoWithdrawalRewards    stdTypes.Coins
+	AutoWithdrawnRewards     []distribution.WithdrawnReward
 }
 
-// HandleMsg: Handle type checking for MsgFundCommunityPool
+// HandleMsg: Handle type checking for MsgDelegate
 func (sf *WrapperMsgDelegate) HandleMsg(msgType string, msg stdTypes.Msg, log *txModule.LogMessage) error {
 	sf.Type = msgType
 	sf.CosmosMsgDelegate = msg.(*stakeTypes.MsgDelegate)
@@ -56,40 +55,18 @@ func (sf *WrapperMsgDelegate) HandleMsg(msgType string, msg stdTypes.Msg, log *t
 		return util.ReturnInvalidLog(msgType, log)
 	}
 
-	// The attribute in the log message that shows you the delegator rewards auto-received
-	delegatorReceivedCoinsEvt := txModule.GetEventWithType(bankTypes.EventTypeTransfer, log)
-	if delegatorReceivedCoinsEvt == nil {
-		sf.AutoWithdrawalReward = nil
-		sf.DelegatorAddress = sf.CosmosMsgDelegate.DelegatorAddress
-	} else {
-		delegatorAddress, err := txModule.GetValueForAttribute("recipient", delegatorReceivedCoinsEvt)
-		if err != nil {
-			return err
-		}
-
-		sf.DelegatorAddress = delegatorAddress
-
-		coinsReceived, err := txModule.GetValueForAttribute("amount", delegatorReceivedCoinsEvt)
-		if err != nil {
-			return err
-		}
-
-		coin, err := stdTypes.ParseCoinNormalized(coinsReceived)
-		if err != nil {
-			sf.AutoWithdrawalRewards, err = stdTypes.ParseCoinsNormalized(coinsReceived)
-			if err != nil {
-				fmt.Println("Error parsing coins normalized")
-				fmt.Println(err)
-				return err
-			}
-			return nil
-		}
-		sf.AutoWithdrawalReward = &coin
+	// Changing a delegation withdraws its pending rewards, the withdraw_rewards events show them
+	sf.DelegatorAddress = sf.CosmosMsgDelegate.DelegatorAddress
+	rewards, err := distribution.GetWithdrawnRewards(log, sf.DelegatorAddress)
+	if err != nil {
+		return &txModule.MessageLogFormatError{MessageType: msgType, Log: fmt.Sprintf("%+v", log)}
 	}
+	sf.AutoWithdrawnRewards = rewards
 
 	return nil
 }
 
+// HandleMsg: Handle type checking for MsgUndelegate
 func (sf *WrapperMsgUndelegate) HandleMsg(msgType string, msg stdTypes.Msg, log *txModule.LogMessage) error {
 	sf.Type = msgType
 	sf.CosmosMsgUndelegate = msg.(*stakeTypes.MsgUndelegate)
@@ -100,49 +77,18 @@ func (sf *WrapperMsgUndelegate) HandleMsg(msgType string, msg stdTypes.Msg, log
 		return util.ReturnInvalidLog(msgType, log)
 	}
 
-	// The attribute in the log message that shows you the delegator rewards auto-received
+	// Changing a delegation withdraws its pending rewards, the withdraw_rewards events show them
 	sf.DelegatorAddress = sf.CosmosMsgUndelegate.DelegatorAddress
-	delegatorReceivedCoinsEvt := txModule.GetEventWithType(bankTypes.EventTypeCoinReceived, log)
-	if delegatorReceivedCoinsEvt == nil {
-		sf.AutoWithdrawalReward = nil
-		sf.DelegatorAddress = sf.CosmosMsgUndelegate.DelegatorAddress
-	} else {
-		var receivers []string
-		var amounts []string
-
-		// Pair off amounts and receivers in order
-		for _, v := range delegatorReceivedCoinsEvt.Attributes {
-			if v.Key == "receiver" {
-				receivers = append(receivers, v.Value)
-			} else if v.Key == "amount" {
-				amounts = append(amounts, v.Value)
-			}
-		}
-
-		// Find delegator address in receivers if its there, find its paired amount and set as the withdrawn rewards
-		for i, v := range receivers {
-			if v == sf.DelegatorAddress {
-				coin, err := stdTypes.ParseCoinNormalized(amounts[i])
-				if err != nil {
-					var coins stdTypes.Coins
-					coins, err = stdTypes.ParseCoinsNormalized(amounts[i])
-					if err != nil {
-						fmt.Println("Error parsing coins normalized")
-						fmt.Println(err)
-						return &txModule.MessageLogFormatError{MessageType: msgType, Log: fmt.Sprintf("%+v", log)}
-					}
-					sf.AutoWithdrawalRewards = append(sf.AutoWithdrawalRewards, coins...)
-					continue
-				}
-				sf.AutoWithdrawalReward = &coin
-			}
-		}
+	rewards, err := distribution.GetWithdrawnRewards(log, sf.DelegatorAddress)
+	if err != nil {
+		return &txModule.MessageLogFormatError{MessageType: msgType, Log: fmt.Sprintf("%+v", log)}
 	}
+	sf.AutoWithdrawnRewards = rewards
 
 	return nil
 }
 
-// HandleMsg: Handle type checking for MsgFundCommunityPool
+// HandleMsg: Handle type checking for MsgBeginRedelegate
 func (sf *WrapperMsgBeginRedelegate) HandleMsg(msgType string, msg stdTypes.Msg, log *txModule.LogMessage) error {
 	sf.Type = msgType
 	sf.CosmosMsgBeginRedelegate = msg.(*stakeTypes.MsgBeginRedelegate)
@@ -153,136 +99,64 @@ func (sf *WrapperMsgBeginRedelegate) HandleMsg(msgType string, msg stdTypes.Msg,
 		return util.ReturnInvalidLog(msgType, log)
 	}
 
-	// The attribute in the log message that shows you the delegator rewards auto-received
-	delegatorReceivedCoinsEvt := txModule.GetEventWithType(bankTypes.EventTypeCoinReceived, log)
+	// Changing a delegation withdraws its pending rewards, the withdraw_rewards events show them
 	sf.DelegatorAddress = sf.CosmosMsgBeginRedelegate.DelegatorAddress
-	if delegatorReceivedCoinsEvt == nil {
-		sf.AutoWithdrawalRewards = make(stdTypes.Coins, 0)
-	} else {
-		var receivers []string
-		var amounts []string
-
-		// Pair off amounts and receivers in order
-		for _, v := range delegatorReceivedCoinsEvt.Attributes {
-			if v.Key == "receiver" {
-				receivers = append(receivers, v.Value)
-			} else if v.Key == "amount" {
-				amounts = append(amounts, v.Value)
-			}
-		}
-
-		// Find delegator address in receivers if its there, find its paired amount and set as the withdrawn rewards
-		// We use a cosmos.Coins array type for redelegations as redelegating could force withdrawal from both validators
-		for i, v := range receivers {
-			if v == sf.DelegatorAddress {
-				coin, err := stdTypes.ParseCoinNormalized(amounts[i])
-				if err != nil {
-					var coins stdTypes.Coins
-					coins, err = stdTypes.ParseCoinsNormalized(amounts[i])
-					if err != nil {
-						fmt.Println("Error parsing coins normalized")
-						fmt.Println(err)
-						return &txModule.MessageLogFormatError{MessageType: msgType, Log: fmt.Sprintf("%+v", log)}
-					}
-					sf.AutoWithdrawalRewards = append(sf.AutoWithdrawalRewards, coins...)
-					continue
-				}
-				sf.AutoWithdrawalRewards = append(sf.AutoWithdrawalRewards, coin)
-			}
-		}
+	rewards, err := distribution.GetWithdrawnRewards(log, sf.DelegatorAddress)
+	if err != nil {
+		return &txModule.MessageLogFormatError{MessageType: msgType, Log: fmt.Sprintf("%+v", log)}
 	}
+	sf.AutoWithdrawnRewards = rewards
 
 	return nil
 }
 
 func (sf *WrapperMsgDelegate) ParseRelevantData() []parsingTypes.MessageRelevantInformation {
-	var relevantData []parsingTypes.MessageRelevantInformation
-	if sf.AutoWithdrawalReward != nil {
-		data := parsingTypes.MessageRelevantInformation{}
-		data.AmountReceived = sf.AutoWithdrawalReward.Amount.BigInt()
-		data.DenominationReceived = sf.AutoWithdrawalReward.Denom
-		data.ReceiverAddress = sf.DelegatorAddress
-		relevantData = append(relevantData, data)
-	} else if len(sf.AutoWithdrawalRewards) > 0 {
-		for _, coin := range sf.AutoWithdrawalRewards {
-			data := parsingTypes.MessageRelevantInformation{}
-			data.AmountReceived = coin.Amount.BigInt()
-			data.DenominationReceived = coin.Denom
-			data.ReceiverAddress = sf.DelegatorAddress
-			relevantData = append(relevantData, data)
-		}
-	}
-	return relevantData
+	return withdrawnRewardsRelevantData(sf.AutoWithdrawnRewards)
 }
 
 func (sf *WrapperMsgUndelegate) ParseRelevantData() []parsingTypes.MessageRelevantInformation {
+	return withdrawnRewardsRelevantData(sf.AutoWithdrawnRewards)
+}
+
+func (sf *WrapperMsgBeginRedelegate) ParseRelevantData() []parsingTypes.MessageRelevantInformation {
+	return withdrawnRewardsRelevantData(sf.AutoWithdrawnRewards)
+}
+
+// withdrawnRewardsRelevantData credits every coin of the rewards to the address the reward was sent to
+func withdrawnRewardsRelevantData(rewards []distribution.WithdrawnReward) []parsingTypes.MessageRelevantInformation {
 	var relevantData []parsingTypes.MessageRelevantInformation
-	if sf.AutoWithdrawalReward != nil {
-		data := parsingTypes.MessageRelevantInformation{}
-		data.AmountReceived = sf.AutoWithdrawalReward.Amount.BigInt()
-		data.DenominationReceived = sf.AutoWithdrawalReward.Denom
-		data.ReceiverAddress = sf.DelegatorAddress
-		relevantData = append(relevantData, data)
-	} else if len(sf.AutoWithdrawalRewards) > 0 {
-		for _, coin := range sf.AutoWithdrawalRewards {
+	for _, reward := range rewards {
+		for _, coin := range reward.Amount {
 			data := parsingTypes.MessageRelevantInformation{}
 			data.AmountReceived = coin.Amount.BigInt()
 			data.DenominationReceived = coin.Denom
-			data.ReceiverAddress = sf.DelegatorAddress
+			data.ReceiverAddress = reward.Recipient
 			relevantData = append(relevantData, data)
 		}
 	}
 	return relevantData
 }
 
-func (sf *WrapperMsgBeginRedelegate) ParseRelevantData() []parsingTypes.MessageRelevantInformation {
-	var relevantData []parsingTypes.MessageRelevantInformation
-	for _, coin := range sf.AutoWithdrawalRewards {
-		data := parsingTypes.MessageRelevantInformation{}
-		data.AmountReceived = coin.Amount.BigInt()
-		data.DenominationReceived = coin.Denom
-		data.ReceiverAddress = sf.DelegatorAddress
-		relevantData = append(relevantData, data)
-	}
-	return relevantData
-}
-
 func (sf *WrapperMsgDelegate) String() string {
-	if sf.AutoWithdrawalReward != nil {
-		return fmt.Sprintf("MsgDelegate: Delegator %s auto-withdrew %s", sf.DelegatorAddress, sf.AutoWithdrawalReward)
-	}
-	if len(sf.AutoWithdrawalRewards) > 0 {
-		var coinsRecievedStrings []string
-		for _, coin := range sf.AutoWithdrawalRewards {
-			coinsRecievedStrings = append(coinsRecievedStrings, coin.String())
-			return fmt.Sprintf("MsgDelegate: Delegator %s auto-withdrew %s", sf.DelegatorAddress, strings.Join(coinsRecievedStrings, ", "))
-		}
-	}
-	return fmt.Sprintf("MsgDelegate: Delegator %s did not auto-withdrawal rewards", sf.DelegatorAddress)
+	return fmt.Sprintf("MsgDelegate: Delegator %s %s", sf.DelegatorAddress, withdrawnRewardsString(sf.AutoWithdrawnRewards))
 }
 
 func (sf *WrapperMsgUndelegate) String() string {
-	if sf.AutoWithdrawalReward != nil {
-		return fmt.Sprintf("MsgUndelegate: Delegator %s auto-withdrew %s", sf.DelegatorAddress, sf.AutoWithdrawalReward)
-	}
-	if len(sf.AutoWithdrawalRewards) > 0 {
-		var coinsRecievedStrings []string
-		for _, coin := range sf.AutoWithdrawalRewards {
-			coinsRecievedStrings = append(coinsRecievedStrings, coin.String())
-			return fmt.Sprintf("MsgUndelegate: Delegator %s auto-withdrew %s", sf.DelegatorAddress, strings.Join(coinsRecievedStrings, ", "))
-		}
-	}
-	return fmt.Sprintf("MsgUndelegate: Delegator %s did not auto-withdrawal rewards", sf.DelegatorAddress)
+	return fmt.Sprintf("MsgUndelegate: Delegator %s %s", sf.DelegatorAddress, withdrawnRewardsString(sf.AutoWithdrawnRewards))
 }
 
 func (sf *WrapperMsgBeginRedelegate) String() string {
-	var coinsRecievedStrings []string
-	for _, coin := range sf.AutoWithdrawalRewards {
-		coinsRecievedStrings = append(coinsRecievedStrings, coin.String())
+	return fmt.Sprintf("MsgBeginRedelegate: Delegator %s %s", sf.DelegatorAddress, withdrawnRewardsString(sf.AutoWithdrawnRewards))
+}
+
+func withdrawnRewardsString(rewards []distribution.WithdrawnReward) string {
+	if len(rewards) == 0 {
+		return "did not auto-withdrawal rewards"
 	}
 
-	if len(coinsRecievedStrings) > 0 {
-		return fmt.Sprintf("MsgBeginRedelegate: Delegator %s auto-withdrew %s", sf.DelegatorAddress, strings.Join(coinsRecievedStrings, ", "))
+	var rewardStrings []string
+	for _, reward := range rewards {
+		rewardStrings = append(rewardStrings, fmt.Sprintf("%s from validator %s to %s", reward.Amount, reward.Validator, reward.Recipient))
 	}
-	return fmt.Sprintf("MsgBeginRedelegate: Delegator %s did not auto-withdrawal rewards", sf.DelegatorAddress)
+	return fmt.Sprintf("auto-withdrew %s", strings.Join(rewardStrings, ", "))
 }
@@ -0,0 +1,18 @@
+package cosmoshub
+
+import "github.com/DefiantLabs/cosmos-tax-cli/core"
+
+func init() {
+	core.RegisterChainPlugin(plugin{})
+}
+
+// plugin registers the Cosmos Hub EndBlocker event handlers
+type plugin struct{}
+
+func (plugin) ChainID() string {
+	return ChainID
+}
+
+func (plugin) Register(registry *core.Registry) {
+	registry.AddEndBlockerEventTypeHandlers(EndBlockerEventTypeHandlers)
+}
@@ -7,23 +7,22 @@ import (
 	"github.com/DefiantLabs/lens/client"
 )
 
-var defaultMessageTypeHandler = map[string][]func() txTypes.CosmosMessage{}
-
-var contractAddressRegistry = map[string]wasm.ContractExecutionMessageHandler{}
-
+// GetCosmWasmMessageTypeHandlers returns new handlers for every call, the contract addresses they look up are those
+// of the chain of the client
 func GetCosmWasmMessageTypeHandlers(customContractAddressHandlers []wasm.ContractExecutionMessageHandler, lensClient *client.ChainClient) (map[string][]func() txTypes.CosmosMessage, error) {
 	msgExecuteContractHandlers, err := configureMsgExecuteContractHandler(customContractAddressHandlers, lensClient)
 	if err != nil {
 		return nil, err
 	}
 
-	defaultMessageTypeHandler[wasm.MsgExecuteContract] = msgExecuteContractHandlers
-
-	return defaultMessageTypeHandler, nil
+	return map[string][]func() txTypes.CosmosMessage{
+		wasm.MsgExecuteContract: msgExecuteContractHandlers,
+	}, nil
 }
 
 // Configures a handler wrapper that will allow using registry values to find custom message handlers
 func configureMsgExecuteContractHandler(customContractAddressHandlers []wasm.ContractExecutionMessageHandler, lensClient *client.ChainClient) ([]func() txTypes.CosmosMessage, error) {
+	contractAddressRegistry := map[string]wasm.ContractExecutionMessageHandler{}
 	for _, handler := range customContractAddressHandlers {
 		if castHandler, ok := handler.(wasm.ContractExecutionMessageHandlerByContractAddress); ok {
 			contractAddressRegistry[castHandler.ContractAddress()] = handler
@@ -59,6 +59,51 @@ func TestAccointingIbcMsgTransferSelf(t *testing.T) {
 	}
 }
 
+// Test behavior of an IBC message transfer to our own address that was linked to its packet on the other chain
+func TestAccointingIbcMsgTransferLinkedSelf(t *testing.T) {
+	cfg := config.IndexConfig{}
+	cfg.Lens.ChainID = osmosis.ChainID
+	parser := GetParser(accointing.ParserKey)
+	parser.InitializeParsingGroups()
+
+	me := "osmo18zljeu4lg4jppkz75en82qr3zymfcnchwvsqgu"
+	alsoMe := "juno18zljeu4lg4jppkz75en82qr3zymfcnchs9qtej"
+
+	// setup user and chain
+	sourceAddress := db.Address{
+		ID:      0,
+		Address: me,
+	}
+	destAddress := db.Address{
+		ID:      1,
+		Address: alsoMe,
+	}
+
+	sourceChain := mkChain(1, osmosis.ChainID, osmosis.Name)
+	targetChain := mkChain(2, "juno-1", "juno")
+
+	transferTxs := getTestIbcTransferTXs(t, sourceAddress, destAddress, sourceChain, targetChain)
+	// set by the export for transfers linked between two of the exported addresses
+	transferTxs[0].SelfTransfer = true
+	emptyFees := []db.Fee{}
+
+	// attempt to parse
+	err := parser.ProcessTaxableTx(sourceAddress.Address, transferTxs, emptyFees)
+	assert.Nil(t, err, "should not get error from parsing these transactions")
+
+	// validate output
+	rows, err := parser.GetRows(sourceAddress.Address, nil, nil)
+	assert.Nil(t, err, "should not get error from getting rows")
+	assert.Equalf(t, len(transferTxs), len(rows), "you should have one row for each transfer transaction ")
+
+	for _, row := range rows {
+		cols := row.GetRowForCsv()
+		// the transfer is still a 'withdraw', but only moves funds between our own wallets
+		assert.Equal(t, "withdraw", cols[0], "transaction type should be a withdrawal")
+		assert.Equal(t, accointing.Internal.String(), cols[8], "transaction should be classified as internal")
+	}
+}
+
 // Test behavior of an IBC message transfer to someone else's address (e.g. NOT a self transfer)
 func TestAccointingIbcMsgTransferExternal(t *testing.T) {
 	cfg := config.IndexConfig{}
@@ -0,0 +1,42 @@
+// nolint:unused
+package csv
+
+import (
+	"testing"
+
+	"github.com/DefiantLabs/cosmos-tax-cli/config"
+	"github.com/DefiantLabs/cosmos-tax-cli/csv/parsers/cryptotaxcalculator"
+	"github.com/DefiantLabs/cosmos-tax-cli/db"
+	"github.com/DefiantLabs/cosmos-tax-cli/osmosis"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test that the refunds of a failed acknowledgement and a timeout are transfers of our own tokens back, not buys
+func TestCryptoTaxCalculatorIbcRefund(t *testing.T) {
+	cfg := config.IndexConfig{}
+	cfg.Lens.ChainID = osmosis.ChainID
+	parser := GetParser(cryptotaxcalculator.ParserKey)
+	parser.InitializeParsingGroups()
+
+	me := db.Address{ID: 0, Address: "osmo18zljeu4lg4jppkz75en82qr3zymfcnchwvsqgu"}
+	counterparty := db.Address{ID: 1, Address: "juno18zljeu4lg4jppkz75en82qr3zymfcnchs9qtej"}
+	chain := mkChain(1, osmosis.ChainID, osmosis.Name)
+
+	refundTxs := getTestIbcRefundTXs(t, me, counterparty, chain)
+
+	// attempt to parse
+	err := parser.ProcessTaxableTx(me.Address, refundTxs, []db.Fee{})
+	assert.Nil(t, err, "should not get error from parsing these transactions")
+
+	// validate output
+	rows, err := parser.GetRows(me.Address, nil, nil)
+	assert.Nil(t, err, "should not get error from getting rows")
+	assert.Equalf(t, len(refundTxs), len(rows), "you should have one row for each refund")
+
+	for _, row := range rows {
+		cols := row.GetRowForCsv()
+		assert.Equal(t, cryptotaxcalculator.TransferIn, cols[1], "refund should be a transfer in")
+		assert.Equal(t, me.Address, cols[9], "refund should be received by the sender of the failed transfer")
+	}
+}
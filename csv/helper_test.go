@@ -78,6 +78,39 @@ func getTestIbcTransferTXs(t *testing.T, sourceAddress db.Address, targetAddress
 	return []db.TaxableTransaction{taxableTX1}
 }
 
+func getTestIbcRefundTXs(t *testing.T, sourceAddress db.Address, counterpartyAddress db.Address, sourceChain db.Chain) []db.TaxableTransaction {
+	// BlockTimes
+	oneYearAgo := time.Now().Add(-1 * time.Hour * 24 * 365)
+
+	// the refunds on the source chain
+	block1 := mkBlk(1, 1, oneYearAgo, sourceChain)
+
+	// create the refunding msgs
+	msgTypeAcknowledgement := mkMsgType(1, ibc.MsgAcknowledgement)
+	msgTypeTimeout := mkMsgType(2, ibc.MsgTimeout)
+
+	// create TXs, relayed by the counterparty
+	ackTX := mkTx(1, "somehash1", 0, block1, counterpartyAddress, nil)
+	timeoutTX := mkTx(2, "somehash2", 0, block1, counterpartyAddress, nil)
+
+	// create Msgs
+	ackMsg := mkMsg(1, ackTX, msgTypeAcknowledgement, 0)
+	timeoutMsg := mkMsg(2, timeoutTX, msgTypeTimeout, 0)
+
+	// create denoms
+	coin1, coin1DenomUnit := mkDenom(1, "uosmo", "Osmosis", "OSMO")
+
+	// populate denom cache
+	db.CachedDenomUnits = []db.DenomUnit{coin1DenomUnit}
+
+	// create taxable transactions
+	// refunds go from the counterparty receiver back to the sender of the failed transfer
+	taxableTX1 := mkTaxableTransaction(1, ackMsg, decimal.NewFromInt(1000000), decimal.NewFromInt(1000000), coin1, coin1, counterpartyAddress, sourceAddress)
+	taxableTX2 := mkTaxableTransaction(2, timeoutMsg, decimal.NewFromInt(2000000), decimal.NewFromInt(2000000), coin1, coin1, counterpartyAddress, sourceAddress)
+
+	return []db.TaxableTransaction{taxableTX1, taxableTX2}
+}
+
 func getTestSwapTXs(t *testing.T, targetAddress db.Address, targetChain db.Chain) []db.TaxableTransaction {
 	randoAddress := mkAddress(t, 2)
 
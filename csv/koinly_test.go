@@ -85,3 +85,34 @@ func TestKoinlyOsmoRewardParsing(t *testing.T) {
 		assert.Equal(t, cols[9], "reward")
 	}
 }
+
+// Test that the refunds of a failed acknowledgement and a timeout are deposits of our own tokens, not income
+func TestKoinlyIbcRefund(t *testing.T) {
+	cfg := config.IndexConfig{}
+	cfg.Lens.ChainID = osmosis.ChainID
+	parser := GetParser(koinly.ParserKey)
+	parser.InitializeParsingGroups()
+
+	me := db.Address{ID: 0, Address: "osmo18zljeu4lg4jppkz75en82qr3zymfcnchwvsqgu"}
+	counterparty := db.Address{ID: 1, Address: "juno18zljeu4lg4jppkz75en82qr3zymfcnchs9qtej"}
+	chain := mkChain(1, osmosis.ChainID, osmosis.Name)
+
+	refundTxs := getTestIbcRefundTXs(t, me, counterparty, chain)
+
+	// attempt to parse
+	err := parser.ProcessTaxableTx(me.Address, refundTxs, []db.Fee{})
+	assert.Nil(t, err, "should not get error from parsing these transactions")
+
+	// validate output
+	rows, err := parser.GetRows(me.Address, nil, nil)
+	assert.Nil(t, err, "should not get error from getting rows")
+	assert.Equalf(t, len(refundTxs), len(rows), "you should have one row for each refund")
+
+	for _, row := range rows {
+		cols := row.GetRowForCsv()
+		// the refund is received back, without a label koinly treats it as a deposit rather than income
+		assert.Equal(t, "", cols[1], "refund should not send anything")
+		assert.NotEqual(t, "", cols[3], "refund should be received")
+		assert.Equal(t, koinly.None.String(), cols[9], "refund should not be labelled")
+	}
+}
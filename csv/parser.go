@@ -58,12 +58,22 @@ func ParseForAddress(addresses []string, startDate, endDate *time.Time, pgSQL *g
 
 	addressRowsCount := make(map[string]uint)
 
+	// IBC transfers between the addresses being exported only move funds between wallets of the same owner
+	selfTransfers, err := db.GetSelfTransferMessageIDs(pgSQL, addresses)
+	if err != nil {
+		config.Log.Error("Error getting IBC self transfers.", err)
+		return nil, nil, nil, err
+	}
+
 	for _, address := range addresses {
 		taxableTxs, err := db.GetTaxableTransactions(address, pgSQL)
 		if err != nil {
 			config.Log.Error("Error getting taxable transaction.", err)
 			return nil, nil, nil, err
 		}
+		for i := range taxableTxs {
+			_, taxableTxs[i].SelfTransfer = selfTransfers[taxableTxs[i].MessageID]
+		}
 
 		// Some TXs may have fees while the address had no taxable TXs
 		// We gather all fees and pass them to the parser
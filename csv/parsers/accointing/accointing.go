@@ -251,6 +251,8 @@ func ParseTx(address string, events []db.TaxableTransaction) (rows []parsers.Csv
 			newRow, err = ParseMsgAcknowledgement(address, event)
 		case ibc.MsgRecvPacket:
 			newRow, err = ParseMsgRecvPacket(address, event)
+		case ibc.MsgTimeout, ibc.MsgTimeoutOnClose:
+			newRow, err = ParseMsgTransfer(address, event)
 		case poolmanager.MsgSplitRouteSwapExactAmountIn, poolmanager.MsgSwapExactAmountIn, poolmanager.MsgSwapExactAmountOut, poolmanager.MsgSplitRouteSwapExactAmountOut:
 			newRow, err = ParsePoolManagerSwap(event)
 		case concentratedliquidity.MsgCollectIncentives, concentratedliquidity.MsgCollectSpreadRewards:
@@ -370,6 +372,7 @@ func ParseMsgTransfer(address string, event db.TaxableTransaction) (Row, error)
 	if err != nil {
 		config.Log.Error("Error with ParseMsgTransfer.", err)
 	}
+	row.classifySelfTransfer(event)
 	return *row, err
 }
 
@@ -378,6 +381,11 @@ func ParseMsgAcknowledgement(address string, event db.TaxableTransaction) (Row,
 
 	denomToUse := event.DenominationSent
 	amountToUse := event.AmountSent
+	// Failed acknowledgements refund the sender
+	if event.ReceiverAddress.Address == address {
+		denomToUse = event.DenominationReceived
+		amountToUse = event.AmountReceived
+	}
 
 	conversionAmount, conversionSymbol, err := db.ConvertUnits(util.FromNumeric(amountToUse), denomToUse)
 	if err != nil {
@@ -425,6 +433,7 @@ func ParseMsgRecvPacket(address string, event db.TaxableTransaction) (Row, error
 
 	row.Date = event.Message.Tx.Block.TimeStamp.Format(TimeLayout)
 	row.OperationID = event.Message.Tx.Hash
+	row.classifySelfTransfer(event)
 	return *row, err
 }
 
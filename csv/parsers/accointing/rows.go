@@ -70,6 +70,15 @@ func (row *Row) ParseBasic(address string, event db.TaxableTransaction) error {
 	return nil
 }
 
+// classifySelfTransfer classifies an IBC transfer between the exported addresses as internal, it moves funds between
+// the user's own wallets
+func (row *Row) classifySelfTransfer(event db.TaxableTransaction) {
+	if event.SelfTransfer {
+		row.Classification = Internal
+		row.Comments = "IBC transfer between own wallets"
+	}
+}
+
 func (row *Row) ParseSwap(event db.TaxableTransaction) error {
 	row.Date = event.Message.Tx.Block.TimeStamp.Format(TimeLayout)
 	row.OperationID = event.Message.Tx.Hash
@@ -213,6 +213,8 @@ func ParseTx(address string, events []db.TaxableTransaction, fees []db.Fee) (row
 			newRow, err = ParseMsgAcknowledgement(address, event)
 		case ibc.MsgRecvPacket:
 			newRow, err = ParseMsgRecvPacket(address, event)
+		case ibc.MsgTimeout, ibc.MsgTimeoutOnClose:
+			newRow, err = ParseMsgTransfer(address, event)
 		case poolmanager.MsgSplitRouteSwapExactAmountIn, poolmanager.MsgSwapExactAmountIn, poolmanager.MsgSwapExactAmountOut, poolmanager.MsgSplitRouteSwapExactAmountOut:
 			newRow, err = ParsePoolManagerSwap(event)
 		case concentratedliquidity.MsgCollectIncentives, concentratedliquidity.MsgCollectSpreadRewards:
@@ -353,6 +355,11 @@ func ParseMsgAcknowledgement(address string, event db.TaxableTransaction) (Row,
 
 	denomToUse := event.DenominationSent
 	amountToUse := event.AmountSent
+	// Failed acknowledgements refund the sender
+	if event.ReceiverAddress.Address == address {
+		denomToUse = event.DenominationReceived
+		amountToUse = event.AmountReceived
+	}
 
 	conversionAmount, conversionSymbol, err := db.ConvertUnits(util.FromNumeric(amountToUse), denomToUse)
 	if err != nil {
@@ -214,6 +214,8 @@ func ParseTx(address string, events []db.TaxableTransaction) (rows []parsers.Csv
 			newRow, err = ParseMsgAcknowledgement(address, event)
 		case ibc.MsgRecvPacket:
 			newRow, err = ParseMsgRecvPacket(address, event)
+		case ibc.MsgTimeout, ibc.MsgTimeoutOnClose:
+			newRow, err = ParseIBCRefund(address, event)
 		case poolmanager.MsgSplitRouteSwapExactAmountIn, poolmanager.MsgSwapExactAmountIn, poolmanager.MsgSwapExactAmountOut, poolmanager.MsgSplitRouteSwapExactAmountOut:
 			newRow, err = ParsePoolManagerSwap(address, event)
 		case concentratedliquidity.MsgCollectIncentives, concentratedliquidity.MsgCollectSpreadRewards:
@@ -313,10 +315,31 @@ func ParseMsgTransfer(address string, event db.TaxableTransaction) (Row, error)
 	if err != nil {
 		config.Log.Error("Error with ParseMsgTransfer.", err)
 	}
+	row.typeSelfTransfer(address, event)
+	return *row, err
+}
+
+// ParseIBCRefund parses the refund of a timed out or failed IBC transfer. The sender gets its own tokens back, so the
+// deposit is a transfer in rather than a buy.
+func ParseIBCRefund(address string, event db.TaxableTransaction) (Row, error) {
+	row := &Row{}
+	err := row.ParseBasic(address, event)
+	if err != nil {
+		config.Log.Error("Error with ParseIBCRefund.", err)
+	}
+	if event.ReceiverAddress.Address == address {
+		row.Type = TransferIn
+	}
+	row.Description = "Refund of a failed IBC transfer"
 	return *row, err
 }
 
 func ParseMsgAcknowledgement(address string, event db.TaxableTransaction) (Row, error) {
+	// Failed acknowledgements refund the sender
+	if event.ReceiverAddress.Address == address {
+		return ParseIBCRefund(address, event)
+	}
+
 	row := &Row{}
 
 	denomToUse := event.DenominationSent
@@ -331,9 +354,7 @@ func ParseMsgAcknowledgement(address string, event db.TaxableTransaction) (Row,
 	row.BaseAmount = conversionAmount.Text('f', -1)
 	row.BaseCurrency = conversionSymbol
 
-	if event.ReceiverAddress.Address == address {
-		row.Type = Buy
-	} else if event.SenderAddress.Address == address { // withdrawal
+	if event.SenderAddress.Address == address { // withdrawal
 		row.Type = Sell
 	}
 
@@ -371,6 +392,7 @@ func ParseMsgRecvPacket(address string, event db.TaxableTransaction) (Row, error
 
 	row.Date = event.Message.Tx.Block.TimeStamp.Format(TimeLayout)
 	row.ID = event.Message.Tx.Hash
+	row.typeSelfTransfer(address, event)
 	return *row, err
 }
 
@@ -104,6 +104,20 @@ func (row *Row) ParseFee(fee db.Fee) error {
 	return nil
 }
 
+// typeSelfTransfer types an IBC transfer between the exported addresses as a transfer in or out, it moves funds
+// between the user's own wallets
+func (row *Row) typeSelfTransfer(address string, event db.TaxableTransaction) {
+	if !event.SelfTransfer {
+		return
+	}
+	if event.ReceiverAddress.Address == address {
+		row.Type = TransferIn
+	} else if event.SenderAddress.Address == address {
+		row.Type = TransferOut
+	}
+	row.Description = "IBC transfer between own wallets"
+}
+
 func (row *Row) ParseSwap(event db.TaxableTransaction, address, eventType string) error {
 	row.Date = event.Message.Tx.Block.TimeStamp.Format(TimeLayout)
 	row.ID = event.Message.Tx.Hash
@@ -44,4 +44,6 @@ const (
 	Sell           = "sell"
 	Fee            = "fee"
 	Staking        = "staking"
+	TransferIn     = "transfer-in"
+	TransferOut    = "transfer-out"
 )
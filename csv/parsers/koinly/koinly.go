@@ -317,6 +317,8 @@ func ParseTx(address string, events []db.TaxableTransaction) (rows []parsers.Csv
 			newRow, err = ParseMsgAcknowledgement(address, event)
 		case ibc.MsgRecvPacket:
 			newRow, err = ParseMsgRecvPacket(address, event)
+		case ibc.MsgTimeout, ibc.MsgTimeoutOnClose:
+			newRow, err = ParseIBCRefund(address, event)
 		case poolmanager.MsgSplitRouteSwapExactAmountIn, poolmanager.MsgSwapExactAmountIn, poolmanager.MsgSwapExactAmountOut, poolmanager.MsgSplitRouteSwapExactAmountOut:
 			newRow, err = ParsePoolManagerSwap(event)
 		case concentratedliquidity.MsgCollectIncentives, concentratedliquidity.MsgCollectSpreadRewards:
@@ -418,10 +420,31 @@ func ParseMsgTransfer(address string, event db.TaxableTransaction) (Row, error)
 	if err != nil {
 		config.Log.Error("Error with ParseMsgTransfer.", err)
 	}
+	row.labelSelfTransfer(event)
+	return *row, err
+}
+
+// ParseIBCRefund parses the refund of a timed out or failed IBC transfer. The sender gets its own tokens back, so the
+// deposit is left unlabelled rather than labelled as income.
+func ParseIBCRefund(address string, event db.TaxableTransaction) (Row, error) {
+	row := &Row{}
+	err := row.ParseBasic(address, event)
+	if err != nil {
+		config.Log.Error("Error with ParseIBCRefund.", err)
+	}
+	if event.ReceiverAddress.Address == address {
+		row.Label = None
+	}
+	row.Description = "Refund of a failed IBC transfer"
 	return *row, err
 }
 
 func ParseMsgAcknowledgement(address string, event db.TaxableTransaction) (Row, error) {
+	// Failed acknowledgements refund the sender
+	if event.ReceiverAddress.Address == address {
+		return ParseIBCRefund(address, event)
+	}
+
 	row := &Row{}
 
 	denomToUse := event.DenominationSent
@@ -433,11 +456,7 @@ func ParseMsgAcknowledgement(address string, event db.TaxableTransaction) (Row,
 		return *row, fmt.Errorf("cannot parse denom units for TX %s (classification: deposit)", event.Message.Tx.Hash)
 	}
 
-	if event.ReceiverAddress.Address == address {
-		row.ReceivedAmount = conversionAmount.Text('f', -1)
-		row.ReceivedCurrency = conversionSymbol
-		row.Label = Income
-	} else if event.SenderAddress.Address == address { // withdrawal
+	if event.SenderAddress.Address == address { // withdrawal
 		row.SentAmount = conversionAmount.Text('f', -1)
 		row.SentCurrency = conversionSymbol
 		row.Label = Cost
@@ -473,6 +492,7 @@ func ParseMsgRecvPacket(address string, event db.TaxableTransaction) (Row, error
 
 	row.Date = event.Message.Tx.Block.TimeStamp.Format(TimeLayout)
 	row.TxHash = event.Message.Tx.Hash
+	row.labelSelfTransfer(event)
 
 	return *row, err
 }
@@ -72,6 +72,15 @@ func (row *Row) ParseBasic(address string, event db.TaxableTransaction) error {
 	return nil
 }
 
+// labelSelfTransfer removes the income or cost label of an IBC transfer between the exported addresses, Koinly
+// treats unlabelled deposits and withdrawals as transfers between the user's own wallets
+func (row *Row) labelSelfTransfer(event db.TaxableTransaction) {
+	if event.SelfTransfer {
+		row.Label = None
+		row.Description = "IBC transfer between own wallets"
+	}
+}
+
 func (row *Row) ParseSwap(event db.TaxableTransaction) error {
 	row.Date = event.Message.Tx.Block.TimeStamp.Format(TimeLayout)
 	row.TxHash = event.Message.Tx.Hash
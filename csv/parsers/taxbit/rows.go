@@ -76,8 +76,15 @@ func (row *Row) ParseBasic(address string, event db.TaxableTransaction) error {
 		row.TransactionType = Buy
 	}
 
-	// Once we support indexing across multiple chains, we can look if the transaction is from one of the user's
-	// wallets to another one of their wallets, if this is the case this is a "Transfer" "In" or "Out".
+	// IBC transfers linked across the indexed chains may be from one of the user's wallets to another one of their
+	// wallets, these are a "Transfer" "In" or "Out"
+	if event.SelfTransfer {
+		if event.ReceiverAddress.Address == address {
+			row.TransactionType = TransfersIn
+		} else if event.SenderAddress.Address == address {
+			row.TransactionType = TransfersOut
+		}
+	}
 
 	return nil
 }
@@ -0,0 +1,265 @@
+package db
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// balanceChanges is a subquery of every balance change recorded by the stored taxable txs, fees and taxable events,
+// by address, denom and block. Filters on its columns are pushed down into each part of the union.
+const balanceChanges = `(
+		SELECT taxable_tx.receiver_address_id AS address_id, taxable_tx.denomination_received_id AS denomination_id,
+			txes.block_id, taxable_tx.amount_received AS amount
+		FROM taxable_tx
+		JOIN messages ON messages.id = taxable_tx.message_id
+		JOIN txes ON txes.id = messages.tx_id
+		WHERE taxable_tx.receiver_address_id IS NOT NULL AND taxable_tx.denomination_received_id IS NOT NULL
+	UNION ALL
+		SELECT taxable_tx.sender_address_id, taxable_tx.denomination_sent_id, txes.block_id, -taxable_tx.amount_sent
+		FROM taxable_tx
+		JOIN messages ON messages.id = taxable_tx.message_id
+		JOIN txes ON txes.id = messages.tx_id
+		WHERE taxable_tx.sender_address_id IS NOT NULL AND taxable_tx.denomination_sent_id IS NOT NULL
+	UNION ALL
+		SELECT fees.payer_address_id, fees.denomination_id, txes.block_id, -fees.amount
+		FROM fees
+		JOIN txes ON txes.id = fees.tx_id
+	UNION ALL
+		SELECT taxable_event.address_id, taxable_event.denomination_id, taxable_event.block_id, taxable_event.amount
+		FROM taxable_event
+	) AS balance_changes`
+
+// DenomBalance is the balance of an address in a denom, as of the last block that changed it
+type DenomBalance struct {
+	DenominationID uint
+	Base           string
+	Symbol         string
+	Balance        decimal.Decimal
+	Height         int64 // height of the last change
+}
+
+type balanceKey struct {
+	BlockchainID   uint
+	AddressID      uint
+	DenominationID uint
+}
+
+// refreshBalances brings the address balances of the blocks up to date with the taxable txs, fees and taxable events
+// stored for them, then recomputes the running balances from the lowest changed height of every address and denom
+// that changed. It must run in the DB transaction that wrote or deleted the rows of the blocks.
+func refreshBalances(db *gorm.DB, blockIDs []uint) error {
+	if len(blockIDs) == 0 {
+		return nil
+	}
+
+	type changedRow struct {
+		balanceKey
+		Height int64
+	}
+
+	fromHeights := make(map[balanceKey]int64)
+	addChanged := func(rows []changedRow) {
+		for _, row := range rows {
+			if height, ok := fromHeights[row.balanceKey]; !ok || row.Height < height {
+				fromHeights[row.balanceKey] = row.Height
+			}
+		}
+	}
+
+	err := inChunks(len(blockIDs), func(start, end int) error {
+		var removed []changedRow
+		err := db.Raw(`DELETE FROM address_balances WHERE block_id IN ?
+			RETURNING blockchain_id, address_id, denomination_id, height`, blockIDs[start:end]).Scan(&removed).Error
+		if err != nil {
+			return err
+		}
+		addChanged(removed)
+
+		var added []changedRow
+		err = db.Raw(`INSERT INTO address_balances (blockchain_id, address_id, denomination_id, height, block_id, change, balance)
+			SELECT blocks.blockchain_id, balance_changes.address_id, balance_changes.denomination_id, blocks.height, blocks.id,
+				SUM(balance_changes.amount), 0
+			FROM `+balanceChanges+`
+			JOIN blocks ON blocks.id = balance_changes.block_id
+			WHERE balance_changes.block_id IN ?
+			GROUP BY blocks.blockchain_id, balance_changes.address_id, balance_changes.denomination_id, blocks.height, blocks.id
+			HAVING SUM(balance_changes.amount) != 0
+			RETURNING blockchain_id, address_id, denomination_id, height`, blockIDs[start:end]).Scan(&added).Error
+		if err != nil {
+			return err
+		}
+		addChanged(added)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	keys := make([]balanceKey, 0, len(fromHeights))
+	for key := range fromHeights {
+		keys = append(keys, key)
+	}
+	// A consistent order keeps concurrent updates of the same rows from deadlocking
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].BlockchainID != keys[j].BlockchainID {
+			return keys[i].BlockchainID < keys[j].BlockchainID
+		}
+		if keys[i].AddressID != keys[j].AddressID {
+			return keys[i].AddressID < keys[j].AddressID
+		}
+		return keys[i].DenominationID < keys[j].DenominationID
+	})
+
+	if err := lockBalanceKeys(db, keys); err != nil {
+		return err
+	}
+
+	return inChunks(len(keys), func(start, end int) error {
+		var args []interface{}
+		for _, key := range keys[start:end] {
+			args = append(args, key.BlockchainID, key.AddressID, key.DenominationID, fromHeights[key])
+		}
+
+		return db.Exec(`UPDATE address_balances SET balance = running.balance
+			FROM (
+				SELECT address_balances.id,
+					COALESCE((
+						SELECT previous.balance FROM address_balances previous
+						WHERE previous.blockchain_id = changed.blockchain_id AND previous.address_id = changed.address_id
+							AND previous.denomination_id = changed.denomination_id AND previous.height < changed.from_height
+						ORDER BY previous.height DESC LIMIT 1
+					), 0) + SUM(address_balances.change) OVER (
+						PARTITION BY address_balances.blockchain_id, address_balances.address_id, address_balances.denomination_id
+						ORDER BY address_balances.height
+					) AS balance
+				FROM (VALUES `+valuesPlaceholders(end-start, "(?::bigint, ?::bigint, ?::bigint, ?::bigint)")+`)
+					AS changed (blockchain_id, address_id, denomination_id, from_height)
+				JOIN address_balances ON address_balances.blockchain_id = changed.blockchain_id
+					AND address_balances.address_id = changed.address_id
+					AND address_balances.denomination_id = changed.denomination_id
+					AND address_balances.height >= changed.from_height
+			) AS running
+			WHERE address_balances.id = running.id`, args...).Error
+	})
+}
+
+// lockBalanceKeys takes a transaction level advisory lock on each address and denom of a chain. Running balances read
+// the balance before them, concurrent refreshes of the same address and denom would read each other's stale balances,
+// refreshes of other addresses and denoms do not wait for each other.
+func lockBalanceKeys(db *gorm.DB, keys []balanceKey) error {
+	type lockKey struct {
+		chain int32
+		hash  int32
+	}
+
+	unique := make(map[lockKey]struct{}, len(keys))
+	for _, key := range keys {
+		h := fnv.New32a()
+		var buf [16]byte
+		binary.BigEndian.PutUint64(buf[:8], uint64(key.AddressID))
+		binary.BigEndian.PutUint64(buf[8:], uint64(key.DenominationID))
+		h.Write(buf[:])
+		unique[lockKey{chain: int32(key.BlockchainID), hash: int32(h.Sum32())}] = struct{}{}
+	}
+
+	// Distinct keys can share a hash, the locks are taken in the order of the lock keys so concurrent refreshes
+	// can't deadlock on them
+	locks := make([]lockKey, 0, len(unique))
+	for lock := range unique {
+		locks = append(locks, lock)
+	}
+	sort.Slice(locks, func(i, j int) bool {
+		if locks[i].chain != locks[j].chain {
+			return locks[i].chain < locks[j].chain
+		}
+		return locks[i].hash < locks[j].hash
+	})
+
+	return inChunks(len(locks), func(start, end int) error {
+		var args []interface{}
+		for _, lock := range locks[start:end] {
+			args = append(args, lock.chain, lock.hash)
+		}
+
+		// A VALUES list is scanned in order, so the locks are taken in the sorted order
+		return db.Exec(`SELECT pg_advisory_xact_lock(locks.chain, locks.hash)
+			FROM (VALUES `+valuesPlaceholders(end-start, "(?::int, ?::int)")+`) AS locks (chain, hash)`, args...).Error
+	})
+}
+
+// RebuildBalances deletes the address balances of the chain and derives them again from the stored rows, a chunk of
+// blocks at a time in height order. progress is called with the highest height of each chunk once it is written.
+func RebuildBalances(db *gorm.DB, chainID uint, chunkSize int, progress func(height int64)) error {
+	if err := db.Exec("DELETE FROM address_balances WHERE blockchain_id = ?::int", chainID).Error; err != nil {
+		return err
+	}
+
+	var lastHeight int64
+	for {
+		var blocks []Block
+		err := db.Select("id", "height").
+			Where("blockchain_id = ?::int AND height > ?", chainID, lastHeight).
+			Order("height asc").
+			Limit(chunkSize).
+			Find(&blocks).Error
+		if err != nil {
+			return err
+		}
+		if len(blocks) == 0 {
+			return nil
+		}
+
+		blockIDs := make([]uint, len(blocks))
+		for i, block := range blocks {
+			blockIDs[i] = block.ID
+		}
+
+		err = db.Transaction(func(dbTransaction *gorm.DB) error {
+			return refreshBalances(dbTransaction, blockIDs)
+		})
+		if err != nil {
+			return err
+		}
+
+		lastHeight = blocks[len(blocks)-1].Height
+		progress(lastHeight)
+	}
+}
+
+// GetBalancesAtHeight gets the non zero balances of the address on the chain after the block at the height
+func GetBalancesAtHeight(db *gorm.DB, chainID uint, address string, height int64) ([]DenomBalance, error) {
+	return getBalances(db.Where("address_balances.height <= ?", height), chainID, address)
+}
+
+// GetBalancesAtTime gets the non zero balances of the address on the chain after the last block at or before the time
+func GetBalancesAtTime(db *gorm.DB, chainID uint, address string, at time.Time) ([]DenomBalance, error) {
+	return getBalances(db.Joins("JOIN blocks ON blocks.id = address_balances.block_id").Where("blocks.time_stamp <= ?", at), chainID, address)
+}
+
+// getBalances gets the last balance of the address in each denom out of the address balances the query selects
+func getBalances(query *gorm.DB, chainID uint, address string) ([]DenomBalance, error) {
+	var rows []DenomBalance
+	err := query.Table("address_balances").
+		Select("DISTINCT ON (address_balances.denomination_id) address_balances.denomination_id, denoms.base, denoms.symbol, address_balances.balance, address_balances.height").
+		Joins("JOIN addresses ON addresses.id = address_balances.address_id").
+		Joins("JOIN denoms ON denoms.id = address_balances.denomination_id").
+		Where("addresses.address = ? AND address_balances.blockchain_id = ?::int", address, chainID).
+		Order("address_balances.denomination_id, address_balances.height desc").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	balances := make([]DenomBalance, 0, len(rows))
+	for _, row := range rows {
+		if !row.Balance.IsZero() {
+			balances = append(balances, row)
+		}
+	}
+	return balances, nil
+}
@@ -0,0 +1,650 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/DefiantLabs/cosmos-tax-cli/config"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Rows per multi-row statement, keeps the statements well below the Postgres limit of 65535 parameters
+const batchInsertRows = 1000
+
+// BlockDBWrapper is a block and its transactions, for writing several blocks at once with IndexNewBlocks
+type BlockDBWrapper struct {
+	Height    int64
+	Time      time.Time
+	BlockHash string // empty keeps the stored hash
+	AppHash   string // empty keeps the stored hash
+	Txs       []TxDBWrapper
+}
+
+type messageKey struct {
+	TxID          uint
+	MessageTypeID uint
+	MessageIndex  int
+	AuthzMsgIndex string
+}
+
+type taxableTxKey struct {
+	MessageID              uint
+	AmountSent             string
+	AmountReceived         string
+	DenominationSentID     uint
+	DenominationReceivedID uint
+	SenderAddressID        uint
+	ReceiverAddressID      uint
+}
+
+// newTaxableTxKey identifies a taxable tx within its message by what moved where, a missing denom or address is 0
+func newTaxableTxKey(taxableTx TaxableTransaction) taxableTxKey {
+	idOrZero := func(id *uint) uint {
+		if id == nil {
+			return 0
+		}
+		return *id
+	}
+	return taxableTxKey{
+		MessageID:              taxableTx.MessageID,
+		AmountSent:             taxableTx.AmountSent.String(),
+		AmountReceived:         taxableTx.AmountReceived.String(),
+		DenominationSentID:     idOrZero(taxableTx.DenominationSentID),
+		DenominationReceivedID: idOrZero(taxableTx.DenominationReceivedID),
+		SenderAddressID:        idOrZero(taxableTx.SenderAddressID),
+		ReceiverAddressID:      idOrZero(taxableTx.ReceiverAddressID),
+	}
+}
+
+// IndexNewBlocks writes the blocks in a single DB transaction.
+// Instead of a round-trip per row, the blocks, addresses, message types, txs and messages of the whole batch are each
+// resolved with multi-row upserts that return the IDs of new and existing rows alike. Like IndexNewBlock, writing the
+// same blocks again does not duplicate any rows.
+func IndexNewBlocks(db *gorm.DB, blocks []BlockDBWrapper, dbChainID uint) error {
+	if len(blocks) == 0 {
+		return nil
+	}
+
+	// Check the data up front so nothing is written for an invalid batch
+	if err := validateBlocks(blocks); err != nil {
+		return err
+	}
+
+	return db.Transaction(func(dbTransaction *gorm.DB) error {
+		heights := make([]int64, len(blocks))
+		for i, block := range blocks {
+			heights[i] = block.Height
+		}
+
+		// remove from failed blocks if exists
+		if err := dbTransaction.
+			Exec("DELETE FROM failed_blocks WHERE height IN ? AND blockchain_id = ?", heights, dbChainID).
+			Error; err != nil {
+			config.Log.Error("Error updating failed blocks.", err)
+			return err
+		}
+
+		blockIDs, err := upsertBlocks(dbTransaction, blocks, dbChainID)
+		if err != nil {
+			config.Log.Error("Error getting/creating block DB objects.", err)
+			return err
+		}
+
+		addressIDs, err := upsertAddresses(dbTransaction, collectAddresses(blocks))
+		if err != nil {
+			config.Log.Error("Error getting/creating addresses.", err)
+			return err
+		}
+
+		messageTypeIDs, err := upsertMessageTypes(dbTransaction, collectMessageTypes(blocks))
+		if err != nil {
+			config.Log.Error("Error getting/creating message types.", err)
+			return err
+		}
+
+		txIDs, err := upsertTxs(dbTransaction, blocks, blockIDs, addressIDs)
+		if err != nil {
+			config.Log.Error("Error creating txs.", err)
+			return err
+		}
+
+		if err := upsertRawTxs(dbTransaction, blocks, txIDs); err != nil {
+			config.Log.Error("Error creating raw txs.", err)
+			return err
+		}
+
+		if err := insertFees(dbTransaction, blocks, txIDs, addressIDs); err != nil {
+			config.Log.Error("Error creating fees.", err)
+			return err
+		}
+
+		messageIDs, err := upsertMessages(dbTransaction, blocks, txIDs, messageTypeIDs)
+		if err != nil {
+			config.Log.Error("Error creating messages.", err)
+			return err
+		}
+
+		if err := upsertTaxableTxs(dbTransaction, blocks, txIDs, messageTypeIDs, messageIDs, addressIDs); err != nil {
+			config.Log.Error("Error creating taxable transactions.", err)
+			return err
+		}
+
+		if err := upsertUnparsedMessages(dbTransaction, blocks, txIDs, messageTypeIDs, messageIDs); err != nil {
+			config.Log.Error("Error creating unparsed messages.", err)
+			return err
+		}
+
+		if err := upsertIBCPackets(dbTransaction, blocks, txIDs, messageTypeIDs, messageIDs, dbChainID); err != nil {
+			config.Log.Error("Error creating IBC packets.", err)
+			return err
+		}
+
+		batchBlockIDs := make([]uint, 0, len(blockIDs))
+		for _, id := range blockIDs {
+			batchBlockIDs = append(batchBlockIDs, id)
+		}
+		if err := refreshBalances(dbTransaction, batchBlockIDs); err != nil {
+			config.Log.Error("Error updating address balances.", err)
+			return err
+		}
+
+		return nil
+	})
+}
+
+func validateBlocks(blocks []BlockDBWrapper) error {
+	for _, block := range blocks {
+		for _, tx := range block.Txs {
+			for _, fee := range tx.Tx.Fees {
+				if fee.PayerAddress.Address == "" {
+					return errors.New("fee cannot have empty payer address")
+				}
+				if fee.Denomination.Base == "" || fee.Denomination.Symbol == "" {
+					return fmt.Errorf("denom not cached for base %s and symbol %s", fee.Denomination.Base, fee.Denomination.Symbol)
+				}
+			}
+
+			for _, message := range tx.Messages {
+				if message.Message.MessageType.MessageType == "" {
+					return errors.New("message type not getting to DB")
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// skipTaxableTx is true for taxable txs with addresses too long to be real, these are not stored
+func skipTaxableTx(taxableTx TaxableTxDBWrapper) bool {
+	return len(taxableTx.SenderAddress.Address) > maxAddrLen || len(taxableTx.ReceiverAddress.Address) > maxAddrLen
+}
+
+func collectAddresses(blocks []BlockDBWrapper) []string {
+	var addresses []string
+	for _, block := range blocks {
+		for _, tx := range block.Txs {
+			addresses = append(addresses, tx.SignerAddress.Address)
+			for _, fee := range tx.Tx.Fees {
+				addresses = append(addresses, fee.PayerAddress.Address)
+			}
+			for _, message := range tx.Messages {
+				for _, taxableTx := range message.TaxableTxs {
+					if skipTaxableTx(taxableTx) {
+						continue
+					}
+					addresses = append(addresses, taxableTx.SenderAddress.Address, taxableTx.ReceiverAddress.Address)
+				}
+			}
+		}
+	}
+	return uniqueNonEmpty(addresses)
+}
+
+func collectMessageTypes(blocks []BlockDBWrapper) []string {
+	var messageTypes []string
+	for _, block := range blocks {
+		for _, tx := range block.Txs {
+			for _, message := range tx.Messages {
+				messageTypes = append(messageTypes, message.Message.MessageType.MessageType)
+			}
+		}
+	}
+	return uniqueNonEmpty(messageTypes)
+}
+
+// uniqueNonEmpty drops the empty and repeated values, an ON CONFLICT DO UPDATE statement can't touch the same row twice
+func uniqueNonEmpty(values []string) []string {
+	seen := make(map[string]struct{}, len(values))
+	var unique []string
+	for _, value := range values {
+		if _, ok := seen[value]; ok || value == "" {
+			continue
+		}
+		seen[value] = struct{}{}
+		unique = append(unique, value)
+	}
+	return unique
+}
+
+// inChunks calls write for consecutive ranges of at most batchInsertRows of the n rows
+func inChunks(n int, write func(start, end int) error) error {
+	for start := 0; start < n; start += batchInsertRows {
+		if err := write(start, min(start+batchInsertRows, n)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// valuesPlaceholders returns the VALUES list for rows of the given placeholder tuple, e.g. "(?, ?), (?, ?)"
+func valuesPlaceholders(rows int, tuple string) string {
+	placeholders := make([]string, rows)
+	for i := range placeholders {
+		placeholders[i] = tuple
+	}
+	return strings.Join(placeholders, ", ")
+}
+
+// upsertBlocks marks the blocks indexed, creating them if needed, and returns their IDs by height
+func upsertBlocks(db *gorm.DB, blocks []BlockDBWrapper, dbChainID uint) (map[int64]uint, error) {
+	// Keep the first occurrence of a height
+	var unique []BlockDBWrapper
+	seen := make(map[int64]struct{}, len(blocks))
+	for _, block := range blocks {
+		if _, ok := seen[block.Height]; !ok {
+			seen[block.Height] = struct{}{}
+			unique = append(unique, block)
+		}
+	}
+
+	ids := make(map[int64]uint, len(unique))
+	err := inChunks(len(unique), func(start, end int) error {
+		var args []interface{}
+		for _, block := range unique[start:end] {
+			args = append(args, block.Height, dbChainID, block.Time, block.BlockHash, block.AppHash)
+		}
+
+		var rows []struct {
+			ID     uint
+			Height int64
+		}
+		err := db.Raw(`INSERT INTO blocks (height, blockchain_id, time_stamp, block_hash, app_hash, indexed) VALUES `+valuesPlaceholders(end-start, "(?, ?, ?, ?, ?, true)")+`
+			ON CONFLICT (height, blockchain_id) DO UPDATE SET indexed = true, time_stamp = EXCLUDED.time_stamp,
+				block_hash = COALESCE(NULLIF(EXCLUDED.block_hash, ''), blocks.block_hash),
+				app_hash = COALESCE(NULLIF(EXCLUDED.app_hash, ''), blocks.app_hash)
+			RETURNING id, height`, args...).Scan(&rows).Error
+		if err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			ids[row.Height] = row.ID
+		}
+		return nil
+	})
+	return ids, err
+}
+
+// upsertAddresses creates the addresses that don't exist yet and returns the IDs of all of them by address
+func upsertAddresses(db *gorm.DB, addresses []string) (map[string]uint, error) {
+	return upsertUniqueStrings(db, "addresses", "address", addresses)
+}
+
+// upsertMessageTypes creates the message types that don't exist yet and returns the IDs of all of them by type
+func upsertMessageTypes(db *gorm.DB, messageTypes []string) (map[string]uint, error) {
+	return upsertUniqueStrings(db, "message_types", "message_type", messageTypes)
+}
+
+// upsertUniqueStrings inserts the values in a table keyed by a unique string column. The no-op update makes the
+// returning clause include the rows that already existed.
+func upsertUniqueStrings(db *gorm.DB, table string, column string, values []string) (map[string]uint, error) {
+	ids := make(map[string]uint, len(values))
+	err := inChunks(len(values), func(start, end int) error {
+		args := make([]interface{}, 0, end-start)
+		for _, value := range values[start:end] {
+			args = append(args, value)
+		}
+
+		var rows []struct {
+			ID    uint
+			Value string
+		}
+		err := db.Raw(fmt.Sprintf(`INSERT INTO %[1]s (%[2]s) VALUES %[3]s
+			ON CONFLICT (%[2]s) DO UPDATE SET %[2]s = EXCLUDED.%[2]s
+			RETURNING id, %[2]s AS value`, table, column, valuesPlaceholders(end-start, "(?)")), args...).Scan(&rows).Error
+		if err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			ids[row.Value] = row.ID
+		}
+		return nil
+	})
+	return ids, err
+}
+
+// upsertTxs creates the txs that don't exist yet and returns the IDs of all of them by hash
+func upsertTxs(db *gorm.DB, blocks []BlockDBWrapper, blockIDs map[int64]uint, addressIDs map[string]uint) (map[string]uint, error) {
+	type txRow struct {
+		hash            string
+		code            uint32
+		blockID         uint
+		signerAddressID *uint
+	}
+
+	var txs []txRow
+	seen := make(map[string]struct{})
+	for _, block := range blocks {
+		for _, tx := range block.Txs {
+			if _, ok := seen[tx.Tx.Hash]; ok {
+				continue
+			}
+			seen[tx.Tx.Hash] = struct{}{}
+
+			row := txRow{hash: tx.Tx.Hash, code: tx.Tx.Code, blockID: blockIDs[block.Height]}
+			if tx.SignerAddress.Address != "" {
+				signerAddressID := addressIDs[tx.SignerAddress.Address]
+				row.signerAddressID = &signerAddressID
+			}
+			txs = append(txs, row)
+		}
+	}
+
+	ids := make(map[string]uint, len(txs))
+	err := inChunks(len(txs), func(start, end int) error {
+		var args []interface{}
+		for _, tx := range txs[start:end] {
+			args = append(args, tx.hash, tx.code, tx.blockID, tx.signerAddressID)
+		}
+
+		var rows []struct {
+			ID   uint
+			Hash string
+		}
+		err := db.Raw(`INSERT INTO txes (hash, code, block_id, signer_address_id) VALUES `+valuesPlaceholders(end-start, "(?, ?, ?, ?)")+`
+			ON CONFLICT (hash) DO UPDATE SET hash = EXCLUDED.hash
+			RETURNING id, hash`, args...).Scan(&rows).Error
+		if err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			ids[row.Hash] = row.ID
+		}
+		return nil
+	})
+	return ids, err
+}
+
+// upsertRawTxs stores the raw data of the txs that have it, replacing what is stored if a tx is indexed again
+func upsertRawTxs(db *gorm.DB, blocks []BlockDBWrapper, txIDs map[string]uint) error {
+	var rawTxs []RawTx
+	seen := make(map[string]struct{})
+	for _, block := range blocks {
+		for _, tx := range block.Txs {
+			if _, ok := seen[tx.Tx.Hash]; ok || tx.RawTx == nil {
+				continue
+			}
+			seen[tx.Tx.Hash] = struct{}{}
+
+			rawTx := *tx.RawTx
+			rawTx.TxID = txIDs[tx.Tx.Hash]
+			rawTxs = append(rawTxs, rawTx)
+		}
+	}
+
+	if len(rawTxs) == 0 {
+		return nil
+	}
+
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "tx_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"tx_bytes", "tx_json", "memo", "gas_wanted", "gas_used", "raw_log", "logs"}),
+	}).Omit(clause.Associations).CreateInBatches(&rawTxs, batchInsertRows).Error
+}
+
+// insertFees creates the fees that don't exist yet, a tx has at most one fee per denom
+func insertFees(db *gorm.DB, blocks []BlockDBWrapper, txIDs map[string]uint, addressIDs map[string]uint) error {
+	var args [][]interface{}
+	for _, block := range blocks {
+		for _, tx := range block.Txs {
+			for _, fee := range tx.Tx.Fees {
+				args = append(args, []interface{}{txIDs[tx.Tx.Hash], fee.Amount, fee.Denomination.ID, addressIDs[fee.PayerAddress.Address]})
+			}
+		}
+	}
+
+	return inChunks(len(args), func(start, end int) error {
+		var flatArgs []interface{}
+		for _, row := range args[start:end] {
+			flatArgs = append(flatArgs, row...)
+		}
+
+		return db.Exec(`INSERT INTO fees (tx_id, amount, denomination_id, payer_address_id) VALUES `+valuesPlaceholders(end-start, "(?, ?, ?, ?)")+`
+			ON CONFLICT (tx_id, denomination_id) DO NOTHING`, flatArgs...).Error
+	})
+}
+
+// upsertMessages creates the messages that don't exist yet and returns the IDs of all of them.
+// Messages have no unique constraint to upsert on, so the existing messages of the txs are looked up first.
+func upsertMessages(db *gorm.DB, blocks []BlockDBWrapper, txIDs map[string]uint, messageTypeIDs map[string]uint) (map[messageKey]uint, error) {
+	ids := make(map[messageKey]uint)
+
+	batchTxIDs := make([]uint, 0, len(txIDs))
+	for _, id := range txIDs {
+		batchTxIDs = append(batchTxIDs, id)
+	}
+
+	err := inChunks(len(batchTxIDs), func(start, end int) error {
+		var existing []Message
+		err := db.Select("id", "tx_id", "message_type_id", "message_index", "authz_msg_index").
+			Where("tx_id IN ?", batchTxIDs[start:end]).
+			Find(&existing).Error
+		if err != nil {
+			return err
+		}
+
+		for _, message := range existing {
+			key := messageKey{message.TxID, message.MessageTypeID, message.MessageIndex, message.AuthzMsgIndex}
+			if _, ok := ids[key]; !ok {
+				ids[key] = message.ID
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var missing []messageKey
+	for _, block := range blocks {
+		for _, tx := range block.Txs {
+			for _, message := range tx.Messages {
+				key := messageKey{
+					TxID:          txIDs[tx.Tx.Hash],
+					MessageTypeID: messageTypeIDs[message.Message.MessageType.MessageType],
+					MessageIndex:  message.Message.MessageIndex,
+					AuthzMsgIndex: message.Message.AuthzMsgIndex,
+				}
+				if _, ok := ids[key]; !ok {
+					// Reserve the key so a repeated message is only inserted once
+					ids[key] = 0
+					missing = append(missing, key)
+				}
+			}
+		}
+	}
+
+	err = inChunks(len(missing), func(start, end int) error {
+		var args []interface{}
+		for _, key := range missing[start:end] {
+			args = append(args, key.TxID, key.MessageTypeID, key.MessageIndex, key.AuthzMsgIndex)
+		}
+
+		var created []Message
+		err := db.Raw(`INSERT INTO messages (tx_id, message_type_id, message_index, authz_msg_index) VALUES `+valuesPlaceholders(end-start, "(?, ?, ?, ?)")+`
+			RETURNING id, tx_id, message_type_id, message_index, authz_msg_index`, args...).Scan(&created).Error
+		if err != nil {
+			return err
+		}
+
+		for _, message := range created {
+			ids[messageKey{message.TxID, message.MessageTypeID, message.MessageIndex, message.AuthzMsgIndex}] = message.ID
+		}
+		return nil
+	})
+	return ids, err
+}
+
+// upsertUnparsedMessages creates the unparsed message records of the messages that have one, or updates them if the
+// message was already stored as unparsed
+func upsertUnparsedMessages(db *gorm.DB, blocks []BlockDBWrapper, txIDs map[string]uint, messageTypeIDs map[string]uint, messageIDs map[messageKey]uint) error {
+	var unparsedMessages []UnparsedMessage
+	seen := make(map[uint]struct{})
+	for _, block := range blocks {
+		for _, tx := range block.Txs {
+			for _, message := range tx.Messages {
+				if message.Unparsed == nil {
+					continue
+				}
+
+				messageID := messageIDs[messageKey{
+					TxID:          txIDs[tx.Tx.Hash],
+					MessageTypeID: messageTypeIDs[message.Message.MessageType.MessageType],
+					MessageIndex:  message.Message.MessageIndex,
+					AuthzMsgIndex: message.Message.AuthzMsgIndex,
+				}]
+				if _, ok := seen[messageID]; ok {
+					continue
+				}
+				seen[messageID] = struct{}{}
+
+				unparsed := *message.Unparsed
+				unparsed.MessageID = messageID
+				unparsedMessages = append(unparsedMessages, unparsed)
+			}
+		}
+	}
+
+	if len(unparsedMessages) == 0 {
+		return nil
+	}
+
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "message_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"message_json", "type_url", "message_bytes"}),
+	}).Omit(clause.Associations).CreateInBatches(&unparsedMessages, batchInsertRows).Error
+}
+
+// upsertTaxableTxs creates the taxable txs of the messages, or updates them if a taxable tx with the same amounts is
+// already stored for the message. Taxable txs have no unique constraint, so the existing ones are looked up first.
+func upsertTaxableTxs(db *gorm.DB, blocks []BlockDBWrapper, txIDs map[string]uint, messageTypeIDs map[string]uint, messageIDs map[messageKey]uint, addressIDs map[string]uint) error {
+	var taxableTxs []TaxableTransaction
+	for _, block := range blocks {
+		for _, tx := range block.Txs {
+			for _, message := range tx.Messages {
+				messageID := messageIDs[messageKey{
+					TxID:          txIDs[tx.Tx.Hash],
+					MessageTypeID: messageTypeIDs[message.Message.MessageType.MessageType],
+					MessageIndex:  message.Message.MessageIndex,
+					AuthzMsgIndex: message.Message.AuthzMsgIndex,
+				}]
+
+				for _, taxableTx := range message.TaxableTxs {
+					if skipTaxableTx(taxableTx) {
+						continue
+					}
+
+					taxableTxOnly := TaxableTransaction{
+						MessageID:      messageID,
+						AmountSent:     taxableTx.TaxableTx.AmountSent,
+						AmountReceived: taxableTx.TaxableTx.AmountReceived,
+						Inferred:       taxableTx.TaxableTx.Inferred,
+					}
+					if taxableTx.TaxableTx.DenominationSent.ID != 0 {
+						denominationSentID := taxableTx.TaxableTx.DenominationSent.ID
+						taxableTxOnly.DenominationSentID = &denominationSentID
+					}
+					if taxableTx.TaxableTx.DenominationReceived.ID != 0 {
+						denominationReceivedID := taxableTx.TaxableTx.DenominationReceived.ID
+						taxableTxOnly.DenominationReceivedID = &denominationReceivedID
+					}
+					if taxableTx.SenderAddress.Address != "" {
+						senderAddressID := addressIDs[taxableTx.SenderAddress.Address]
+						taxableTxOnly.SenderAddressID = &senderAddressID
+					}
+					if taxableTx.ReceiverAddress.Address != "" {
+						receiverAddressID := addressIDs[taxableTx.ReceiverAddress.Address]
+						taxableTxOnly.ReceiverAddressID = &receiverAddressID
+					}
+					taxableTxs = append(taxableTxs, taxableTxOnly)
+				}
+			}
+		}
+	}
+
+	messageIDList := make([]uint, 0, len(messageIDs))
+	for _, id := range messageIDs {
+		messageIDList = append(messageIDList, id)
+	}
+
+	// It is possible to have more than 1 taxable TX for a single msg, they are keyed off of the msg ID, the amounts,
+	// the denoms and the addresses so movements of equal amounts in different denoms or between different addresses
+	// are all kept
+	existingIDs := make(map[taxableTxKey]uint)
+	err := inChunks(len(messageIDList), func(start, end int) error {
+		var existing []TaxableTransaction
+		err := db.Select("id", "message_id", "amount_sent", "amount_received", "denomination_sent_id", "denomination_received_id", "sender_address_id", "receiver_address_id").
+			Where("message_id IN ?", messageIDList[start:end]).
+			Find(&existing).Error
+		if err != nil {
+			return err
+		}
+
+		for _, taxableTx := range existing {
+			key := newTaxableTxKey(taxableTx)
+			if _, ok := existingIDs[key]; !ok {
+				existingIDs[key] = taxableTx.ID
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// The last taxable tx for a key wins, the same way it would overwrite an existing one
+	var toCreate []TaxableTransaction
+	toCreateIndex := make(map[taxableTxKey]int)
+	for _, taxableTx := range taxableTxs {
+		key := newTaxableTxKey(taxableTx)
+
+		if id, ok := existingIDs[key]; ok {
+			// Force update with new data, only happens when a block is reindexed
+			// Updates skips zero values, inferred is set on its own so a parser can clear it
+			if err := db.Model(&TaxableTransaction{ID: id}).Updates(&taxableTx).Error; err != nil {
+				return err
+			}
+			if err := db.Model(&TaxableTransaction{ID: id}).Update("inferred", taxableTx.Inferred).Error; err != nil {
+				return err
+			}
+			continue
+		}
+
+		if i, ok := toCreateIndex[key]; ok {
+			toCreate[i] = taxableTx
+			continue
+		}
+		toCreateIndex[key] = len(toCreate)
+		toCreate = append(toCreate, taxableTx)
+	}
+
+	if len(toCreate) == 0 {
+		return nil
+	}
+	return db.CreateInBatches(&toCreate, batchInsertRows).Error
+}
@@ -1,7 +1,6 @@
 package db
 
 import (
-	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -50,16 +49,22 @@ func MigrateModels(db *gorm.DB) error {
 		&FailedEventBlock{},
 		&Chain{},
 		&Tx{},
+		&RawTx{},
 		&Fee{},
 		&Address{},
 		&MessageType{},
 		&Message{},
+		&UnparsedMessage{},
+		&IBCPacket{},
 		&TaxableTransaction{},
 		&TaxableEvent{},
+		&AddressBalance{},
 		&Denom{},
 		&DenomUnit{},
 		&IBCDenom{},
 		&Epoch{},
+		&BlockJob{},
+		&WatchedAddress{},
 	)
 }
 
@@ -69,7 +74,7 @@ func GetFailedBlocks(db *gorm.DB, chainID uint) []FailedBlock {
 	return failedBlocks
 }
 
-func GetFirstMissingBlockInRange(db *gorm.DB, start, end int64, chainID uint) int64 {
+func GetFirstMissingBlockInRange(db *gorm.DB, start, end int64, chainID uint) (int64, error) {
 	// Find the highest block we have indexed so far
 	currMax := GetHighestIndexedBlock(db, chainID)
 
@@ -85,12 +90,12 @@ func GetFirstMissingBlockInRange(db *gorm.DB, start, end int64, chainID uint) in
 						ORDER BY s.i ASC LIMIT 1;`, start, end, chainID).Row().Scan(&firstMissingBlock)
 	if err != nil {
 		if !strings.Contains(err.Error(), "no rows in result set") {
-			config.Log.Fatalf("Unable to find start block. Err: %v", err)
+			return 0, fmt.Errorf("unable to find start block: %w", err)
 		}
 		firstMissingBlock = start
 	}
 
-	return firstMissingBlock
+	return firstMissingBlock, nil
 }
 
 func GetDBChainID(db *gorm.DB, chain Chain) (uint, error) {
@@ -108,214 +113,114 @@ func GetHighestIndexedBlock(db *gorm.DB, chainID uint) Block {
 	return block
 }
 
-func UpsertFailedBlock(db *gorm.DB, blockHeight int64, chainID string, chainName string) error {
+// GetRecentIndexedBlocks gets up to count of the highest indexed blocks that have a block hash, highest first
+func GetRecentIndexedBlocks(db *gorm.DB, chainID uint, count int64) ([]Block, error) {
+	var blocks []Block
+	err := db.Where("blockchain_id = ?::int AND indexed = true AND block_hash != ''", chainID).
+		Order("height desc").
+		Limit(int(count)).
+		Find(&blocks).Error
+	return blocks, err
+}
+
+// ResetBlock deletes the txs of an indexed block along with everything parsed from them and marks the block as not
+// indexed, so it can be indexed again from scratch (e.g. after it turned out to be from a fork)
+func ResetBlock(db *gorm.DB, blockID uint) error {
 	return db.Transaction(func(dbTransaction *gorm.DB) error {
-		failedBlock := FailedBlock{Height: blockHeight, Chain: Chain{ChainID: chainID, Name: chainName}}
+		txIDs := dbTransaction.Table("txes").Select("id").Where("block_id = ?", blockID)
 
-		if err := dbTransaction.Where(&failedBlock.Chain).FirstOrCreate(&failedBlock.Chain).Error; err != nil {
-			config.Log.Error("Error creating chain DB object.", err)
+		for _, table := range []string{"taxable_tx", "unparsed_messages", "ibc_packets"} {
+			if err := dbTransaction.Exec(fmt.Sprintf("DELETE FROM %s WHERE message_id IN (SELECT id FROM messages WHERE tx_id IN (?))", table), txIDs).Error; err != nil {
+				return err
+			}
+		}
+		for _, table := range []string{"messages", "fees", "raw_txes"} {
+			if err := dbTransaction.Exec(fmt.Sprintf("DELETE FROM %s WHERE tx_id IN (?)", table), txIDs).Error; err != nil {
+				return err
+			}
+		}
+		if err := dbTransaction.Exec("DELETE FROM txes WHERE block_id = ?", blockID).Error; err != nil {
 			return err
 		}
-
-		if err := dbTransaction.Where(&failedBlock).FirstOrCreate(&failedBlock).Error; err != nil {
-			config.Log.Error("Error creating failed block DB object.", err)
+		if err := refreshBalances(dbTransaction, []uint{blockID}); err != nil {
 			return err
 		}
-		return nil
+
+		return dbTransaction.Model(&Block{}).Where("id = ?", blockID).
+			Updates(map[string]interface{}{"indexed": false, "block_hash": "", "app_hash": ""}).Error
 	})
 }
 
-func UpsertFailedEventBlock(db *gorm.DB, blockHeight int64, chainID string, chainName string) error {
+// UpsertFailedBlock records the failure of a block. If the block failed before, its attempts are counted up and the
+// failure details are replaced with the ones of this failure.
+func UpsertFailedBlock(db *gorm.DB, failure FailedBlock, chainID string, chainName string) error {
 	return db.Transaction(func(dbTransaction *gorm.DB) error {
-		failedEventBlock := FailedEventBlock{Height: blockHeight, Chain: Chain{ChainID: chainID, Name: chainName}}
-
-		if err := dbTransaction.Where(&failedEventBlock.Chain).FirstOrCreate(&failedEventBlock.Chain).Error; err != nil {
+		chain := Chain{ChainID: chainID, Name: chainName}
+		if err := dbTransaction.Where(&chain).FirstOrCreate(&chain).Error; err != nil {
 			config.Log.Error("Error creating chain DB object.", err)
 			return err
 		}
 
-		if err := dbTransaction.Where(&failedEventBlock).FirstOrCreate(&failedEventBlock).Error; err != nil {
-			config.Log.Error("Error creating failed event block DB object.", err)
+		now := time.Now()
+		failure.ID = 0
+		failure.BlockchainID = chain.ID
+		failure.Attempts = 1
+		failure.FirstSeen = now
+		failure.LastSeen = now
+
+		if err := dbTransaction.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "height"}, {Name: "blockchain_id"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"code":         failure.Code,
+				"error":        failure.Error,
+				"message_type": failure.MessageType,
+				"tx_hash":      failure.TxHash,
+				"attempts":     gorm.Expr("failed_blocks.attempts + 1"),
+				"last_seen":    now,
+			}),
+		}).Omit(clause.Associations).Create(&failure).Error; err != nil {
+			config.Log.Error("Error creating failed block DB object.", err)
 			return err
 		}
 		return nil
 	})
 }
 
-var maxAddrLen = 100
-
-func IndexNewBlock(db *gorm.DB, blockHeight int64, blockTime time.Time, txs []TxDBWrapper, dbChainID uint) error {
-	// consider optimizing the transaction, but how? Ordering matters due to foreign key constraints
-	// Order required: Block -> (For each Tx: Signer Address -> Tx -> (For each Message: Message -> Taxable Events))
-	// Also, foreign key relations are struct value based so create needs to be called first to get right foreign key ID
+// UpsertFailedEventBlock records the failure of the events of a block, counting up the attempts if they failed before
+func UpsertFailedEventBlock(db *gorm.DB, blockHeight int64, chainID string, chainName string) error {
 	return db.Transaction(func(dbTransaction *gorm.DB) error {
-		// remove from failed blocks if exists
-		if err := dbTransaction.
-			Exec("DELETE FROM failed_blocks WHERE height = ? AND blockchain_id = ?", blockHeight, dbChainID).
-			Error; err != nil {
-			config.Log.Error("Error updating failed block.", err)
+		chain := Chain{ChainID: chainID, Name: chainName}
+		if err := dbTransaction.Where(&chain).FirstOrCreate(&chain).Error; err != nil {
+			config.Log.Error("Error creating chain DB object.", err)
 			return err
 		}
 
-		// create block if it doesn't exist
-		blockOnly := Block{Height: blockHeight, TimeStamp: blockTime, Indexed: true, BlockchainID: dbChainID}
-		if err := dbTransaction.
-			Where(Block{Height: blockHeight, BlockchainID: dbChainID}).
-			Assign(Block{Indexed: true, TimeStamp: blockTime}).
-			FirstOrCreate(&blockOnly).Error; err != nil {
-			config.Log.Error("Error getting/creating block DB object.", err)
+		now := time.Now()
+		failedEventBlock := FailedEventBlock{Height: blockHeight, BlockchainID: chain.ID, Attempts: 1, FirstSeen: now, LastSeen: now}
+		if err := dbTransaction.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "height"}, {Name: "blockchain_id"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"attempts":  gorm.Expr("failed_event_blocks.attempts + 1"),
+				"last_seen": now,
+			}),
+		}).Omit(clause.Associations).Create(&failedEventBlock).Error; err != nil {
+			config.Log.Error("Error creating failed event block DB object.", err)
 			return err
 		}
+		return nil
+	})
+}
 
-		for _, tx := range txs {
-			transaction := tx
-			txOnly := Tx{
-				Hash:            transaction.Tx.Hash,
-				Code:            transaction.Tx.Code,
-				BlockID:         blockOnly.ID,
-				SignerAddressID: nil,
-			}
-
-			// store the signer address if there is one
-			if transaction.SignerAddress.Address != "" {
-				// viewing gorm logs shows this gets translated into a single ON CONFLICT DO NOTHING RETURNING "id"
-				if err := dbTransaction.Where(Address{Address: transaction.SignerAddress.Address}).
-					FirstOrCreate(&transaction.SignerAddress).
-					Error; err != nil {
-					config.Log.Error("Error getting/creating signer address for tx.", err)
-					return err
-				}
-				// store created db model in signer address, creates foreign key relation
-				txOnly.SignerAddressID = &transaction.SignerAddress.ID
-			}
-
-			// store the TX
-			if err := dbTransaction.Where(Tx{Hash: txOnly.Hash}).FirstOrCreate(&txOnly).Error; err != nil {
-				config.Log.Error("Error creating tx.", err)
-				return err
-			}
-
-			for _, feeL := range transaction.Tx.Fees {
-				fee := feeL
-				feeOnly := Fee{
-					TxID:           txOnly.ID,
-					Amount:         fee.Amount,
-					DenominationID: fee.Denomination.ID,
-				}
-				if fee.PayerAddress.Address != "" {
-					if err := dbTransaction.Where(Address{Address: fee.PayerAddress.Address}).
-						FirstOrCreate(&fee.PayerAddress).
-						Error; err != nil {
-						config.Log.Error("Error getting/creating fee payer address.", err)
-						return err
-					}
-
-					// creates foreign key relation.
-					feeOnly.PayerAddressID = fee.PayerAddress.ID
-				} else if fee.PayerAddress.Address == "" {
-					return errors.New("fee cannot have empty payer address")
-				}
-
-				if fee.Denomination.Base == "" || fee.Denomination.Symbol == "" {
-					return fmt.Errorf("denom not cached for base %s and symbol %s", fee.Denomination.Base, fee.Denomination.Symbol)
-				}
-
-				// store the Fee
-				if err := dbTransaction.Where(Fee{TxID: feeOnly.TxID, DenominationID: feeOnly.DenominationID}).FirstOrCreate(&feeOnly).Error; err != nil {
-					config.Log.Error("Error creating fee.", err)
-					return err
-				}
-			}
-
-			for _, msg := range transaction.Messages {
-				message := msg
-				if message.Message.MessageType.MessageType == "" {
-					config.Log.Fatal("Message type not getting to DB")
-				}
-				if err := dbTransaction.Where(&message.Message.MessageType).FirstOrCreate(&message.Message.MessageType).Error; err != nil {
-					config.Log.Error("Error getting/creating message_type.", err)
-					return err
-				}
-
-				msgOnly := Message{
-					TxID:          txOnly.ID,
-					MessageTypeID: message.Message.MessageType.ID,
-					MessageIndex:  message.Message.MessageIndex,
-				}
-
-				// Store the msg
-				if err := dbTransaction.Where(Message{TxID: msgOnly.TxID, MessageTypeID: msgOnly.MessageTypeID, MessageIndex: msgOnly.MessageIndex}).FirstOrCreate(&msgOnly).Error; err != nil {
-					config.Log.Error("Error creating message.", err)
-					return err
-				}
+// DeleteFailedEventBlock removes the block from the failed event blocks once its events are indexed
+func DeleteFailedEventBlock(db *gorm.DB, blockHeight int64, chainID uint) error {
+	return db.Exec("DELETE FROM failed_event_blocks WHERE height = ? AND blockchain_id = ?", blockHeight, chainID).Error
+}
 
-				for _, taxableTxL := range message.TaxableTxs {
-					taxableTx := taxableTxL
-					if len(taxableTx.SenderAddress.Address) > maxAddrLen || len(taxableTx.ReceiverAddress.Address) > maxAddrLen {
-						continue
-					}
-					taxableTxOnly := TaxableTransaction{
-						MessageID:      msgOnly.ID,
-						AmountSent:     taxableTx.TaxableTx.AmountSent,
-						AmountReceived: taxableTx.TaxableTx.AmountReceived,
-					}
-					if taxableTx.TaxableTx.DenominationSent.ID != 0 {
-						taxableTxOnly.DenominationSentID = &taxableTx.TaxableTx.DenominationSent.ID
-					}
-					if taxableTx.TaxableTx.DenominationReceived.ID != 0 {
-						taxableTxOnly.DenominationReceivedID = &taxableTx.TaxableTx.DenominationReceived.ID
-					}
-					if taxableTx.SenderAddress.Address != "" {
-						if err := dbTransaction.Where(&taxableTx.SenderAddress).FirstOrCreate(&taxableTx.SenderAddress).Error; err != nil {
-							config.Log.Error("Error getting/creating sender address.", err)
-							return err
-						}
-						// store created db model in sender address, creates foreign key relation
-						taxableTxOnly.SenderAddressID = &taxableTx.SenderAddress.ID
-					}
-
-					if taxableTx.ReceiverAddress.Address != "" {
-						if err := dbTransaction.Where(&taxableTx.ReceiverAddress).FirstOrCreate(&taxableTx.ReceiverAddress).Error; err != nil {
-							config.Log.Errorf("Error getting/creating receiver address for msg %v of tx hash %v. Err: %v", message.Message.MessageIndex, txOnly.Hash, err)
-							return err
-						}
-						// store created db model in receiver address, creates foreign key relation
-						taxableTxOnly.ReceiverAddressID = &taxableTx.ReceiverAddress.ID
-					}
-
-					// It is possible to have more than 1 taxable TX for a single msg. In most cases it should only be 1 or 2, but
-					// more is possible. Keying off of msg ID and amount may be sufficient....
-
-					var foundRecord TaxableTransaction
-					dbTransaction.
-						Where(TaxableTransaction{
-							MessageID:      taxableTxOnly.MessageID,
-							AmountSent:     taxableTxOnly.AmountSent,
-							AmountReceived: taxableTxOnly.AmountReceived,
-						}).Limit(1).Find(&foundRecord)
-
-					// If not found, do a create
-					if foundRecord.ID == 0 {
-						res := dbTransaction.Create(&taxableTxOnly)
-
-						if res.Error != nil {
-							config.Log.Error("Error creating taxable transaction.", res.Error)
-							return res.Error
-						}
-					} else {
-						// Force update with new data
-						res := dbTransaction.Model(&foundRecord).Updates(&taxableTxOnly)
-						if res.Error != nil {
-							config.Log.Error("Error updating taxable transaction.", res.Error)
-							return res.Error
-						}
-					}
-				}
-			}
-		}
+var maxAddrLen = 100
 
-		return nil
-	})
+// IndexNewBlock writes a single block, see IndexNewBlocks
+func IndexNewBlock(db *gorm.DB, blockHeight int64, blockTime time.Time, txs []TxDBWrapper, dbChainID uint) error {
+	return IndexNewBlocks(db, []BlockDBWrapper{{Height: blockHeight, Time: blockTime, Txs: txs}}, dbChainID)
 }
 
 func UpsertDenoms(db *gorm.DB, denoms []DenomDBWrapper) error {
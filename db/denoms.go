@@ -59,6 +59,71 @@ func GetIBCDenom(denomTrace string) (IBCDenom, error) {
 	return IBCDenom{}, fmt.Errorf("no IBC denom found for the specified denom trace %s", denomTrace)
 }
 
+// DenomCache mirrors the denoms and IBC denoms in the DB for the lookups done while indexing. Each indexed chain has
+// its own, so setting up a chain or adding a missing denom does not reload the cache other chains are reading.
+type DenomCache struct {
+	mu         sync.Mutex
+	denomUnits []DenomUnit
+	ibcDenoms  []IBCDenom
+}
+
+// NewDenomCache loads a cache of the denoms currently in the DB
+func NewDenomCache(db *gorm.DB) *DenomCache {
+	cache := &DenomCache{}
+	cache.Load(db)
+	return cache
+}
+
+// Load replaces the cached denoms with the ones currently in the DB
+func (c *DenomCache) Load(db *gorm.DB) {
+	var denomUnits []DenomUnit
+	db.Preload("Denom").Find(&denomUnits)
+	var ibcDenoms []IBCDenom
+	db.Find(&ibcDenoms)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.denomUnits = denomUnits
+	c.ibcDenoms = ibcDenoms
+}
+
+// GetDenomForBase is GetDenomForBase on the cached denoms
+func (c *DenomCache) GetDenomForBase(base string) (Denom, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, denomUnit := range c.denomUnits {
+		if denomUnit.Denom.Base == base {
+			return denomUnit.Denom, nil
+		}
+	}
+
+	return Denom{}, fmt.Errorf("GetDenomForBase: no denom unit for the specified denom %s", base)
+}
+
+// GetIBCDenom is GetIBCDenom on the cached IBC denoms
+func (c *DenomCache) GetIBCDenom(denomTrace string) (IBCDenom, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, denom := range c.ibcDenoms {
+		if denom.Hash == denomTrace {
+			return denom, nil
+		}
+	}
+	return IBCDenom{}, fmt.Errorf("no IBC denom found for the specified denom trace %s", denomTrace)
+}
+
+// AddUnknownDenom is AddUnknownDenom, reloading this cache rather than the global one
+func (c *DenomCache) AddUnknownDenom(db *gorm.DB, denom string) (Denom, error) {
+	denomToAdd, err := upsertUnknownDenom(db, denom)
+	if err != nil {
+		return denomToAdd, err
+	}
+
+	c.Load(db)
+
+	return c.GetDenomForBase(denom)
+}
+
 func GetDenomUnitForDenom(denom Denom) (DenomUnit, error) {
 	for _, denomUnit := range CachedDenomUnits {
 		if denomUnit.DenomID == denom.ID {
@@ -180,14 +245,7 @@ func ConvertUnits(amount *big.Int, denom Denom) (*big.Float, string, error) {
 // a transaction message and not found in our database during tx parsing
 // Creates a single denom and a single denom_unit that fits our DB structure, adds them to the DB
 func AddUnknownDenom(db *gorm.DB, denom string) (Denom, error) {
-	denomToAdd := Denom{Base: denom, Name: "UNKNOWN", Symbol: "UNKNOWN"}
-	singleDenomUnit := DenomUnit{Exponent: 0, Name: denom}
-	denomUnitsToAdd := [...]DenomUnitDBWrapper{{DenomUnit: singleDenomUnit}}
-
-	denomDbWrapper := [1]DenomDBWrapper{{Denom: denomToAdd}}
-	denomDbWrapper[0].DenomUnits = denomUnitsToAdd[:]
-
-	err := UpsertDenoms(db, denomDbWrapper[:])
+	denomToAdd, err := upsertUnknownDenom(db, denom)
 	if err != nil {
 		return denomToAdd, err
 	}
@@ -198,3 +256,15 @@ func AddUnknownDenom(db *gorm.DB, denom string) (Denom, error) {
 
 	return GetDenomForBase(denom)
 }
+
+// upsertUnknownDenom adds the denom with a single denom unit and an UNKNOWN name and symbol
+func upsertUnknownDenom(db *gorm.DB, denom string) (Denom, error) {
+	denomToAdd := Denom{Base: denom, Name: "UNKNOWN", Symbol: "UNKNOWN"}
+	singleDenomUnit := DenomUnit{Exponent: 0, Name: denom}
+	denomUnitsToAdd := [...]DenomUnitDBWrapper{{DenomUnit: singleDenomUnit}}
+
+	denomDbWrapper := [1]DenomDBWrapper{{Denom: denomToAdd}}
+	denomDbWrapper[0].DenomUnits = denomUnitsToAdd[:]
+
+	return denomToAdd, UpsertDenoms(db, denomDbWrapper[:])
+}
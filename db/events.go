@@ -13,16 +13,16 @@ import (
 	"gorm.io/gorm"
 )
 
-func IndexBlockEvents(db *gorm.DB, dryRun bool, blockHeight int64, blockTime time.Time, blockEvents []events.EventRelevantInformation, dbChainID string, dbChainName string, identifierLoggingString string) error {
+func IndexBlockEvents(db *gorm.DB, denoms *DenomCache, dryRun bool, blockHeight int64, blockTime time.Time, blockEvents []events.EventRelevantInformation, dbChainID string, dbChainName string, identifierLoggingString string) error {
 	dbEvents := []TaxableEvent{}
 
 	for _, blockEvent := range blockEvents {
 
-		denom, err := GetDenomForBase(blockEvent.Denomination)
+		denom, err := denoms.GetDenomForBase(blockEvent.Denomination)
 		if err != nil {
 			// attempt to add missing denoms to the database
 			config.Log.Warnf("Denom lookup failed. Will be inserted as UNKNOWN. Denom Received: %v. Err: %v", blockEvent.Denomination, err)
-			denom, err = AddUnknownDenom(db, blockEvent.Denomination)
+			denom, err = denoms.AddUnknownDenom(db, blockEvent.Denomination)
 			if err != nil {
 				config.Log.Error(fmt.Sprintf("There was an error adding a missing denom. Denom Received: %v", blockEvent.Denomination), err)
 				return err
@@ -112,6 +112,7 @@ func createTaxableEvents(db *gorm.DB, events []TaxableEvent) error {
 
 		var chainPrev Chain
 		var blockPrev Block
+		var blockIDs []uint
 
 		for _, eventL := range events {
 			event := eventL
@@ -135,6 +136,7 @@ func createTaxableEvents(db *gorm.DB, events []TaxableEvent) error {
 				}
 
 				blockPrev = event.Block
+				blockIDs = append(blockIDs, blockPrev.ID)
 			}
 
 			event.Block = blockPrev
@@ -158,7 +160,7 @@ func createTaxableEvents(db *gorm.DB, events []TaxableEvent) error {
 			}
 		}
 
-		return nil
+		return refreshBalances(dbTransaction, blockIDs)
 	})
 }
 
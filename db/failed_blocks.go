@@ -0,0 +1,95 @@
+package db
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// FailedBlockFilter selects failed blocks, empty fields match any block
+type FailedBlockFilter struct {
+	Code        string
+	MessageType string
+	StartHeight int64
+	EndHeight   int64 // -1 for no upper bound
+}
+
+func (filter FailedBlockFilter) apply(query *gorm.DB, chainID uint) *gorm.DB {
+	query = query.Where("blockchain_id = ?::int AND height >= ?", chainID, filter.StartHeight)
+	if filter.EndHeight != -1 {
+		query = query.Where("height <= ?", filter.EndHeight)
+	}
+	if filter.Code != "" {
+		query = query.Where("code = ?", filter.Code)
+	}
+	if filter.MessageType != "" {
+		query = query.Where("message_type = ?", filter.MessageType)
+	}
+	return query
+}
+
+// FailedBlockReason is the number of failed blocks that failed for the same reason
+type FailedBlockReason struct {
+	Code        string
+	MessageType string
+	Blocks      int64
+	MinHeight   int64
+	MaxHeight   int64
+	LastSeen    *time.Time // null for blocks recorded before failure details were stored
+}
+
+// GetFilteredFailedBlocks gets the failed blocks that match the filter in height order
+func GetFilteredFailedBlocks(db *gorm.DB, chainID uint, filter FailedBlockFilter) ([]FailedBlock, error) {
+	var failedBlocks []FailedBlock
+	err := filter.apply(db.Model(&FailedBlock{}), chainID).Order("height asc").Find(&failedBlocks).Error
+	return failedBlocks, err
+}
+
+// GetFailedBlockReasons groups the failed blocks that match the filter by failure code and message type, most
+// common first
+func GetFailedBlockReasons(db *gorm.DB, chainID uint, filter FailedBlockFilter) ([]FailedBlockReason, error) {
+	var reasons []FailedBlockReason
+	err := filter.apply(db.Model(&FailedBlock{}), chainID).
+		Select("code, message_type, COUNT(*) AS blocks, MIN(height) AS min_height, MAX(height) AS max_height, MAX(last_seen) AS last_seen").
+		Group("code, message_type").
+		Order("blocks desc").
+		Scan(&reasons).Error
+	return reasons, err
+}
+
+// GetFailedBlocksForRetry splits the failed blocks into the ones with fewer than maxAttempts attempts and the heights
+// of the ones that reached it
+func GetFailedBlocksForRetry(db *gorm.DB, chainID uint, maxAttempts int) (retryable []FailedBlock, exhausted []int64, err error) {
+	var failedBlocks []FailedBlock
+	err = db.Where("blockchain_id = ?::int", chainID).Order("height asc").Find(&failedBlocks).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, block := range failedBlocks {
+		if block.Attempts < maxAttempts {
+			retryable = append(retryable, block)
+		} else {
+			exhausted = append(exhausted, block.Height)
+		}
+	}
+	return retryable, exhausted, nil
+}
+
+// GetFailedEventBlocksForRetry is GetFailedBlocksForRetry for the failed event blocks
+func GetFailedEventBlocksForRetry(db *gorm.DB, chainID uint, maxAttempts int) (retryable []FailedEventBlock, exhausted []int64, err error) {
+	var failedBlocks []FailedEventBlock
+	err = db.Where("blockchain_id = ?::int", chainID).Order("height asc").Find(&failedBlocks).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, block := range failedBlocks {
+		if block.Attempts < maxAttempts {
+			retryable = append(retryable, block)
+		} else {
+			exhausted = append(exhausted, block.Height)
+		}
+	}
+	return retryable, exhausted, nil
+}
@@ -0,0 +1,127 @@
+package db
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Direction of an IBC packet on the chain it was stored for
+const (
+	IBCPacketSent     = "sent"
+	IBCPacketReceived = "received"
+)
+
+// IBCPacket is the identity of the packet of an ICS-20 transfer message. It is stored on the sending chain for the
+// MsgTransfer and on the receiving chain for the MsgRecvPacket, when both chains are indexed in the same DB the two
+// sides are linked by LinkIBCPackets.
+type IBCPacket struct {
+	ID                 uint
+	MessageID          uint    `gorm:"uniqueIndex"`
+	Message            Message `gorm:"foreignKey:MessageID"`
+	BlockchainID       uint
+	Chain              Chain  `gorm:"foreignKey:BlockchainID"`
+	Direction          string `gorm:"index:idx_ibc_packet_identity,priority:1"`
+	SourcePort         string `gorm:"index:idx_ibc_packet_identity,priority:2"`
+	SourceChannel      string `gorm:"index:idx_ibc_packet_identity,priority:3"`
+	DestinationPort    string
+	DestinationChannel string     `gorm:"index:idx_ibc_packet_identity,priority:4"`
+	Sequence           uint64     `gorm:"index:idx_ibc_packet_identity,priority:5"`
+	Sender             string     // packet data sender on the sending chain, lower case
+	Receiver           string     // packet data receiver on the receiving chain, lower case
+	LinkedPacketID     *uint      `gorm:"index"`
+	LinkedPacket       *IBCPacket `gorm:"foreignKey:LinkedPacketID;constraint:OnDelete:SET NULL"`
+}
+
+// upsertIBCPackets creates the IBC packets of the messages that have one, or updates them if the message was already
+// stored. A packet that is updated keeps its link.
+func upsertIBCPackets(db *gorm.DB, blocks []BlockDBWrapper, txIDs map[string]uint, messageTypeIDs map[string]uint, messageIDs map[messageKey]uint, dbChainID uint) error {
+	var packets []IBCPacket
+	seen := make(map[uint]struct{})
+	for _, block := range blocks {
+		for _, tx := range block.Txs {
+			for _, message := range tx.Messages {
+				if message.IBCPacket == nil {
+					continue
+				}
+
+				messageID := messageIDs[messageKey{
+					TxID:          txIDs[tx.Tx.Hash],
+					MessageTypeID: messageTypeIDs[message.Message.MessageType.MessageType],
+					MessageIndex:  message.Message.MessageIndex,
+					AuthzMsgIndex: message.Message.AuthzMsgIndex,
+				}]
+				if _, ok := seen[messageID]; ok {
+					continue
+				}
+				seen[messageID] = struct{}{}
+
+				packet := *message.IBCPacket
+				packet.MessageID = messageID
+				packet.BlockchainID = dbChainID
+				packets = append(packets, packet)
+			}
+		}
+	}
+
+	if len(packets) == 0 {
+		return nil
+	}
+
+	return db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "message_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"direction", "source_port", "source_channel", "destination_port", "destination_channel", "sequence", "sender", "receiver",
+		}),
+	}).Omit(clause.Associations).CreateInBatches(&packets, batchInsertRows).Error
+}
+
+// LinkIBCPackets links the unlinked sent packets to the packets received on another chain with the same identity and
+// returns the number of transfers linked. Packets with more than one candidate on the other side are left unlinked,
+// channel IDs are only unique per chain so those can't be told apart.
+func LinkIBCPackets(db *gorm.DB) (int64, error) {
+	result := db.Exec(`WITH candidates AS (
+			SELECT sent.id AS sent_id, received.id AS received_id,
+				COUNT(*) OVER (PARTITION BY sent.id) AS sent_matches,
+				COUNT(*) OVER (PARTITION BY received.id) AS received_matches
+			FROM ibc_packets sent
+			JOIN ibc_packets received ON received.direction = ?
+				AND received.linked_packet_id IS NULL
+				AND received.blockchain_id <> sent.blockchain_id
+				AND received.source_port = sent.source_port
+				AND received.source_channel = sent.source_channel
+				AND received.destination_port = sent.destination_port
+				AND received.destination_channel = sent.destination_channel
+				AND received.sequence = sent.sequence
+				AND received.sender = sent.sender
+				AND received.receiver = sent.receiver
+			WHERE sent.direction = ? AND sent.linked_packet_id IS NULL
+		), pairs AS (
+			SELECT sent_id, received_id FROM candidates WHERE sent_matches = 1 AND received_matches = 1
+		)
+		UPDATE ibc_packets
+		SET linked_packet_id = CASE WHEN ibc_packets.id = pairs.sent_id THEN pairs.received_id ELSE pairs.sent_id END
+		FROM pairs
+		WHERE ibc_packets.id = pairs.sent_id OR ibc_packets.id = pairs.received_id`, IBCPacketReceived, IBCPacketSent)
+
+	// Both sides of a transfer are updated
+	return result.RowsAffected / 2, result.Error
+}
+
+// GetSelfTransferMessageIDs gets the messages of the linked IBC transfers whose sender and receiver are both in the
+// addresses, on either side of the transfer. These only move funds between the wallets of the same owner.
+func GetSelfTransferMessageIDs(db *gorm.DB, addresses []string) (map[uint]struct{}, error) {
+	var messageIDs []uint
+	err := db.Table("ibc_packets").
+		Select("ibc_packets.message_id").
+		Where("ibc_packets.linked_packet_id IS NOT NULL AND ibc_packets.sender IN ? AND ibc_packets.receiver IN ?", normalizeAddresses(addresses), normalizeAddresses(addresses)).
+		Pluck("ibc_packets.message_id", &messageIDs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	selfTransfers := make(map[uint]struct{}, len(messageIDs))
+	for _, id := range messageIDs {
+		selfTransfers[id] = struct{}{}
+	}
+	return selfTransfers, nil
+}
@@ -13,6 +13,8 @@ type Block struct {
 	BlockchainID uint  `gorm:"uniqueIndex:chainheight"`
 	Chain        Chain `gorm:"foreignKey:BlockchainID"`
 	Indexed      bool
+	BlockHash    string `gorm:"not null;default:''"` // hex encoded, used to detect blocks that were indexed from a fork
+	AppHash      string `gorm:"not null;default:''"` // hex encoded, from the block header
 }
 
 type FailedBlock struct {
@@ -20,6 +22,14 @@ type FailedBlock struct {
 	Height       int64 `gorm:"uniqueIndex:failedchainheight"`
 	BlockchainID uint  `gorm:"uniqueIndex:failedchainheight"`
 	Chain        Chain `gorm:"foreignKey:BlockchainID"`
+	// The details of the latest failure, the message type and tx hash are empty if the failure was not caused by a tx
+	Code        string `gorm:"not null;default:''"`
+	Error       string `gorm:"not null;default:''"`
+	MessageType string `gorm:"not null;default:''"`
+	TxHash      string `gorm:"not null;default:''"`
+	Attempts    int    `gorm:"not null;default:1"`
+	FirstSeen   time.Time
+	LastSeen    time.Time
 }
 
 type FailedEventBlock struct {
@@ -27,6 +37,9 @@ type FailedEventBlock struct {
 	Height       int64 `gorm:"uniqueIndex:failedchaineventheight"`
 	BlockchainID uint  `gorm:"uniqueIndex:failedchaineventheight"`
 	Chain        Chain `gorm:"foreignKey:BlockchainID"`
+	Attempts     int   `gorm:"not null;default:1"`
+	FirstSeen    time.Time
+	LastSeen     time.Time
 }
 
 type Chain struct {
@@ -39,7 +52,7 @@ type Tx struct {
 	ID              uint
 	Hash            string `gorm:"uniqueIndex"`
 	Code            uint32
-	BlockID         uint
+	BlockID         uint `gorm:"index"`
 	Block           Block
 	SignerAddressID *uint // *int allows foreign key to be null
 	SignerAddress   Address
@@ -57,6 +70,21 @@ type Fee struct {
 	PayerAddress   Address         `gorm:"foreignKey:PayerAddressID"`
 }
 
+// RawTx is the on-chain data of a tx, only stored when raw tx storage is enabled. It lets the parsed data be audited
+// back to the chain and lets a tx be parsed again without querying an archive node.
+type RawTx struct {
+	ID        uint
+	TxID      uint `gorm:"uniqueIndex"`
+	Tx        Tx
+	TxBytes   []byte  // protobuf encoded tx
+	TxJSON    *string `gorm:"type:jsonb"` // decoded tx, null if it contains messages the codec could not decode
+	Memo      string
+	GasWanted int64
+	GasUsed   int64
+	RawLog    string
+	Logs      string `gorm:"type:jsonb"` // events of each message, as given to the message parsers
+}
+
 // dbTypes.Address{Address: currTx.FeePayer().String()}
 
 type Address struct {
@@ -76,6 +104,19 @@ type Message struct {
 	MessageTypeID uint `gorm:"foreignKey:MessageTypeID,index:idx_txid_typeid"`
 	MessageType   MessageType
 	MessageIndex  int
+	// Position of the message inside an authz MsgExec (dotted for nested execs), empty for top level messages
+	AuthzMsgIndex string `gorm:"not null;default:''"`
+}
+
+// UnparsedMessage is a message that had no parser when it was indexed, or whose type the codec could not decode,
+// stored instead of failing its block when unknown messages are handled leniently
+type UnparsedMessage struct {
+	ID           uint
+	MessageID    uint `gorm:"uniqueIndex"`
+	Message      Message
+	MessageJSON  *string `gorm:"type:jsonb"` // decoded message, null if the codec could not encode it
+	TypeURL      string  `gorm:"not null;default:''"`
+	MessageBytes []byte  // protobuf encoded message, as it was in the tx
 }
 
 const (
@@ -130,12 +171,33 @@ type TaxableTransaction struct {
 	SenderAddress          Address
 	ReceiverAddressID      *uint `gorm:"index:idx_receiver"`
 	ReceiverAddress        Address
+	// Derived from the bank events of a message without a parser, rather than by a parser for the message type
+	Inferred bool `gorm:"not null;default:false"`
+	// Set when exporting, the message is an IBC transfer between two of the exported addresses
+	SelfTransfer bool `gorm:"-"`
 }
 
 func (TaxableTransaction) TableName() string {
 	return "taxable_tx" // Legacy
 }
 
+// AddressBalance is the balance of an address in a denom after a block that changed it, derived from the taxable txs,
+// fees and taxable events stored for the block. Kept up to date as blocks are written, see refreshBalances.
+type AddressBalance struct {
+	ID             uint
+	BlockchainID   uint            `gorm:"uniqueIndex:addrdenomheight,priority:1"`
+	Chain          Chain           `gorm:"foreignKey:BlockchainID"`
+	AddressID      uint            `gorm:"uniqueIndex:addrdenomheight,priority:2"`
+	Address        Address         `gorm:"foreignKey:AddressID"`
+	DenominationID uint            `gorm:"uniqueIndex:addrdenomheight,priority:3"`
+	Denomination   Denom           `gorm:"foreignKey:DenominationID"`
+	Height         int64           `gorm:"uniqueIndex:addrdenomheight,priority:4"`
+	BlockID        uint            `gorm:"index:idx_balance_block"`
+	Block          Block           `gorm:"foreignKey:BlockID"`
+	Change         decimal.Decimal `gorm:"type:decimal(78,0);"`
+	Balance        decimal.Decimal `gorm:"type:decimal(78,0);"`
+}
+
 type Denom struct {
 	ID     uint
 	Base   string `gorm:"uniqueIndex"`
@@ -156,12 +218,15 @@ type TxDBWrapper struct {
 	Tx            Tx
 	SignerAddress Address
 	Messages      []MessageDBWrapper
+	RawTx         *RawTx // nil unless raw tx storage is enabled
 }
 
 // Store messages with their taxable events for easy database creation
 type MessageDBWrapper struct {
 	Message    Message
 	TaxableTxs []TaxableTxDBWrapper
+	Unparsed   *UnparsedMessage // set for messages without a parser that are stored as unparsed messages
+	IBCPacket  *IBCPacket       // set for ICS-20 transfers sent or received by the message, linked across chains
 }
 
 // Store taxable tx with their sender/receiver address for easy database creation
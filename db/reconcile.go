@@ -0,0 +1,32 @@
+package db
+
+import (
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// GetNetPositions gets the net amount of each denom the address gained up to and including the height by base denom,
+// going by the taxable txs, taxable events and fees stored for the chain
+func GetNetPositions(db *gorm.DB, chainID uint, address string, height int64) (map[string]decimal.Decimal, error) {
+	var rows []struct {
+		Denom  string
+		Amount decimal.Decimal
+	}
+	err := db.Raw(`SELECT denoms.base AS denom, SUM(balance_changes.amount) AS amount
+		FROM `+balanceChanges+`
+		JOIN blocks ON blocks.id = balance_changes.block_id
+		JOIN denoms ON denoms.id = balance_changes.denomination_id
+		WHERE balance_changes.address_id = (SELECT id FROM addresses WHERE address = ?)
+			AND blocks.blockchain_id = ?::int AND blocks.height <= ?
+		GROUP BY denoms.base`, address, chainID, height).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	positions := make(map[string]decimal.Decimal, len(rows))
+	for _, row := range rows {
+		positions[row.Denom] = row.Amount
+	}
+	return positions, nil
+}
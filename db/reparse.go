@@ -0,0 +1,202 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// StoredRawTx is a raw tx with the tx and block data needed to parse it again
+type StoredRawTx struct {
+	RawTx
+	Hash      string
+	Code      uint32
+	Height    int64
+	TimeStamp time.Time
+}
+
+// TaxableTxFingerprint identifies a taxable tx by what it records rather than by its IDs, so the output of the parsers
+// can be compared to the rows in the DB
+type TaxableTxFingerprint struct {
+	MessageType            string
+	MessageIndex           int
+	AuthzMsgIndex          string
+	AmountSent             string
+	DenominationSentID     uint
+	AmountReceived         string
+	DenominationReceivedID uint
+	SenderAddress          string
+	ReceiverAddress        string
+	Inferred               bool
+}
+
+// GetStoredRawTxs gets up to limit raw txs of the chain with an ID after afterID, in ID order.
+// An endHeight of -1 means no upper bound, an empty messageType means txs of any message type.
+func GetStoredRawTxs(db *gorm.DB, chainID uint, startHeight int64, endHeight int64, messageType string, afterID uint, limit int) ([]StoredRawTx, error) {
+	query := db.Table("raw_txes").
+		Select("raw_txes.*, txes.hash, txes.code, blocks.height, blocks.time_stamp").
+		Joins("JOIN txes ON txes.id = raw_txes.tx_id").
+		Joins("JOIN blocks ON blocks.id = txes.block_id").
+		Where("blocks.blockchain_id = ?::int AND blocks.height >= ? AND raw_txes.id > ?", chainID, startHeight, afterID)
+
+	if endHeight != -1 {
+		query = query.Where("blocks.height <= ?", endHeight)
+	}
+
+	if messageType != "" {
+		query = query.Where(`EXISTS (SELECT 1 FROM messages
+			JOIN message_types ON message_types.id = messages.message_type_id
+			WHERE messages.tx_id = txes.id AND message_types.message_type = ?)`, messageType)
+	}
+
+	var rawTxs []StoredRawTx
+	err := query.Order("raw_txes.id asc").Limit(limit).Scan(&rawTxs).Error
+	return rawTxs, err
+}
+
+// GetTaxableTxFingerprints gets the fingerprints of the taxable txs stored for each of the txs
+func GetTaxableTxFingerprints(db *gorm.DB, txIDs []uint) (map[uint][]TaxableTxFingerprint, error) {
+	type txFingerprint struct {
+		TxID uint
+		TaxableTxFingerprint
+	}
+
+	fingerprints := make(map[uint][]TaxableTxFingerprint)
+	err := inChunks(len(txIDs), func(start, end int) error {
+		var rows []txFingerprint
+		err := db.Raw(`SELECT messages.tx_id, message_types.message_type, messages.message_index, messages.authz_msg_index,
+				COALESCE(taxable_tx.amount_sent, 0)::text AS amount_sent, COALESCE(taxable_tx.denomination_sent_id, 0) AS denomination_sent_id,
+				COALESCE(taxable_tx.amount_received, 0)::text AS amount_received, COALESCE(taxable_tx.denomination_received_id, 0) AS denomination_received_id,
+				COALESCE(sender.address, '') AS sender_address, COALESCE(receiver.address, '') AS receiver_address,
+				taxable_tx.inferred
+			FROM taxable_tx
+			JOIN messages ON messages.id = taxable_tx.message_id
+			JOIN message_types ON message_types.id = messages.message_type_id
+			LEFT JOIN addresses sender ON sender.id = taxable_tx.sender_address_id
+			LEFT JOIN addresses receiver ON receiver.id = taxable_tx.receiver_address_id
+			WHERE messages.tx_id IN ?`, txIDs[start:end]).Scan(&rows).Error
+		if err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			fingerprints[row.TxID] = append(fingerprints[row.TxID], row.TaxableTxFingerprint)
+		}
+		return nil
+	})
+
+	return fingerprints, err
+}
+
+// GetUnparsedMessageCounts gets the number of unparsed messages stored for each of the txs
+func GetUnparsedMessageCounts(db *gorm.DB, txIDs []uint) (map[uint]int, error) {
+	counts := make(map[uint]int)
+	err := inChunks(len(txIDs), func(start, end int) error {
+		var rows []struct {
+			TxID  uint
+			Count int
+		}
+		err := db.Raw(`SELECT messages.tx_id, COUNT(*) AS count
+			FROM unparsed_messages
+			JOIN messages ON messages.id = unparsed_messages.message_id
+			WHERE messages.tx_id IN ?
+			GROUP BY messages.tx_id`, txIDs[start:end]).Scan(&rows).Error
+		if err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			counts[row.TxID] = row.Count
+		}
+		return nil
+	})
+
+	return counts, err
+}
+
+// CountUnparsedMessages counts the messages of a parsed tx that would be stored as unparsed messages
+func CountUnparsedMessages(tx TxDBWrapper) int {
+	count := 0
+	for _, message := range tx.Messages {
+		if message.Unparsed != nil {
+			count++
+		}
+	}
+	return count
+}
+
+// GetTxTaxableTxFingerprints builds the fingerprints of the taxable txs of a parsed tx, as they would be stored
+func GetTxTaxableTxFingerprints(tx TxDBWrapper) []TaxableTxFingerprint {
+	var fingerprints []TaxableTxFingerprint
+	for _, message := range tx.Messages {
+		for _, taxableTx := range message.TaxableTxs {
+			if skipTaxableTx(taxableTx) {
+				continue
+			}
+
+			fingerprints = append(fingerprints, TaxableTxFingerprint{
+				MessageType:            message.Message.MessageType.MessageType,
+				MessageIndex:           message.Message.MessageIndex,
+				AuthzMsgIndex:          message.Message.AuthzMsgIndex,
+				AmountSent:             taxableTx.TaxableTx.AmountSent.String(),
+				DenominationSentID:     taxableTx.TaxableTx.DenominationSent.ID,
+				AmountReceived:         taxableTx.TaxableTx.AmountReceived.String(),
+				DenominationReceivedID: taxableTx.TaxableTx.DenominationReceived.ID,
+				SenderAddress:          taxableTx.SenderAddress.Address,
+				ReceiverAddress:        taxableTx.ReceiverAddress.Address,
+				Inferred:               taxableTx.TaxableTx.Inferred,
+			})
+		}
+	}
+	return fingerprints
+}
+
+// DiffTaxableTxFingerprints returns the fingerprints only in parsed (added) and the ones only in stored (removed)
+func DiffTaxableTxFingerprints(stored []TaxableTxFingerprint, parsed []TaxableTxFingerprint) (added []TaxableTxFingerprint, removed []TaxableTxFingerprint) {
+	// A message can have identical taxable txs, so this compares counts rather than sets
+	counts := make(map[TaxableTxFingerprint]int)
+	for _, fingerprint := range stored {
+		counts[fingerprint]++
+	}
+
+	for _, fingerprint := range parsed {
+		if counts[fingerprint] > 0 {
+			counts[fingerprint]--
+		} else {
+			added = append(added, fingerprint)
+		}
+	}
+
+	for _, fingerprint := range stored {
+		if counts[fingerprint] > 0 {
+			counts[fingerprint]--
+			removed = append(removed, fingerprint)
+		}
+	}
+
+	return added, removed
+}
+
+// ReplaceTaxableTxs deletes the taxable txs and unparsed messages of the txs and writes the blocks again with the txs parsed anew, in a single
+// DB transaction. The blocks only need to hold the txs being replaced.
+func ReplaceTaxableTxs(db *gorm.DB, blocks []BlockDBWrapper, txIDs []uint, dbChainID uint) error {
+	return db.Transaction(func(dbTransaction *gorm.DB) error {
+		err := inChunks(len(txIDs), func(start, end int) error {
+			for _, table := range []string{"taxable_tx", "unparsed_messages"} {
+				err := dbTransaction.
+					Exec(fmt.Sprintf("DELETE FROM %s WHERE message_id IN (SELECT id FROM messages WHERE tx_id IN ?)", table), txIDs[start:end]).
+					Error
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		return IndexNewBlocks(dbTransaction, blocks, dbChainID)
+	})
+}
@@ -0,0 +1,33 @@
+package db
+
+import "gorm.io/gorm"
+
+// UnknownMessageType is the number of messages of a type that were stored as unparsed messages
+type UnknownMessageType struct {
+	MessageType string
+	Messages    int64
+	Txs         int64
+	MinHeight   int64
+	MaxHeight   int64
+}
+
+// GetUnknownMessageTypes groups the unparsed messages of the chain in the height range by message type, most common
+// first. An endHeight of -1 means no upper bound.
+func GetUnknownMessageTypes(db *gorm.DB, chainID uint, startHeight int64, endHeight int64) ([]UnknownMessageType, error) {
+	query := db.Table("unparsed_messages").
+		Select(`message_types.message_type, COUNT(*) AS messages, COUNT(DISTINCT messages.tx_id) AS txs,
+			MIN(blocks.height) AS min_height, MAX(blocks.height) AS max_height`).
+		Joins("JOIN messages ON messages.id = unparsed_messages.message_id").
+		Joins("JOIN message_types ON message_types.id = messages.message_type_id").
+		Joins("JOIN txes ON txes.id = messages.tx_id").
+		Joins("JOIN blocks ON blocks.id = txes.block_id").
+		Where("blocks.blockchain_id = ?::int AND blocks.height >= ?", chainID, startHeight)
+
+	if endHeight != -1 {
+		query = query.Where("blocks.height <= ?", endHeight)
+	}
+
+	var messageTypes []UnknownMessageType
+	err := query.Group("message_types.message_type").Order("messages desc").Scan(&messageTypes).Error
+	return messageTypes, err
+}
@@ -0,0 +1,58 @@
+package db
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// BlockIndexSummary is what is stored for an indexed block, used to verify the index against the chain
+type BlockIndexSummary struct {
+	ID           uint
+	Height       int64
+	TimeStamp    time.Time
+	Indexed      bool
+	TxCount      int
+	MessageCount int // top level messages only, authz executed messages are not counted
+}
+
+// GetBlockIndexSummaries gets the summaries of the stored blocks in the height range by height
+func GetBlockIndexSummaries(db *gorm.DB, chainID uint, startHeight int64, endHeight int64) (map[int64]BlockIndexSummary, error) {
+	var rows []BlockIndexSummary
+	err := db.Raw(`SELECT blocks.id, blocks.height, blocks.time_stamp, blocks.indexed,
+			(SELECT COUNT(*) FROM txes WHERE txes.block_id = blocks.id) AS tx_count,
+			(SELECT COUNT(*) FROM messages JOIN txes ON txes.id = messages.tx_id
+				WHERE txes.block_id = blocks.id AND messages.authz_msg_index = '') AS message_count
+		FROM blocks
+		WHERE blocks.blockchain_id = ?::int AND blocks.height >= ? AND blocks.height <= ?`, chainID, startHeight, endHeight).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make(map[int64]BlockIndexSummary, len(rows))
+	for _, row := range rows {
+		summaries[row.Height] = row
+	}
+	return summaries, nil
+}
+
+// GetFailedBlockHeightsInRange gets the heights in failed_blocks within the height range
+func GetFailedBlockHeightsInRange(db *gorm.DB, chainID uint, startHeight int64, endHeight int64) ([]int64, error) {
+	var heights []int64
+	err := db.Model(&FailedBlock{}).
+		Where("blockchain_id = ?::int AND height >= ? AND height <= ?", chainID, startHeight, endHeight).
+		Order("height asc").
+		Pluck("height", &heights).Error
+	return heights, err
+}
+
+// GetFailedEventBlockHeightsInRange gets the heights in failed_event_blocks within the height range
+func GetFailedEventBlockHeightsInRange(db *gorm.DB, chainID uint, startHeight int64, endHeight int64) ([]int64, error) {
+	var heights []int64
+	err := db.Model(&FailedEventBlock{}).
+		Where("blockchain_id = ?::int AND height >= ? AND height <= ?", chainID, startHeight, endHeight).
+		Order("height asc").
+		Pluck("height", &heights).Error
+	return heights, err
+}
@@ -0,0 +1,171 @@
+package db
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DefiantLabs/cosmos-tax-cli/cosmos/events"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WatchedAddress is an address whose txs and events are stored when the indexer runs in watch mode
+type WatchedAddress struct {
+	ID           uint
+	BlockchainID uint    `gorm:"uniqueIndex:watchedchainaddress"`
+	Chain        Chain   `gorm:"foreignKey:BlockchainID"`
+	AddressID    uint    `gorm:"uniqueIndex:watchedchainaddress"`
+	Address      Address `gorm:"foreignKey:AddressID"`
+	AddedAt      time.Time
+}
+
+// AddWatchedAddresses adds the addresses to the watch list of the chain and returns the ones that were not watched yet
+func AddWatchedAddresses(db *gorm.DB, chainID uint, addresses []string) ([]string, error) {
+	var added []string
+	err := db.Transaction(func(dbTransaction *gorm.DB) error {
+		addressIDs, err := upsertAddresses(dbTransaction, uniqueNonEmpty(normalizeAddresses(addresses)))
+		if err != nil {
+			return err
+		}
+
+		for address, id := range addressIDs {
+			result := dbTransaction.Clauses(clause.OnConflict{DoNothing: true}).
+				Omit(clause.Associations).
+				Create(&WatchedAddress{BlockchainID: chainID, AddressID: id, AddedAt: time.Now()})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected > 0 {
+				added = append(added, address)
+			}
+		}
+		return nil
+	})
+	return added, err
+}
+
+// RemoveWatchedAddresses removes the addresses from the watch list of the chain, the data already stored is kept
+func RemoveWatchedAddresses(db *gorm.DB, chainID uint, addresses []string) (int64, error) {
+	result := db.Exec(`DELETE FROM watched_addresses
+		WHERE blockchain_id = ?::int AND address_id IN (SELECT id FROM addresses WHERE address IN ?)`, chainID, normalizeAddresses(addresses))
+	return result.RowsAffected, result.Error
+}
+
+// GetWatchedAddresses gets the watch list of the chain
+func GetWatchedAddresses(db *gorm.DB, chainID uint) ([]WatchedAddress, error) {
+	var watched []WatchedAddress
+	err := db.Preload("Address").
+		Where("blockchain_id = ?::int", chainID).
+		Order("added_at asc").
+		Find(&watched).Error
+	return watched, err
+}
+
+// Taxable tx addresses are stored lower case
+func normalizeAddresses(addresses []string) []string {
+	normalized := make([]string, len(addresses))
+	for i, address := range addresses {
+		normalized[i] = strings.ToLower(strings.TrimSpace(address))
+	}
+	return normalized
+}
+
+// WatchList is the set of addresses the indexer stores data for in watch mode. A nil WatchList stores everything.
+// It is safe for concurrent use, the set can be reloaded while blocks are being written.
+type WatchList struct {
+	mu        sync.RWMutex
+	addresses map[string]struct{}
+}
+
+// LoadWatchList loads the watch list of the chain
+func LoadWatchList(db *gorm.DB, chainID uint) (*WatchList, error) {
+	watchList := &WatchList{}
+	return watchList, watchList.Reload(db, chainID)
+}
+
+// Reload replaces the set with the current watch list of the chain, so addresses added while indexing are picked up
+func (w *WatchList) Reload(db *gorm.DB, chainID uint) error {
+	watched, err := GetWatchedAddresses(db, chainID)
+	if err != nil {
+		return err
+	}
+
+	addresses := make(map[string]struct{}, len(watched))
+	for _, address := range watched {
+		addresses[address.Address.Address] = struct{}{}
+	}
+
+	w.mu.Lock()
+	w.addresses = addresses
+	w.mu.Unlock()
+	return nil
+}
+
+// Len is the number of watched addresses
+func (w *WatchList) Len() int {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return len(w.addresses)
+}
+
+func (w *WatchList) watched(address string) bool {
+	_, ok := w.addresses[strings.ToLower(address)]
+	return ok
+}
+
+// FilterTxs keeps the txs that touch a watched address, as the signer, a fee payer or a party of a taxable tx.
+// A kept tx is stored whole, including the taxable txs between other addresses.
+func (w *WatchList) FilterTxs(txs []TxDBWrapper) []TxDBWrapper {
+	if w == nil {
+		return txs
+	}
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	var kept []TxDBWrapper
+	for _, tx := range txs {
+		if w.txTouchesWatched(tx) {
+			kept = append(kept, tx)
+		}
+	}
+	return kept
+}
+
+func (w *WatchList) txTouchesWatched(tx TxDBWrapper) bool {
+	if w.watched(tx.SignerAddress.Address) {
+		return true
+	}
+	for _, fee := range tx.Tx.Fees {
+		if w.watched(fee.PayerAddress.Address) {
+			return true
+		}
+	}
+	for _, message := range tx.Messages {
+		for _, taxableTx := range message.TaxableTxs {
+			if w.watched(taxableTx.SenderAddress.Address) || w.watched(taxableTx.ReceiverAddress.Address) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// FilterEvents keeps the block events of watched addresses
+func (w *WatchList) FilterEvents(blockEvents []events.EventRelevantInformation) []events.EventRelevantInformation {
+	if w == nil {
+		return blockEvents
+	}
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	var kept []events.EventRelevantInformation
+	for _, blockEvent := range blockEvents {
+		if w.watched(blockEvent.Address) {
+			kept = append(kept, blockEvent)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,117 @@
+package db
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	BlockJobPending = "pending"
+	BlockJobClaimed = "claimed"
+	BlockJobDone    = "done"
+	BlockJobFailed  = "failed"
+)
+
+// BlockJob is a block height in the persistent work queue. Jobs are claimed with a lease so several indexer processes
+// can share the same range, a job claimed by a process that died is picked up again once its lease expires.
+type BlockJob struct {
+	ID             uint
+	Height         int64     `gorm:"uniqueIndex:jobchainheight"`
+	BlockchainID   uint      `gorm:"uniqueIndex:jobchainheight;index:idx_job_chain_status"`
+	Chain          Chain     `gorm:"foreignKey:BlockchainID"`
+	Status         string    `gorm:"index:idx_job_chain_status;not null;default:'pending'"`
+	Owner          string    // the indexer process holding the lease
+	LeaseExpiresAt time.Time // only meaningful while the job is claimed
+	Attempts       uint
+	LastError      string
+	UpdatedAt      time.Time
+}
+
+// EnqueueBlockJobRange adds a job for every height in [startHeight, endHeight].
+// Heights that already have a job are left alone, unless requeue is set in which case finished jobs are reset to pending.
+// Without requeue, heights that are already indexed in the blocks table are recorded as done.
+func EnqueueBlockJobRange(db *gorm.DB, chainID uint, startHeight int64, endHeight int64, requeue bool) error {
+	if startHeight > endHeight {
+		return nil
+	}
+
+	onConflict := "DO NOTHING"
+	if requeue {
+		onConflict = "DO UPDATE SET status = 'pending', attempts = 0, updated_at = now() WHERE block_jobs.status <> 'claimed'"
+	}
+
+	return db.Exec(`INSERT INTO block_jobs (height, blockchain_id, status, attempts, updated_at)
+		SELECT h, ?::int, CASE WHEN ?::bool OR blocks.id IS NULL THEN 'pending' ELSE 'done' END, 0, now()
+		FROM generate_series(?::bigint, ?::bigint) AS h
+		LEFT JOIN blocks ON blocks.height = h AND blocks.blockchain_id = ?::int AND blocks.indexed = true AND blocks.time_stamp != '0001-01-01T00:00:00.000Z'
+		ON CONFLICT (height, blockchain_id) `+onConflict, chainID, requeue, startHeight, endHeight, chainID).Error
+}
+
+// EnqueueBlockJobs adds a job for every given height, see EnqueueBlockJobRange for the requeue behavior
+func EnqueueBlockJobs(db *gorm.DB, chainID uint, heights []int64, requeue bool) error {
+	for _, height := range heights {
+		if err := EnqueueBlockJobRange(db, chainID, height, height, requeue); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ClaimBlockJobs leases up to limit of the lowest pending (or lease expired) jobs to the owner and returns their heights.
+// Rows locked by another claim in progress are skipped so concurrent indexers never claim the same job.
+func ClaimBlockJobs(db *gorm.DB, chainID uint, owner string, limit int, lease time.Duration) ([]int64, error) {
+	var heights []int64
+	err := db.Raw(`UPDATE block_jobs SET status = 'claimed', owner = ?, lease_expires_at = now() + ?::int * interval '1 second', attempts = attempts + 1, updated_at = now()
+		WHERE id IN (
+			SELECT id FROM block_jobs
+			WHERE blockchain_id = ?::int AND (status = 'pending' OR (status = 'claimed' AND lease_expires_at < now()))
+			ORDER BY height ASC
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING height`, owner, int64(lease.Seconds()), chainID, limit).Scan(&heights).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return heights, nil
+}
+
+// CompleteBlockJobs marks the jobs for the heights as done
+func CompleteBlockJobs(db *gorm.DB, chainID uint, heights []int64) error {
+	return db.Model(&BlockJob{}).
+		Where("height IN ? AND blockchain_id = ?::int", heights, chainID).
+		Updates(map[string]interface{}{"status": BlockJobDone, "last_error": ""}).Error
+}
+
+// FailBlockJob marks the job for the height as failed, it will not be claimed again until it is requeued
+func FailBlockJob(db *gorm.DB, chainID uint, height int64, errMsg string) error {
+	return db.Model(&BlockJob{}).
+		Where("height = ? AND blockchain_id = ?::int", height, chainID).
+		Updates(map[string]interface{}{"status": BlockJobFailed, "last_error": errMsg}).Error
+}
+
+// GetHighestBlockJobHeight returns the highest height that has been enqueued for the chain, this is the checkpoint the
+// enqueue process resumes from. The bool return is false if nothing has been enqueued yet.
+func GetHighestBlockJobHeight(db *gorm.DB, chainID uint) (int64, bool, error) {
+	var job BlockJob
+	err := db.Where("blockchain_id = ?::int", chainID).Order("height desc").First(&job).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, false, nil
+	} else if err != nil {
+		return 0, false, err
+	}
+
+	return job.Height, true, nil
+}
+
+// ReleaseBlockJobs puts the jobs the owner still holds back to pending, so they can be claimed right away instead of
+// waiting for their lease to expire
+func ReleaseBlockJobs(db *gorm.DB, chainID uint, owner string) (int64, error) {
+	res := db.Model(&BlockJob{}).
+		Where("blockchain_id = ?::int AND owner = ? AND status = ?", chainID, owner, BlockJobClaimed).
+		Updates(map[string]interface{}{"status": BlockJobPending, "owner": ""})
+	return res.RowsAffected, res.Error
+}
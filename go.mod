@@ -30,9 +30,11 @@ require (
 	github.com/cometbft/cometbft v0.38.0
 	github.com/cosmos/ibc-go/v7 v7.4.1
 	github.com/go-git/go-git/v5 v5.11.0
+	github.com/mitchellh/mapstructure v1.5.0
 	github.com/osmosis-labs/osmosis/v25 v25.0.2
 	github.com/osmosis-labs/osmosis/x/epochs v0.0.9
 	github.com/preichenberger/go-coinbasepro/v2 v2.1.0
+	github.com/prometheus/client_golang v1.19.1
 	github.com/rs/zerolog v1.31.0
 	github.com/swaggo/files v1.0.0
 	github.com/swaggo/gin-swagger v1.5.3
@@ -199,7 +201,6 @@ require (
 	github.com/minio/highwayhash v1.0.2 // indirect
 	github.com/mitchellh/go-homedir v1.1.0 // indirect
 	github.com/mitchellh/go-testing-interface v1.14.1 // indirect
-	github.com/mitchellh/mapstructure v1.5.0 // indirect
 	github.com/modern-go/concurrent v0.0.0-20180306012644-bacd9c7ef1dd // indirect
 	github.com/modern-go/reflect2 v1.0.2 // indirect
 	github.com/mtibben/percent v0.2.1 // indirect
@@ -213,7 +214,6 @@ require (
 	github.com/pjbgf/sha1cd v0.3.0 // indirect
 	github.com/pkg/errors v0.9.1 // indirect
 	github.com/pmezard/go-difflib v1.0.1-0.20181226105442-5d4384ee4fb2 // indirect
-	github.com/prometheus/client_golang v1.19.1 // indirect
 	github.com/prometheus/client_model v0.5.0 // indirect
 	github.com/prometheus/common v0.48.0 // indirect
 	github.com/prometheus/procfs v0.12.0 // indirect
@@ -0,0 +1,21 @@
+package juno
+
+import (
+	"github.com/DefiantLabs/cosmos-tax-cli/core"
+	"github.com/DefiantLabs/cosmos-tax-cli/tasks"
+)
+
+func init() {
+	core.RegisterChainPlugin(plugin{})
+}
+
+// plugin registers the Juno assetlist denom upsert
+type plugin struct{}
+
+func (plugin) ChainID() string {
+	return ChainID
+}
+
+func (plugin) Register(registry *core.Registry) {
+	registry.SetDenomUpsert(tasks.UpsertJunoDenoms)
+}
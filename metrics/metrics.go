@@ -0,0 +1,167 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/DefiantLabs/cosmos-tax-cli/config"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "cosmos_tax_cli"
+
+var (
+	BlocksProcessed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "blocks_processed_total",
+		Help:      "Number of blocks written to the DB (or processed in a dry run), by chain.",
+	}, []string{"chain"})
+
+	IndexedHeight = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "indexed_height",
+		Help:      "Height of the highest block written to the DB (or processed in a dry run), by chain.",
+	}, []string{"chain"})
+
+	ChainHeight = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "chain_height",
+		Help:      "Latest height reported by the RPC node, by chain.",
+	}, []string{"chain"})
+
+	HeadLag = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "head_lag_blocks",
+		Help:      "Number of blocks between the latest chain height and the indexed height, by chain.",
+	}, []string{"chain"})
+
+	FailedBlocks = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "failed_blocks_total",
+		Help:      "Number of blocks recorded as failed after the error policy gave up on them, by chain and final failure code.",
+	}, []string{"chain", "code"})
+
+	ExhaustedFailedBlocks = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "exhausted_failed_blocks",
+		Help:      "Number of failed blocks that reached the max retry attempts and are no longer retried, by chain and kind.",
+	}, []string{"chain", "kind"})
+
+	RPCLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "rpc_request_duration_seconds",
+		Help:      "Latency of RPC requests, by method, endpoint and outcome.",
+		Buckets:   prometheus.ExponentialBuckets(0.05, 2, 10),
+	}, []string{"method", "endpoint", "outcome"})
+
+	DBWriteLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_write_duration_seconds",
+		Help:      "Latency of DB writes (a batch of blocks is a single write), by chain and kind of data written.",
+		Buckets:   prometheus.ExponentialBuckets(0.005, 2, 12),
+	}, []string{"chain", "kind"})
+)
+
+// The last heights set, kept to compute the head lag
+type heights struct {
+	indexed, chain atomic.Int64
+}
+
+// The heights of every chain by chain ID
+var chainHeights sync.Map
+
+func heightsOf(chainID string) *heights {
+	h, _ := chainHeights.LoadOrStore(chainID, &heights{})
+	return h.(*heights)
+}
+
+func init() {
+	prometheus.MustRegister(BlocksProcessed, IndexedHeight, ChainHeight, HeadLag, FailedBlocks, ExhaustedFailedBlocks, RPCLatency, DBWriteLatency)
+}
+
+// RegisterChannelDepth exposes the number of items waiting in a channel of the chain, read through the given func at
+// scrape time
+func RegisterChannelDepth(chainID string, channel string, depth func() int) {
+	err := prometheus.Register(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace:   namespace,
+		Name:        "channel_depth",
+		Help:        "Number of items waiting in an indexer channel.",
+		ConstLabels: prometheus.Labels{"chain": chainID, "channel": channel},
+	}, func() float64 { return float64(depth()) }))
+
+	// Registering the same channel twice (e.g. in tests) is harmless, anything else is a programming error
+	var alreadyRegistered prometheus.AlreadyRegisteredError
+	if err != nil && !errors.As(err, &alreadyRegistered) {
+		config.Log.Error("Error registering channel depth metric.", err)
+	}
+}
+
+// ObserveRPC records the latency of an RPC request started at start
+func ObserveRPC(method string, endpoint string, start time.Time, err error) {
+	outcome := "success"
+	if err != nil {
+		outcome = "error"
+	}
+	RPCLatency.WithLabelValues(method, endpoint, outcome).Observe(time.Since(start).Seconds())
+}
+
+// ObserveDBWrite records the latency of a DB write of the chain started at start
+func ObserveDBWrite(chainID string, kind string, start time.Time) {
+	DBWriteLatency.WithLabelValues(chainID, kind).Observe(time.Since(start).Seconds())
+}
+
+// SetIndexedHeight records the last height of the chain written to the DB and updates the head lag
+func SetIndexedHeight(chainID string, height int64) {
+	h := heightsOf(chainID)
+	// Blocks are written out of order by the workers, only move forward
+	for {
+		current := h.indexed.Load()
+		if height <= current {
+			return
+		}
+		if h.indexed.CompareAndSwap(current, height) {
+			break
+		}
+	}
+	IndexedHeight.WithLabelValues(chainID).Set(float64(height))
+	updateHeadLag(chainID, h)
+}
+
+// SetChainHeight records the latest height of the chain and updates the head lag
+func SetChainHeight(chainID string, height int64) {
+	h := heightsOf(chainID)
+	h.chain.Store(height)
+	ChainHeight.WithLabelValues(chainID).Set(float64(height))
+	updateHeadLag(chainID, h)
+}
+
+func updateHeadLag(chainID string, h *heights) {
+	// Not meaningful until both heights are known
+	chain, indexed := h.chain.Load(), h.indexed.Load()
+	if chain > 0 && indexed > 0 {
+		HeadLag.WithLabelValues(chainID).Set(float64(chain - indexed))
+	}
+}
+
+// StartServer serves the metrics on /metrics at the given address in the background
+func StartServer(address string) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+
+	server := &http.Server{
+		Addr:              address,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		config.Log.Infof("Serving metrics on %s/metrics", address)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			config.Log.Error("Metrics server stopped.", err)
+		}
+	}()
+}
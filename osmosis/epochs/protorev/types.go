@@ -11,19 +11,14 @@ import (
 	osmosisEvents "github.com/DefiantLabs/cosmos-tax-cli/osmosis/events"
 )
 
-// protorevDeveloperAddress is the address of the developer account that receives rewards on the weekly Epoch.
-// This will need to be prepopulated by the indexer before module startup
-var protorevDeveloperAddress string
-
-func SetDeveloperAddress(address string) {
-	protorevDeveloperAddress = address
-}
-
 type WrapperBlockCoinReceived struct {
-	Event           abciTypes.Event
-	RewardsReceived sdk.Coins
-	ReceiverAddress string
-	ProtorevEvent   bool
+	// DeveloperAddress is the address of the developer account that receives rewards on the weekly Epoch.
+	// It is looked up on chain when the epoch indexer is set up.
+	DeveloperAddress string
+	Event            abciTypes.Event
+	RewardsReceived  sdk.Coins
+	ReceiverAddress  string
+	ProtorevEvent    bool
 }
 
 func (sf *WrapperBlockCoinReceived) GetType() string {
@@ -43,7 +38,7 @@ func (sf *WrapperBlockCoinReceived) HandleEvent(eventType string, event abciType
 		}
 	}
 
-	if receiverAddr != "" && receiverAmount != "" && receiverAddr == protorevDeveloperAddress {
+	if receiverAddr != "" && receiverAmount != "" && receiverAddr == sf.DeveloperAddress {
 		coins, err := sdk.ParseCoinsNormalized(receiverAmount)
 		if err != nil {
 			return err
@@ -12,9 +12,8 @@ var dayBeginBlockEventTypesToHandlers = map[string][]func() eventTypes.CosmosEve
 	events.BlockEventDistribution: {func() eventTypes.CosmosEvent { return &incentivesEventTypes.WrapperBlockDistribution{} }},
 }
 
-var weekBeginBlockEventTypesToHandlers = map[string][]func() eventTypes.CosmosEvent{
-	events.BlockEventCoinReceived: {func() eventTypes.CosmosEvent { return &protorevEventTypes.WrapperBlockCoinReceived{} }},
-}
+// The protorev handler needs the developer account, it is added by ProtorevEventHandlers once that is looked up
+var weekBeginBlockEventTypesToHandlers = map[string][]func() eventTypes.CosmosEvent{}
 
 var dayEventTypeHandlers = map[string]map[string][]func() eventTypes.CosmosEvent{
 	"begin_block": dayBeginBlockEventTypesToHandlers,
@@ -35,3 +34,17 @@ var EpochIdentifierBlockEventHandlers = map[string]map[string]map[string][]func(
 	epochs.DayEpochIdentifier:  dayEventTypeHandlers,
 	epochs.WeekEpochIdentifier: weekEventTypeHandlers,
 }
+
+// ProtorevEventHandlers returns the handlers of the protorev developer rewards paid on the weekly Epoch to the developer
+// account, in the same form as EpochIdentifierBlockEventHandlers
+func ProtorevEventHandlers(developerAddress string) map[string]map[string]map[string][]func() eventTypes.CosmosEvent {
+	return map[string]map[string]map[string][]func() eventTypes.CosmosEvent{
+		epochs.WeekEpochIdentifier: {
+			"begin_block": {
+				events.BlockEventCoinReceived: {func() eventTypes.CosmosEvent {
+					return &protorevEventTypes.WrapperBlockCoinReceived{DeveloperAddress: developerAddress}
+				}},
+			},
+		},
+	}
+}
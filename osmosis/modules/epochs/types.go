@@ -4,8 +4,3 @@ const (
 	DayEpochIdentifier  = "day"
 	WeekEpochIdentifier = "week"
 )
-
-var OsmosisIndexableEpochs = map[string]bool{
-	DayEpochIdentifier:  true,
-	WeekEpochIdentifier: true,
-}
@@ -0,0 +1,26 @@
+package osmosis
+
+import (
+	"github.com/DefiantLabs/cosmos-tax-cli/core"
+	"github.com/DefiantLabs/cosmos-tax-cli/osmosis/epochs"
+	"github.com/DefiantLabs/cosmos-tax-cli/tasks"
+)
+
+func init() {
+	core.RegisterChainPlugin(plugin{})
+}
+
+// plugin registers the Osmosis message handlers, epoch event handlers and epoch indexer setup and the assetlist denom
+// upsert
+type plugin struct{}
+
+func (plugin) ChainID() string {
+	return ChainID
+}
+
+func (plugin) Register(registry *core.Registry) {
+	registry.AddMessageTypeHandlers(MessageTypeHandler)
+	registry.AddEpochIdentifierEventTypeHandlers(epochs.EpochIdentifierBlockEventHandlers)
+	registry.SetEpochIndexerSetup(setupEpochIndexer)
+	registry.SetDenomUpsert(tasks.UpsertOsmosisDenoms)
+}
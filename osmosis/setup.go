@@ -2,14 +2,15 @@ package osmosis
 
 import (
 	"github.com/DefiantLabs/cosmos-tax-cli/config"
-	"github.com/DefiantLabs/cosmos-tax-cli/osmosis/epochs/protorev"
+	"github.com/DefiantLabs/cosmos-tax-cli/core"
+	epochHandlers "github.com/DefiantLabs/cosmos-tax-cli/osmosis/epochs"
 	"github.com/DefiantLabs/cosmos-tax-cli/osmosis/modules/epochs"
 	"github.com/DefiantLabs/cosmos-tax-cli/rpc"
 	"github.com/DefiantLabs/lens/client"
 )
 
-// SetupOsmosisEpochIndexer sets up the indexer for the osmosis epoch indexing process
-func SetupOsmosisEpochIndexer(cl *client.ChainClient, epochIdentifier string) error {
+// setupEpochIndexer sets up the registry for the osmosis epoch indexing process
+func setupEpochIndexer(cl *client.ChainClient, registry *core.Registry, epochIdentifier string) error {
 	config.Log.Info("Setting up Osmosis Epoch Indexer")
 
 	switch epochIdentifier {
@@ -22,7 +23,7 @@ func SetupOsmosisEpochIndexer(cl *client.ChainClient, epochIdentifier string) er
 			return err
 		}
 
-		protorev.SetDeveloperAddress(resp.DeveloperAccount)
+		registry.AddEpochIdentifierEventTypeHandlers(epochHandlers.ProtorevEventHandlers(resp.DeveloperAccount))
 		config.Log.Debugf("Protorev Developer Account Address: %s", resp.DeveloperAccount)
 	default:
 		config.Log.Infof("Epoch Identifier %s requires no setup, skipping.", epochIdentifier)
@@ -0,0 +1,94 @@
+package rpc
+
+import (
+	lensClient "github.com/DefiantLabs/lens/client"
+	lensQuery "github.com/DefiantLabs/lens/client/query"
+	sdkTypes "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/query"
+	stakingTypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+)
+
+// AccountHoldings is what an address holds at a height, split by where the coins are held
+type AccountHoldings struct {
+	Bank      sdkTypes.Coins
+	Delegated sdkTypes.Coins
+	Unbonding sdkTypes.Coins
+}
+
+// GetAccountHoldingsAtHeight queries the bank balances, delegations and unbonding delegations of the address at the height
+func GetAccountHoldingsAtHeight(cl *lensClient.ChainClient, address string, height int64) (*AccountHoldings, error) {
+	holdings := &AccountHoldings{}
+
+	pg := query.PageRequest{Limit: 100}
+	options := lensQuery.QueryOptions{Height: height, Pagination: &pg}
+	q := lensQuery.Query{Client: cl, Options: &options}
+
+	for {
+		resp, err := q.Balances(address)
+		if err != nil {
+			return nil, err
+		}
+		holdings.Bank = holdings.Bank.Add(resp.Balances...)
+		if resp.Pagination == nil || len(resp.Pagination.NextKey) == 0 {
+			break
+		}
+		pg.Key = resp.Pagination.NextKey
+	}
+
+	pg.Key = nil
+	for {
+		resp, err := q.Delegations(address)
+		if err != nil {
+			return nil, err
+		}
+		for _, delegation := range resp.DelegationResponses {
+			holdings.Delegated = holdings.Delegated.Add(delegation.Balance)
+		}
+		if resp.Pagination == nil || len(resp.Pagination.NextKey) == 0 {
+			break
+		}
+		pg.Key = resp.Pagination.NextKey
+	}
+
+	// Unbonding entries only hold an amount, they are always in the bond denom
+	stakingClient := stakingTypes.NewQueryClient(cl)
+	ctx, cancel := q.GetQueryContext()
+	defer cancel()
+
+	params, err := stakingClient.Params(ctx, &stakingTypes.QueryParamsRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	pg.Key = nil
+	for {
+		resp, err := stakingClient.DelegatorUnbondingDelegations(ctx, &stakingTypes.QueryDelegatorUnbondingDelegationsRequest{
+			DelegatorAddr: address,
+			Pagination:    &pg,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, unbonding := range resp.UnbondingResponses {
+			for _, entry := range unbonding.Entries {
+				holdings.Unbonding = holdings.Unbonding.Add(sdkTypes.NewCoin(params.Params.BondDenom, entry.Balance))
+			}
+		}
+		if resp.Pagination == nil || len(resp.Pagination.NextKey) == 0 {
+			break
+		}
+		pg.Key = resp.Pagination.NextKey
+	}
+
+	return holdings, nil
+}
+
+// GetAccountHoldingsAtHeightFromPool runs GetAccountHoldingsAtHeight on the best node of the pool for the height
+func GetAccountHoldingsAtHeightFromPool(pool *ClientPool, address string, height int64) (resp *AccountHoldings, err error) {
+	err = pool.Do("account_holdings", height, func(cl *lensClient.ChainClient) error {
+		var queryErr error
+		resp, queryErr = GetAccountHoldingsAtHeight(cl, address, height)
+		return queryErr
+	})
+	return resp, err
+}
@@ -0,0 +1,210 @@
+package rpc
+
+import (
+	"errors"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DefiantLabs/cosmos-tax-cli/config"
+	"github.com/DefiantLabs/cosmos-tax-cli/metrics"
+	lensClient "github.com/DefiantLabs/lens/client"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	txTypes "github.com/cosmos/cosmos-sdk/types/tx"
+)
+
+const (
+	// Weight of the latest sample in the latency and error rate moving averages
+	poolEWMAWeight = 0.2
+	// A node is benched after this many consecutive failures
+	poolMaxConsecutiveFailures = 3
+	// How long a benched node is skipped before it is tried again
+	poolNodeCooldown = 30 * time.Second
+	// Added to the score of a node that always fails, scaled down by its error rate
+	poolErrorPenalty = 10 * time.Second
+)
+
+var ErrNoPoolNodeForHeight = errors.New("no RPC node in the pool has history for this height")
+
+var lowestHeightRegex = regexp.MustCompile(`lowest height is (\d+)`)
+
+type poolNode struct {
+	client              *lensClient.ChainClient
+	latency             time.Duration // moving average of successful request latency
+	errorRate           float64       // moving average of failed requests, between 0 and 1
+	consecutiveFailures int
+	benchedUntil        time.Time
+	lowestHeight        int64 // lowest height the node reported it has history for
+}
+
+// score is lower for better nodes, failing nodes are penalized on top of their latency.
+// The penalty is added rather than scaling the latency, since a node that never succeeded has no latency samples and
+// must still sort after the nodes that work. A node that was never tried scores 0, so it is tried first.
+func (n *poolNode) score() float64 {
+	return float64(n.latency) + n.errorRate*float64(poolErrorPenalty)
+}
+
+// ClientPool routes height based RPC queries to the healthiest node that has the history for the height.
+// Nodes are scored on their request latency and error rate, a node that keeps failing is benched for a while and
+// a node that reports it has pruned a height is no longer asked for heights below its lowest available height.
+type ClientPool struct {
+	mu    sync.Mutex
+	nodes []*poolNode
+}
+
+func NewClientPool(clients []*lensClient.ChainClient) *ClientPool {
+	pool := &ClientPool{}
+	for _, cl := range clients {
+		pool.nodes = append(pool.nodes, &poolNode{client: cl})
+	}
+	return pool
+}
+
+// Primary returns the first client of the pool, used for anything that is not height routed (e.g. the codec)
+func (p *ClientPool) Primary() *lensClient.ChainClient {
+	return p.nodes[0].client
+}
+
+// candidates returns the nodes that may have the height, best scored first.
+// Benched nodes are only returned when every node that may have the height is benched.
+func (p *ClientPool) candidates(height int64) []*poolNode {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	var healthy, benched []*poolNode
+	for _, node := range p.nodes {
+		if height > 0 && node.lowestHeight > height {
+			continue
+		}
+		if now.Before(node.benchedUntil) {
+			benched = append(benched, node)
+		} else {
+			healthy = append(healthy, node)
+		}
+	}
+
+	if len(healthy) == 0 {
+		healthy = benched
+	}
+
+	sort.SliceStable(healthy, func(i, j int) bool { return healthy[i].score() < healthy[j].score() })
+	return healthy
+}
+
+func (p *ClientPool) reportSuccess(node *poolNode, latency time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if node.latency == 0 {
+		node.latency = latency
+	} else {
+		node.latency = time.Duration(poolEWMAWeight*float64(latency) + (1-poolEWMAWeight)*float64(node.latency))
+	}
+	node.errorRate = (1 - poolEWMAWeight) * node.errorRate
+	node.consecutiveFailures = 0
+}
+
+func (p *ClientPool) reportFailure(node *poolNode, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// A pruned node is not unhealthy, it just can't serve this height, so only remember its lowest height
+	if lowestHeight, ok := ParseLowestHeight(err); ok {
+		if lowestHeight > node.lowestHeight {
+			config.Log.Infof("RPC node %s only has history from height %d", node.client.Config.RPCAddr, lowestHeight)
+			node.lowestHeight = lowestHeight
+		}
+		return
+	}
+
+	node.errorRate = poolEWMAWeight + (1-poolEWMAWeight)*node.errorRate
+	node.consecutiveFailures++
+	if node.consecutiveFailures >= poolMaxConsecutiveFailures {
+		config.Log.Warnf("RPC node %s failed %d times in a row, benching it for %v", node.client.Config.RPCAddr, node.consecutiveFailures, poolNodeCooldown)
+		node.benchedUntil = time.Now().Add(poolNodeCooldown)
+		node.consecutiveFailures = 0
+	}
+}
+
+// Do runs the query for the height on the best node, failing over to the next best node on error.
+// Errors that would be the same on every node (e.g. unknown type URLs) are returned without failing over.
+// The method is only used to label the request latency metrics.
+func (p *ClientPool) Do(method string, height int64, query func(cl *lensClient.ChainClient) error) error {
+	nodes := p.candidates(height)
+	if len(nodes) == 0 {
+		return ErrNoPoolNodeForHeight
+	}
+
+	var err error
+	for _, node := range nodes {
+		start := time.Now()
+		err = query(node.client)
+		metrics.ObserveRPC(method, node.client.Config.RPCAddr, start, err)
+		if err == nil {
+			p.reportSuccess(node, time.Since(start))
+			return nil
+		}
+
+		if strings.Contains(err.Error(), "unable to resolve type URL") {
+			return err
+		}
+
+		p.reportFailure(node, err)
+		config.Log.Debugf("RPC node %s failed for height %d, trying the next node. Err: %v", node.client.Config.RPCAddr, height, err)
+	}
+
+	return err
+}
+
+// ParseLowestHeight extracts the lowest available height from the error a pruned node returns for missing history
+func ParseLowestHeight(err error) (int64, bool) {
+	if err == nil || !strings.Contains(err.Error(), "is not available, lowest height is") {
+		return 0, false
+	}
+
+	matches := lowestHeightRegex.FindStringSubmatch(err.Error())
+	if len(matches) != 2 {
+		return 0, false
+	}
+
+	lowestHeight, parseErr := strconv.ParseInt(matches[1], 10, 64)
+	if parseErr != nil {
+		return 0, false
+	}
+
+	return lowestHeight, true
+}
+
+// GetTxsByBlockHeightFromPool runs GetTxsByBlockHeight on the best node of the pool for the height
+func GetTxsByBlockHeightFromPool(pool *ClientPool, height int64) (resp *txTypes.GetTxsEventResponse, unpackError error, queryError error) {
+	queryError = pool.Do("tx_search", height, func(cl *lensClient.ChainClient) error {
+		var err error
+		resp, unpackError, err = GetTxsByBlockHeight(cl, height)
+		return err
+	})
+	return resp, unpackError, queryError
+}
+
+// GetBlockByHeightFromPool runs GetBlockByHeight on the best node of the pool for the height
+func GetBlockByHeightFromPool(pool *ClientPool, height int64) (resp *coretypes.ResultBlockResults, err error) {
+	err = pool.Do("block_results", height, func(cl *lensClient.ChainClient) error {
+		var queryErr error
+		resp, queryErr = GetBlockByHeight(cl, height)
+		return queryErr
+	})
+	return resp, err
+}
+
+// GetBlockFromPool runs GetBlock on the best node of the pool for the height
+func GetBlockFromPool(pool *ClientPool, height int64) (resp *coretypes.ResultBlock, err error) {
+	err = pool.Do("block", height, func(cl *lensClient.ChainClient) error {
+		var queryErr error
+		resp, queryErr = GetBlock(cl, height)
+		return queryErr
+	})
+	return resp, err
+}
@@ -0,0 +1,79 @@
+package rpc
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	lensClient "github.com/DefiantLabs/lens/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestPool(addrs ...string) *ClientPool {
+	var clients []*lensClient.ChainClient
+	for _, addr := range addrs {
+		clients = append(clients, &lensClient.ChainClient{Config: &lensClient.ChainClientConfig{RPCAddr: addr}})
+	}
+	return NewClientPool(clients)
+}
+
+func TestParseLowestHeight(t *testing.T) {
+	height, ok := ParseLowestHeight(errors.New("RPC error -32603 - Internal error: height 100 is not available, lowest height is 2500"))
+	assert.True(t, ok)
+	assert.Equal(t, int64(2500), height)
+
+	_, ok = ParseLowestHeight(errors.New("connection refused"))
+	assert.False(t, ok)
+}
+
+func TestClientPoolSkipsPrunedNodes(t *testing.T) {
+	pool := newTestPool("pruned", "archive")
+	pool.reportFailure(pool.nodes[0], errors.New("height 100 is not available, lowest height is 2500"))
+
+	var used []string
+	err := pool.Do("test", 100, func(cl *lensClient.ChainClient) error {
+		used = append(used, cl.Config.RPCAddr)
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"archive"}, used)
+
+	// heights the pruned node has are still routed to it
+	assert.Len(t, pool.candidates(3000), 2)
+}
+
+func TestClientPoolFailover(t *testing.T) {
+	pool := newTestPool("flaky", "healthy")
+	pool.reportSuccess(pool.nodes[0], time.Millisecond)
+	pool.reportSuccess(pool.nodes[1], 2*time.Millisecond)
+
+	var used []string
+	err := pool.Do("test", 10, func(cl *lensClient.ChainClient) error {
+		used = append(used, cl.Config.RPCAddr)
+		if cl.Config.RPCAddr == "flaky" {
+			return errors.New("connection refused")
+		}
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"flaky", "healthy"}, used)
+
+	// enough failures in a row bench the node
+	for i := 0; i < poolMaxConsecutiveFailures; i++ {
+		pool.reportFailure(pool.nodes[0], errors.New("connection refused"))
+	}
+	candidates := pool.candidates(10)
+	assert.Len(t, candidates, 1)
+	assert.Equal(t, "healthy", candidates[0].client.Config.RPCAddr)
+}
+
+func TestClientPoolNodeWithoutSamplesFailingSortsLast(t *testing.T) {
+	pool := newTestPool("dead", "healthy")
+	pool.reportFailure(pool.nodes[0], errors.New("connection refused"))
+	pool.reportSuccess(pool.nodes[1], 500*time.Millisecond)
+
+	candidates := pool.candidates(10)
+	assert.Len(t, candidates, 2)
+	assert.Equal(t, "healthy", candidates[0].client.Config.RPCAddr)
+	assert.Equal(t, "dead", candidates[1].client.Config.RPCAddr)
+}
@@ -10,6 +10,7 @@ import (
 
 	wasmTypes "github.com/CosmWasm/wasmd/x/wasm/types"
 	"github.com/DefiantLabs/cosmos-tax-cli/config"
+	"github.com/DefiantLabs/cosmos-tax-cli/metrics"
 	lensClient "github.com/DefiantLabs/lens/client"
 	lensQuery "github.com/DefiantLabs/lens/client/query"
 	"github.com/cosmos/cosmos-sdk/types/query"
@@ -108,7 +109,9 @@ func GetLatestBlockHeight(cl *lensClient.ChainClient) (int64, error) {
 	ctx, cancel := query.GetQueryContext()
 	defer cancel()
 
+	start := time.Now()
 	resStatus, err := query.Client.RPCClient.Status(ctx)
+	metrics.ObserveRPC("status", cl.Config.RPCAddr, start, err)
 	if err != nil {
 		return 0, err
 	}
@@ -120,7 +123,9 @@ func GetBlockResultRPC(cl *lensClient.ChainClient, height int64) (*coretypes.Res
 	ctx, cancel := query.GetQueryContext()
 	defer cancel()
 
+	start := time.Now()
 	res, err := query.Client.RPCClient.BlockResults(ctx, &height)
+	metrics.ObserveRPC("block_results", cl.Config.RPCAddr, start, err)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,80 @@
+package rpc
+
+import (
+	"sort"
+	"time"
+
+	"github.com/DefiantLabs/cosmos-tax-cli/metrics"
+	lensClient "github.com/DefiantLabs/lens/client"
+	lensQuery "github.com/DefiantLabs/lens/client/query"
+)
+
+// Max results per page the node returns for tx and block searches
+const searchPageSize = 100
+
+// SearchTxHeights pages through the node's tx search for the query and returns the distinct heights of the matching txs
+// in ascending order. The node must have tx indexing enabled for the events in the query.
+func SearchTxHeights(cl *lensClient.ChainClient, query string) ([]int64, error) {
+	q := lensQuery.Query{Client: cl, Options: &lensQuery.QueryOptions{}}
+
+	heights := make(map[int64]struct{})
+	perPage := searchPageSize
+	for page, seen := 1, 0; ; page++ {
+		ctx, cancel := q.GetQueryContext()
+		start := time.Now()
+		resp, err := cl.RPCClient.TxSearch(ctx, query, false, &page, &perPage, "asc")
+		metrics.ObserveRPC("tx_search", cl.Config.RPCAddr, start, err)
+		cancel()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, tx := range resp.Txs {
+			heights[tx.Height] = struct{}{}
+		}
+		seen += len(resp.Txs)
+		if len(resp.Txs) == 0 || seen >= resp.TotalCount {
+			break
+		}
+	}
+
+	return sortedHeights(heights), nil
+}
+
+// SearchBlockHeights pages through the node's block search for the query and returns the heights of the blocks whose
+// BeginBlocker or EndBlocker events match, in ascending order
+func SearchBlockHeights(cl *lensClient.ChainClient, query string) ([]int64, error) {
+	q := lensQuery.Query{Client: cl, Options: &lensQuery.QueryOptions{}}
+
+	heights := make(map[int64]struct{})
+	perPage := searchPageSize
+	for page, seen := 1, 0; ; page++ {
+		ctx, cancel := q.GetQueryContext()
+		start := time.Now()
+		resp, err := cl.RPCClient.BlockSearch(ctx, query, &page, &perPage, "asc")
+		metrics.ObserveRPC("block_search", cl.Config.RPCAddr, start, err)
+		cancel()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, block := range resp.Blocks {
+			heights[block.Block.Height] = struct{}{}
+		}
+		seen += len(resp.Blocks)
+		if len(resp.Blocks) == 0 || seen >= resp.TotalCount {
+			break
+		}
+	}
+
+	return sortedHeights(heights), nil
+}
+
+func sortedHeights(set map[int64]struct{}) []int64 {
+	heights := make([]int64, 0, len(set))
+	for height := range set {
+		heights = append(heights, height)
+	}
+	sort.Slice(heights, func(i, j int) bool { return heights[i] < heights[j] })
+	return heights
+}
@@ -8,8 +8,6 @@ import (
 
 	"github.com/DefiantLabs/cosmos-tax-cli/config"
 	dbTypes "github.com/DefiantLabs/cosmos-tax-cli/db"
-	"github.com/DefiantLabs/cosmos-tax-cli/juno"
-	"github.com/DefiantLabs/cosmos-tax-cli/osmosis"
 	"github.com/DefiantLabs/cosmos-tax-cli/rest"
 	"github.com/DefiantLabs/cosmos-tax-cli/rpc"
 
@@ -33,26 +31,6 @@ type DenomUnit struct {
 	Aliases  []string
 }
 
-var ChainSpecificDenomUpsertFunctions = map[string]func(db *gorm.DB, retryMaxAttempts int64, retryMaxWaitSeconds uint64, conf config.AssetList){
-	osmosis.ChainID: UpsertOsmosisDenoms,
-	juno.ChainID:    UpsertJunoDenoms,
-}
-
-func DoChainSpecificUpsertDenoms(db *gorm.DB, chain string, retryMaxAttempts int64, retryMaxWaitSeconds uint64, conf config.AssetList) {
-	if chain == osmosis.ChainID {
-		UpsertOsmosisDenoms(db, retryMaxAttempts, retryMaxWaitSeconds, conf)
-	}
-
-	if chain == juno.ChainID {
-		UpsertJunoDenoms(db, retryMaxAttempts, retryMaxWaitSeconds, conf)
-	}
-	// may want to move this elsewhere, or eliminate entirely
-	// I would prefer we just grab the denoms when needed always
-	// Current problem: we use the denom cache in various blocks later on
-	dbTypes.CacheDenoms(db)
-	dbTypes.CacheIBCDenoms(db)
-}
-
 func UpsertOsmosisDenoms(db *gorm.DB, retryMaxAttempts int64, retryMaxWaitSeconds uint64, conf config.AssetList) {
 	config.Log.Info("Updating Omsosis specific denoms")
 	url := conf.OsmosisAssetListURL
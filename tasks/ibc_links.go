@@ -0,0 +1,20 @@
+package tasks
+
+import (
+	"fmt"
+
+	"github.com/DefiantLabs/cosmos-tax-cli/config"
+	dbTypes "github.com/DefiantLabs/cosmos-tax-cli/db"
+	"gorm.io/gorm"
+)
+
+// IBCLinkTask links the IBC transfers sent on one indexed chain to the packets received on another, both sides have
+// to be indexed before they can be linked so this runs periodically
+func IBCLinkTask(db *gorm.DB) {
+	linked, err := dbTypes.LinkIBCPackets(db)
+	if err != nil {
+		config.Log.Error("Error linking IBC transfers across chains", err)
+		return
+	}
+	config.Log.Info(fmt.Sprintf("Linked %d IBC transfers across chains", linked))
+}
@@ -8,7 +8,6 @@ import (
 	"github.com/DefiantLabs/cosmos-tax-cli/config"
 	"github.com/DefiantLabs/cosmos-tax-cli/osmosis"
 
-	"github.com/DefiantLabs/cosmos-tax-cli/core"
 	dbUtils "github.com/DefiantLabs/cosmos-tax-cli/db"
 	"github.com/DefiantLabs/cosmos-tax-cli/util"
 	"gorm.io/gorm"
@@ -73,7 +72,7 @@ func getConfig(configFileLocation string) (config.IndexConfig, error) {
 //   - Loads the application config from config.tml, cli args and parses/merges
 //   - Connects to the database and returns the db object
 //   - Returns various values used throughout the application
-func dbSetup(addressRegex string, addressPrefix string) (*gorm.DB, error) {
+func dbSetup() (*gorm.DB, error) {
 	config, err := getConfig("../config.toml")
 	if err != nil {
 		fmt.Println("Error opening configuration file", err)
@@ -86,10 +85,6 @@ func dbSetup(addressRegex string, addressPrefix string) (*gorm.DB, error) {
 		return nil, err
 	}
 
-	// Could potentially check Node info at startup and pass in ourselves?
-	core.SetupAddressRegex(addressRegex)
-	core.SetupAddressPrefix(addressPrefix)
-
 	// run database migrations at every runtime
 	err = dbUtils.MigrateModels(db)
 	if err != nil {
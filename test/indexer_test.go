@@ -8,11 +8,6 @@ import (
 	"github.com/DefiantLabs/cosmos-tax-cli/db"
 )
 
-const (
-	OsmosisAddressRegex  = "osmo(valoper)?1[a-z0-9]{38}"
-	OsmosisAddressPrefix = "osmo"
-)
-
 // Example DB query to get TXs for address:
 /*
 select * from taxable_tx tx
@@ -20,9 +15,7 @@ INNER JOIN addresses as addr ON addr.id = tx.sender_address_id OR addr.id = tx.r
 where addr.address = 'osmo...'
 */
 func TestOsmosisCsvForAddress(t *testing.T) {
-	addressRegex := OsmosisAddressRegex
-	addressPrefix := OsmosisAddressPrefix
-	gorm, _ := dbSetup(addressRegex, addressPrefix)
+	gorm, _ := dbSetup()
 	address := "osmo14mmus5h7m6vkp0pteks8wawaj4wf3sx7fy3s2r" // local test key address
 	csvRows, headers, _, err := csv.ParseForAddress([]string{address}, nil, nil, gorm, "accointing")
 	if err != nil || len(csvRows) == 0 {
@@ -45,9 +38,7 @@ func TestOsmosisCsvForAddress(t *testing.T) {
 }
 
 func TestCsvForAddress(t *testing.T) {
-	addressRegex := "juno(valoper)?1[a-z0-9]{38}"
-	addressPrefix := "juno"
-	gorm, _ := dbSetup(addressRegex, addressPrefix)
+	gorm, _ := dbSetup()
 	// address := "juno1mt72y3jny20456k247tc5gf2dnat76l4ynvqwl"
 	// address := "juno130mdu9a0etmeuw52qfxk73pn0ga6gawk4k539x" // strangelove's delegator
 	address := "juno1m2hg5t7n8f6kzh8kmh98phenk8a4xp5wyuz34y" // local test key address
@@ -71,9 +62,7 @@ func TestCsvForAddress(t *testing.T) {
 }
 
 func TestLookupTxForAddresses(t *testing.T) {
-	addressRegex := "juno(valoper)?1[a-z0-9]{38}"
-	addressPrefix := "juno"
-	gorm, _ := dbSetup(addressRegex, addressPrefix)
+	gorm, _ := dbSetup()
 	// "juno1txpxafd7q96nkj5jxnt7qnqy4l0rrjyuv6dgte"
 	// juno1mt72y3jny20456k247tc5gf2dnat76l4ynvqwl
 	taxableEvts, err := db.GetTaxableTransactions("juno1txpxafd7q96nkj5jxnt7qnqy4l0rrjyuv6dgte", gorm)
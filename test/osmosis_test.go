@@ -32,9 +32,7 @@ func TestGetRewardsForAddress(t *testing.T) {
 		order by a.amount desc limit 5
 	*/
 
-	addressRegex := OsmosisAddressRegex
-	addressPrefix := OsmosisAddressPrefix
-	gorm, err := dbSetup(addressRegex, addressPrefix)
+	gorm, err := dbSetup()
 	if err != nil {
 		t.Fail()
 	}
@@ -63,9 +61,7 @@ func TestGetRewardsForAddress(t *testing.T) {
 }
 
 func TestGetOsmosisRewardIndex(t *testing.T) {
-	addressRegex := OsmosisAddressRegex
-	addressPrefix := OsmosisAddressPrefix
-	gorm, err := dbSetup(addressRegex, addressPrefix)
+	gorm, err := dbSetup()
 	if err != nil {
 		t.Fail()
 	}
@@ -82,9 +78,7 @@ func TestGetOsmosisRewardIndex(t *testing.T) {
 }
 
 func TestInsertOsmosisRewards(t *testing.T) {
-	addressRegex := OsmosisAddressRegex
-	addressPrefix := OsmosisAddressPrefix
-	gorm, err := dbSetup(addressRegex, addressPrefix)
+	gorm, err := dbSetup()
 	if err != nil {
 		t.Fail()
 	}